@@ -0,0 +1,119 @@
+// Command catalog-migrate copies every component and version from one catalog
+// storage backend to another.
+//
+// Usage:
+//
+//	catalog-migrate -source dynamodb.yaml -target postgres.yaml [-checkpoint state.json] [-dry-run] [-verify] [-allow-republish]
+//
+// Each config file holds a storage configuration as accepted by the catalog
+// service (type plus backend-specific settings). The changes recorded for the
+// components and the taxonomy are copied too.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/HatiCode/nestor/catalog/internal/migrate"
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	_ "github.com/HatiCode/nestor/catalog/internal/storage/dynamodb"
+	_ "github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	var (
+		sourcePath     = flag.String("source", "", "path to the source storage config (YAML)")
+		targetPath     = flag.String("target", "", "path to the target storage config (YAML)")
+		checkpointPath = flag.String("checkpoint", "", "path to a checkpoint file used to resume interrupted runs")
+		dryRun         = flag.Bool("dry-run", false, "read the source and report without writing")
+		verify         = flag.Bool("verify", false, "compare checksums of both stores after copying")
+		allowRepublish = flag.Bool("allow-republish", false, "republish versions as drafts when the target cannot write them verbatim, losing their timestamps and changes")
+		pageSize       = flag.Int("page-size", int(migrate.DefaultPageSize), "components read per page (1-100)")
+		logLevel       = flag.String("log-level", "info", "log level (debug, info, warn, error)")
+	)
+	flag.Parse()
+
+	if *sourcePath == "" || *targetPath == "" {
+		fmt.Fprintln(os.Stderr, "both -source and -target are required")
+		flag.Usage()
+		return 2
+	}
+
+	logger := logging.NewLogger(&logging.Config{Level: *logLevel, Format: "text"})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	source, err := openStore(*sourcePath, logger)
+	if err != nil {
+		logger.Error("failed to open source store", "error", err)
+		return 1
+	}
+	target, err := openStore(*targetPath, logger)
+	if err != nil {
+		logger.Error("failed to open target store", "error", err)
+		return 1
+	}
+
+	opts := migrate.Options{
+		PageSize:       int32(*pageSize),
+		DryRun:         *dryRun,
+		Verify:         *verify,
+		AllowRepublish: *allowRepublish,
+		Logger:         logger,
+	}
+	if *checkpointPath != "" {
+		opts.Checkpoints = migrate.NewFileCheckpoint(*checkpointPath)
+	}
+
+	report, err := migrate.Migrate(ctx, source, target, opts)
+	if report != nil {
+		printReport(report)
+	}
+	if err != nil {
+		logger.Error("migration failed", "error", err)
+		return 1
+	}
+
+	if report.Verification != nil && !report.Verification.OK() {
+		logger.Error("verification failed",
+			"missing", len(report.Verification.Missing),
+			"unexpected", len(report.Verification.Unexpected),
+			"mismatched", len(report.Verification.Mismatched))
+		return 3
+	}
+
+	return 0
+}
+
+func openStore(path string, logger logging.Logger) (storage.ComponentStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var config storage.StorageConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return storage.NewComponentStore(&config, nil, logger)
+}
+
+func printReport(report *migrate.Report) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(report)
+}
@@ -0,0 +1,64 @@
+// Command catalog-policy runs the test cases declared in governance policy
+// files.
+//
+// Usage:
+//
+//	catalog-policy [-v] PATH...
+//
+// Each path is a policy file or a directory of .yaml and .yml policy files.
+// The command exits with status 1 when a test case fails and 2 when the
+// policies cannot be loaded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/HatiCode/nestor/catalog/internal/policy"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	verbose := flag.Bool("v", false, "print passing test cases and their violations")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "at least one policy path is required")
+		flag.Usage()
+		return 2
+	}
+
+	engine, tests, err := policy.Load(flag.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	failed := 0
+	for _, result := range engine.Run(tests) {
+		if result.Passed() {
+			if *verbose {
+				fmt.Printf("PASS %s\n", result.Name)
+				for _, violation := range result.Violations {
+					fmt.Printf("     %s %s: %s: %s\n", violation.Enforcement, violation.Policy, violation.Path, violation.Message)
+				}
+			}
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s\n", result.Name)
+		for _, failure := range result.Failures {
+			fmt.Printf("     %s\n", failure)
+		}
+	}
+
+	fmt.Printf("%d policies, %d tests, %d failed\n", len(engine.Policies()), len(tests), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
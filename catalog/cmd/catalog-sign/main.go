@@ -0,0 +1,143 @@
+// Command catalog-sign generates signing keys and signs component
+// definitions before they are published.
+//
+// Usage:
+//
+//	catalog-sign keygen
+//	catalog-sign sign -key-id ID -key FILE COMPONENT
+//	catalog-sign verify -keys FILE COMPONENT
+//
+// keygen prints a new base64 encoded key pair. sign prints COMPONENT, a JSON
+// component definition, with its checksum and a signature by the private key
+// in FILE. verify checks the signatures of COMPONENT against a key set file.
+// The command exits with status 1 when verification fails and 2 on usage
+// errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/catalog/pkg/signing"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: catalog-sign keygen | sign | verify")
+		return 2
+	}
+
+	switch args[0] {
+	case "keygen":
+		return keygen()
+	case "sign":
+		return sign(args[1:])
+	case "verify":
+		return verify(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		return 2
+	}
+}
+
+func keygen() int {
+	publicKey, privateKey, err := signing.GenerateKey()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Printf("public_key: %s\nprivate_key: %s\n", publicKey, privateKey)
+	return 0
+}
+
+func sign(args []string) int {
+	flags := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyID := flags.String("key-id", "", "ID of the signing key in the catalog key set")
+	keyPath := flags.String("key", "", "file holding the base64 encoded private key")
+	_ = flags.Parse(args)
+
+	if *keyID == "" || *keyPath == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	data, err := os.ReadFile(*keyPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	key, err := signing.ParsePrivateKey(string(data))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *keyPath, err)
+		return 2
+	}
+
+	component, err := readComponent(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := signing.Sign(component, *keyID, key); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	out, err := json.ToJSON(component)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Println(string(out))
+	return 0
+}
+
+func verify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	keysPath := flags.String("keys", "", "key set file")
+	_ = flags.Parse(args)
+
+	if *keysPath == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	keys, err := signing.LoadKeySet(*keysPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	component, err := readComponent(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	signers, err := keys.Verify(component)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	for _, key := range signers {
+		fmt.Printf("signed by %s (%s)\n", key.ID, key.Publisher)
+	}
+	return 0
+}
+
+func readComponent(path string) (*models.Component, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var component models.Component
+	if err := json.FromJSON(data, &component); err != nil {
+		return nil, fmt.Errorf("%s is not a valid component: %w", path, err)
+	}
+	return &component, nil
+}
@@ -0,0 +1,480 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/internal/impact"
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// ComponentHandler serves component discovery and publication.
+type ComponentHandler struct {
+	store        storage.ComponentStore
+	drafts       storage.DraftStore
+	deprecations storage.DeprecationStore
+	logger       logging.Logger
+}
+
+// NewComponentHandler creates a ComponentHandler backed by store. The draft
+// promotion routes are only served when store implements
+// storage.DraftStore, and the deprecation routes when it implements
+// storage.DeprecationStore.
+func NewComponentHandler(store storage.ComponentStore, logger logging.Logger) *ComponentHandler {
+	drafts, _ := store.(storage.DraftStore)
+	deprecations, _ := store.(storage.DeprecationStore)
+	return &ComponentHandler{
+		store:        store,
+		drafts:       drafts,
+		deprecations: deprecations,
+		logger:       logger.With("component", "component_handler"),
+	}
+}
+
+// Register adds the component routes to mux.
+func (h *ComponentHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/components", h.listComponents)
+	mux.HandleFunc("POST /api/v1/components", h.storeComponent)
+	mux.HandleFunc("GET /api/v1/components/{name}/versions", h.versionHistory)
+	mux.HandleFunc("GET /api/v1/components/{name}/versions/{version}", h.getComponent)
+	mux.HandleFunc("GET /api/v1/components/{name}/versions/{version}/schema/{part}", h.schema)
+	mux.HandleFunc("POST /api/v1/components/{name}/versions/{version}/preview", h.preview)
+	mux.HandleFunc("GET /api/v1/components/{name}/versions/{version}/signatures", h.signatures)
+	mux.HandleFunc("GET /api/v1/components/{name}/dependents", h.dependents)
+	mux.HandleFunc("GET /api/v1/components/{name}/versions/{version}/impact", h.impact)
+	if h.drafts != nil {
+		mux.HandleFunc("POST /api/v1/components/{name}/versions/{version}/approve", h.approve)
+		mux.HandleFunc("POST /api/v1/components/{name}/versions/{version}/promote", h.promote)
+		mux.HandleFunc("GET /api/v1/components/{name}/changes", h.changes)
+	}
+	if h.deprecations != nil {
+		mux.HandleFunc("POST /api/v1/components/{name}/deprecate", h.deprecateComponent)
+		mux.HandleFunc("POST /api/v1/components/{name}/versions/{version}/deprecate", h.deprecateVersion)
+	}
+}
+
+// listComponents lists components. Supported query parameters are provider,
+// category, sub_category and engine (all repeatable), depends_on, provides,
+// version (a constraint such as ^2), major, highest_only, limit and
+// next_token. Drafts are only listed to their maintainers. The deprecations
+// of the versions listed are reported in the warnings field and with Warning
+// headers.
+func (h *ComponentHandler) listComponents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	pagination := storage.Pagination{NextToken: query.Get("next_token")}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			writeError(w, r, h.logger, storage.NewValidationError("limit", "must be an integer"))
+			return
+		}
+		pagination.Limit = int32(n)
+	}
+
+	filters := storage.ComponentFilters{
+		Providers:          query["provider"],
+		Categories:         query["category"],
+		SubCategories:      query["sub_category"],
+		DeploymentEngines:  query["engine"],
+		HasDependency:      query.Get("depends_on"),
+		ProvidesDependency: query.Get("provides"),
+		VersionConstraint:  query.Get("version"),
+		Viewer:             requestUser(r),
+	}
+	if major := query.Get("major"); major != "" {
+		n, err := strconv.Atoi(major)
+		if err != nil {
+			writeError(w, r, h.logger, storage.NewValidationError("major", "must be an integer"))
+			return
+		}
+		filters.MajorVersion = &n
+	}
+	if highest := query.Get("highest_only"); highest != "" {
+		value, err := strconv.ParseBool(highest)
+		if err != nil {
+			writeError(w, r, h.logger, storage.NewValidationError("highest_only", "must be a boolean"))
+			return
+		}
+		filters.HighestOnly = value
+	}
+
+	list, err := h.store.ListComponents(r.Context(), filters, pagination)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	response := listResponse{ComponentList: list}
+	now := time.Now()
+	for _, component := range list.Components {
+		response.Warnings = append(response.Warnings, component.Warnings(now)...)
+	}
+	setWarningHeaders(w, response.Warnings)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listResponse is the body of listComponents.
+type listResponse struct {
+	*storage.ComponentList
+	Warnings []models.Warning `json:"warnings,omitempty"`
+}
+
+// storeComponent publishes the component in the request body on behalf of
+// the caller, who is recorded as its publisher. When the store keeps drafts,
+// the version is stored as a draft, and an existing draft can only be
+// replaced by one of its maintainers. The stored component is returned with
+// the validation warnings it raised.
+func (h *ComponentHandler) storeComponent(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, r, h.logger, storage.NewValidationError("body", "request body is unreadable or too large").WithCause(err))
+		return
+	}
+
+	var component models.Component
+	if err := json.FromJSON(data, &component); err != nil {
+		writeError(w, r, h.logger, storage.NewValidationError("body", "request body is not a valid component").WithCause(err))
+		return
+	}
+	component.Metadata.PublishedBy = requestUser(r)
+
+	if h.drafts != nil {
+		existing, err := h.store.GetComponent(r.Context(), component.Name, component.Version)
+		switch {
+		case err == nil && existing.IsDraft() && !existing.IsMaintainer(requestUser(r)):
+			writeError(w, r, h.logger, storage.NewComponentExistsError(component.Name, component.Version))
+			return
+		case err != nil && !errors.Is(err, storage.ErrResourceNotFound):
+			writeError(w, r, h.logger, err)
+			return
+		}
+	}
+
+	warnings, err := h.store.StoreComponent(r.Context(), &component)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, publishResponse{
+		storedComponent: (*storedComponent)(&component),
+		ID:              component.GetID(),
+		Warnings:        warnings,
+	})
+}
+
+// publishResponse is the body of storeComponent: the component, encoded as
+// models.Component encodes itself, and its validation warnings.
+type publishResponse struct {
+	*storedComponent
+	ID       string                  `json:"id"`
+	Warnings models.ValidationErrors `json:"warnings,omitempty"`
+}
+
+// storedComponent has the fields of models.Component without its methods,
+// so that its MarshalJSON does not take over the encoding of
+// publishResponse.
+type storedComponent models.Component
+
+// versionHistory lists the versions of a component, leaving out the drafts
+// the caller does not maintain. The deprecations of the versions are
+// reported in the warnings field and with Warning headers.
+func (h *ComponentHandler) versionHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := h.store.GetVersionHistory(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	visible := make([]models.ComponentVersion, 0, len(history))
+	var warnings []models.Warning
+	now := time.Now()
+	for _, entry := range history {
+		if entry.Status == models.VersionStatusDraft && !entry.IsMaintainer(requestUser(r)) {
+			continue
+		}
+		warnings = append(warnings, entry.Warnings(now)...)
+		visible = append(visible, entry)
+	}
+
+	response := map[string]any{"versions": visible}
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	setWarningHeaders(w, warnings)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getComponent returns a component version. With ?engine=X the component is
+// rendered for engine X only, and 404 is returned if X is not supported.
+func (h *ComponentHandler) getComponent(w http.ResponseWriter, r *http.Request) {
+	component, err := h.getVisible(w, r)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	if engine := r.URL.Query().Get("engine"); engine != "" {
+		rendered, err := component.ForEngine(engine)
+		if errors.Is(err, models.ErrUnsupportedEngine) {
+			writeError(w, r, h.logger, storage.NewResourceNotFoundError("deployment engine", engine).
+				WithDetail("component", component.GetID()).
+				WithDetail("supported_engines", component.Engines()).
+				WithCause(err))
+			return
+		}
+		if err != nil {
+			writeError(w, r, h.logger, fmt.Errorf("failed to render %s for %s: %w", component.GetID(), engine, err))
+			return
+		}
+		component = rendered
+	}
+
+	writeJSON(w, http.StatusOK, component)
+}
+
+// schema serves the JSON Schema of the inputs or outputs of a component
+// version, depending on part.
+func (h *ComponentHandler) schema(w http.ResponseWriter, r *http.Request) {
+	var generate func(*models.Component) (map[string]any, error)
+	switch part := r.PathValue("part"); part {
+	case "inputs":
+		generate = models.InputsSchema
+	case "outputs":
+		generate = models.OutputsSchema
+	default:
+		writeError(w, r, h.logger, storage.NewResourceNotFoundError("schema", part))
+		return
+	}
+
+	component, err := h.getVisible(w, r)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	schema, err := generate(component)
+	if err != nil {
+		writeError(w, r, h.logger, fmt.Errorf("failed to generate schema for %s: %w", component.GetID(), err))
+		return
+	}
+	schema["$id"] = requestURL(r)
+
+	writeJSONAs(w, http.StatusOK, "application/schema+json", schema)
+}
+
+// previewRequest is the body of a preview request.
+type previewRequest struct {
+	Inputs map[string]any `json:"inputs"`
+}
+
+// preview renders the deployment config of a component version for the
+// input values in the body, without deploying anything. ?engine=X selects
+// the engine, the default engine is used otherwise.
+func (h *ComponentHandler) preview(w http.ResponseWriter, r *http.Request) {
+	var req previewRequest
+	if r.ContentLength != 0 {
+		if err := readBody(w, r, &req); err != nil {
+			writeError(w, r, h.logger, err)
+			return
+		}
+	}
+
+	component, err := h.getVisible(w, r)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	engine := r.URL.Query().Get("engine")
+	if engine == "" {
+		engine = component.Deployment.Engine
+	}
+
+	config, err := component.Preview(engine, req.Inputs)
+	var issues models.ValidationErrors
+	switch {
+	case errors.Is(err, models.ErrUnsupportedEngine):
+		writeError(w, r, h.logger, storage.NewResourceNotFoundError("deployment engine", engine).
+			WithDetail("component", component.GetID()).
+			WithDetail("supported_engines", component.Engines()).
+			WithCause(err))
+		return
+	case errors.As(err, &issues):
+		writeError(w, r, h.logger, storage.NewValidationError("inputs", issues.Error()).
+			WithDetail("errors", issues).
+			WithCause(err))
+		return
+	case err != nil:
+		writeError(w, r, h.logger, storage.NewValidationError("config", fmt.Sprintf("config of %s cannot be rendered: %v", component.GetID(), err)).WithCause(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"component": component.GetID(),
+		"engine":    engine,
+		"config":    config,
+	})
+}
+
+// signatures returns the checksum of a component version and the
+// signatures made of it.
+func (h *ComponentHandler) signatures(w http.ResponseWriter, r *http.Request) {
+	component, err := h.getVisible(w, r)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	signatures := component.Metadata.Signatures
+	if signatures == nil {
+		signatures = []models.Signature{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"component":  component.GetID(),
+		"checksum":   component.Metadata.Checksum,
+		"signatures": signatures,
+	})
+}
+
+// approve records the caller's approval of a draft version. Like promote,
+// it is limited to the maintainers of the draft, and the store only accepts
+// the approvals of the maintainers of the previous version other than the
+// publisher.
+func (h *ComponentHandler) approve(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.getVisible(w, r); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	component, err := h.drafts.ApproveVersion(r.Context(), r.PathValue("name"), r.PathValue("version"), requestUser(r))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, component)
+}
+
+// promote makes a draft version active. Drafts are only visible to their
+// maintainers, so nobody else can promote them.
+func (h *ComponentHandler) promote(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.getVisible(w, r); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	change, err := h.drafts.PromoteVersion(r.Context(), r.PathValue("name"), r.PathValue("version"), requestUser(r))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, change)
+}
+
+// changes lists the changes recorded for a component, oldest first.
+func (h *ComponentHandler) changes(w http.ResponseWriter, r *http.Request) {
+	changes, err := h.drafts.GetChanges(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"changes": changes})
+}
+
+// deprecateVersion deprecates a component version on behalf of the caller,
+// who must maintain it. The body is a models.Deprecation and may be empty.
+func (h *ComponentHandler) deprecateVersion(w http.ResponseWriter, r *http.Request) {
+	deprecation, err := readDeprecation(w, r)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	component, err := h.deprecations.DeprecateVersion(r.Context(), r.PathValue("name"), r.PathValue("version"), requestUser(r), deprecation)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	setDeprecationHeaders(w, component)
+	writeJSON(w, http.StatusOK, component)
+}
+
+// deprecateComponent deprecates every published version of a component, like
+// deprecateVersion.
+func (h *ComponentHandler) deprecateComponent(w http.ResponseWriter, r *http.Request) {
+	deprecation, err := readDeprecation(w, r)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	components, err := h.deprecations.DeprecateComponent(r.Context(), r.PathValue("name"), requestUser(r), deprecation)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"components": components})
+}
+
+func readDeprecation(w http.ResponseWriter, r *http.Request) (models.Deprecation, error) {
+	var deprecation models.Deprecation
+	if r.ContentLength != 0 {
+		if err := readBody(w, r, &deprecation); err != nil {
+			return deprecation, err
+		}
+	}
+	return deprecation, nil
+}
+
+// getVisible returns the component version named in the path and sets the
+// deprecation headers of deprecated versions. Drafts the caller does not
+// maintain are reported as not found.
+func (h *ComponentHandler) getVisible(w http.ResponseWriter, r *http.Request) (*models.Component, error) {
+	name, version := r.PathValue("name"), r.PathValue("version")
+	component, err := h.store.GetComponent(r.Context(), name, version)
+	if err != nil {
+		return nil, err
+	}
+	if component.IsDraft() && !component.IsMaintainer(requestUser(r)) {
+		return nil, storage.NewComponentNotFoundError(name, version)
+	}
+	setDeprecationHeaders(w, component)
+	return component, nil
+}
+
+// dependents lists every component version depending on the component,
+// with the constraint each one declares.
+func (h *ComponentHandler) dependents(w http.ResponseWriter, r *http.Request) {
+	dependents, err := impact.Dependents(r.Context(), h.store, r.PathValue("name"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	if dependents == nil {
+		dependents = []impact.Dependent{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dependents": dependents})
+}
+
+// impact reports the dependents affected by ?action=deprecate or
+// ?action=yank (the default) on a component version.
+func (h *ComponentHandler) impact(w http.ResponseWriter, r *http.Request) {
+	action := impact.ActionYank
+	if value := r.URL.Query().Get("action"); value != "" {
+		action = impact.Action(value)
+	}
+
+	report, err := impact.Analyze(r.Context(), h.store, r.PathValue("name"), r.PathValue("version"), action)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
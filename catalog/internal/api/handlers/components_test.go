@@ -0,0 +1,632 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// maintainer maintains the components made by newComponent.
+const maintainer = "platform@example.com"
+
+func newComponent(name, version string) *models.Component {
+	created := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	return &models.Component{
+		Name:        name,
+		Version:     version,
+		Provider:    "aws",
+		Category:    "database",
+		Maintainers: []string{maintainer},
+		Inputs: []models.InputSpec{
+			{Name: "db_name", Type: "string", Description: "Database name"},
+		},
+		Outputs: []models.OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Database endpoint"},
+		},
+		Deployment: models.DeploymentSpec{
+			Engine:  "terraform",
+			Version: "1.5.0",
+			Config:  map[string]any{"source": "git::https://example.com/postgres", "module_version": "1.0.0"},
+		},
+		AdditionalDeployments: []models.DeploymentSpec{
+			{Engine: "crossplane", Version: "1.14.0", Config: map[string]any{"apiVersion": "database.example.org/v1alpha1", "kind": "XPostgres", "compositionRef": map[string]any{"name": "xpostgres"}}},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+}
+
+func newServer(t *testing.T, components ...*models.Component) (*http.ServeMux, storage.ComponentStore) {
+	t.Helper()
+
+	store := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), components))
+
+	mux := http.NewServeMux()
+	NewComponentHandler(store, logging.NewNoop()).Register(mux)
+	NewHealthHandler(store, logging.NewNoop()).Register(mux)
+	return mux, store
+}
+
+func serve(t *testing.T, mux *http.ServeMux, method, target string, body []byte) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	return serveAs(t, mux, "", method, target, body)
+}
+
+// serveAs serves a request made by user, anonymous when empty.
+func serveAs(t *testing.T, mux *http.ServeMux, user, method, target string, body []byte) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if user != "" {
+		req.Header.Set(UserHeader, user)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	var decoded map[string]any
+	require.NoError(t, json.FromJSON(rec.Body.Bytes(), &decoded), rec.Body.String())
+	return rec, decoded
+}
+
+func TestComponentHandler_GetComponentForEngine(t *testing.T) {
+	mux, _ := newServer(t, newComponent("postgres", "1.0.0"))
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantEngine string
+		wantExtra  bool
+	}{
+		{"all engines", "/api/v1/components/postgres/versions/1.0.0", http.StatusOK, "terraform", true},
+		{"default engine", "/api/v1/components/postgres/versions/1.0.0?engine=terraform", http.StatusOK, "terraform", false},
+		{"additional engine", "/api/v1/components/postgres/versions/1.0.0?engine=crossplane", http.StatusOK, "crossplane", false},
+		{"unsupported engine", "/api/v1/components/postgres/versions/1.0.0?engine=pulumi", http.StatusNotFound, "", false},
+		{"unknown version", "/api/v1/components/postgres/versions/9.9.9", http.StatusNotFound, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, body := serve(t, mux, http.MethodGet, tt.target, nil)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			if tt.wantStatus != http.StatusOK {
+				assert.Contains(t, body, "error")
+				return
+			}
+			deployment := body["deployment"].(map[string]any)
+			assert.Equal(t, tt.wantEngine, deployment["engine"])
+			_, hasExtra := body["additional_deployments"]
+			assert.Equal(t, tt.wantExtra, hasExtra)
+		})
+	}
+}
+
+func TestComponentHandler_UnsupportedEngineError(t *testing.T) {
+	mux, _ := newServer(t, newComponent("postgres", "1.0.0"))
+
+	_, body := serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.0.0?engine=pulumi", nil)
+	errBody := body["error"].(map[string]any)
+	assert.Equal(t, storage.CodeResourceNotFound, errBody["code"])
+	assert.Equal(t, []any{"terraform", "crossplane"}, errBody["details"].(map[string]any)["supported_engines"])
+}
+
+func TestComponentHandler_ListComponentsByEngine(t *testing.T) {
+	terraformOnly := newComponent("vpc", "1.0.0")
+	terraformOnly.AdditionalDeployments = nil
+	mux, _ := newServer(t, newComponent("postgres", "1.0.0"), terraformOnly)
+
+	rec, body := serve(t, mux, http.MethodGet, "/api/v1/components?engine=crossplane", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	components := body["components"].([]any)
+	require.Len(t, components, 1)
+	assert.Equal(t, "postgres", components[0].(map[string]any)["name"])
+
+	rec, _ = serve(t, mux, http.MethodGet, "/api/v1/components?limit=ten", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestComponentHandler_StoreComponent(t *testing.T) {
+	mux, store := newServer(t)
+
+	data, err := json.ToJSON(newComponent("postgres", "1.0.0"))
+	require.NoError(t, err)
+	rec, created := serve(t, mux, http.MethodPost, "/api/v1/components", data)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "postgres:1.0.0", created["id"])
+	assert.NotContains(t, created, "warnings")
+
+	stored, err := store.GetComponent(context.Background(), "postgres", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"terraform", "crossplane"}, stored.Engines())
+
+	sensitive := newComponent("postgres", "1.0.1")
+	sensitive.Inputs = append(sensitive.Inputs, models.InputSpec{Name: "password", Type: "string", Description: "Master password", Sensitive: true, Default: "hunter2"})
+	data, err = json.ToJSON(sensitive)
+	require.NoError(t, err)
+	rec, created = serve(t, mux, http.MethodPost, "/api/v1/components", data)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "1.0.1", created["version"])
+	warnings := created["warnings"].([]any)
+	require.Len(t, warnings, 1, "warnings do not reject the component but are returned")
+	assert.Equal(t, "inputs[1].default", warnings[0].(map[string]any)["path"])
+	assert.Equal(t, string(models.SeverityWarning), warnings[0].(map[string]any)["severity"])
+
+	duplicate := newComponent("postgres", "1.1.0")
+	duplicate.AdditionalDeployments[0].Engine = "terraform"
+	data, err = json.ToJSON(duplicate)
+	require.NoError(t, err)
+	rec, body := serve(t, mux, http.MethodPost, "/api/v1/components", data)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, storage.CodeValidation, body["error"].(map[string]any)["code"])
+
+	invalid := newComponent("postgres", "1.2.0")
+	invalid.Inputs[0].Type = "text"
+	invalid.Deployment.Version = ""
+	data, err = json.ToJSON(invalid)
+	require.NoError(t, err)
+	rec, body = serve(t, mux, http.MethodPost, "/api/v1/components", data)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "validation error for component: input 'db_name' has invalid type: invalid type 'text': unknown type 'text' at position 0; deployment engine version is required",
+		body["error"].(map[string]any)["message"])
+	issues := body["error"].(map[string]any)["details"].(map[string]any)["errors"].([]any)
+	require.Len(t, issues, 2)
+	assert.Equal(t, map[string]any{
+		"path":     "inputs[0].type",
+		"rule":     models.RuleType,
+		"message":  "input 'db_name' has invalid type: invalid type 'text': unknown type 'text' at position 0",
+		"severity": string(models.SeverityError),
+	}, issues[0])
+	assert.Equal(t, "deployment.version", issues[1].(map[string]any)["path"])
+
+	rec, _ = serve(t, mux, http.MethodPost, "/api/v1/components", []byte("{"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHealthHandler(t *testing.T) {
+	mux, _ := newServer(t)
+
+	rec, body := serve(t, mux, http.MethodGet, "/health", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", body["status"])
+}
+
+func TestComponentHandler_ReverseDependencies(t *testing.T) {
+	app := newComponent("app", "1.0.0")
+	app.Dependencies = []models.Dependency{{Name: "postgres", Type: "component", Version: "^1.0.0"}}
+	postgres := newComponent("postgres", "1.0.0")
+	postgres.Provides = []string{"sql-database"}
+	mux, _ := newServer(t, app, postgres, newComponent("postgres", "1.1.0"))
+
+	_, body := serve(t, mux, http.MethodGet, "/api/v1/components?depends_on=postgres", nil)
+	components := body["components"].([]any)
+	require.Len(t, components, 1)
+	assert.Equal(t, "app", components[0].(map[string]any)["name"])
+
+	_, body = serve(t, mux, http.MethodGet, "/api/v1/components?provides=sql-database", nil)
+	components = body["components"].([]any)
+	require.Len(t, components, 1)
+	assert.Equal(t, "1.0.0", components[0].(map[string]any)["version"])
+
+	rec, body := serve(t, mux, http.MethodGet, "/api/v1/components/postgres/dependents", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []any{map[string]any{"name": "app", "version": "1.0.0", "constraint": "^1.0.0"}}, body["dependents"])
+
+	rec, body = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.1.0/impact", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "yank", body["action"])
+	affected := body["affected"].([]any)
+	require.Len(t, affected, 1)
+	assert.Equal(t, []any{"1.0.0"}, affected[0].(map[string]any)["alternatives"])
+
+	rec, _ = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.1.0/impact?action=delete", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestComponentHandler_Schema(t *testing.T) {
+	component := newComponent("postgres", "1.0.0")
+	component.Inputs[0].Validation.Required = true
+	mux, _ := newServer(t, component)
+
+	rec, body := serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.0.0/schema/inputs", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/schema+json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, models.JSONSchemaDialect, body["$schema"])
+	assert.Equal(t, "http://example.com/api/v1/components/postgres/versions/1.0.0/schema/inputs", body["$id"])
+	assert.Equal(t, []any{"db_name"}, body["required"])
+
+	rec, body = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.0.0/schema/outputs", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, body["properties"], "endpoint")
+
+	rec, _ = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.0.0/schema/deployment", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec, _ = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/2.0.0/schema/inputs", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestComponentHandler_Preview(t *testing.T) {
+	component := newComponent("postgres", "1.0.0")
+	component.Inputs[0].Validation.Required = true
+	component.Deployment.Config["name"] = "{{ .inputs.db_name }}"
+	mux, _ := newServer(t, component)
+
+	target := "/api/v1/components/postgres/versions/1.0.0/preview"
+	rec, body := serve(t, mux, http.MethodPost, target, []byte(`{"inputs": {"db_name": "orders"}}`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "terraform", body["engine"])
+	assert.Equal(t, "orders", body["config"].(map[string]any)["name"])
+
+	rec, body = serve(t, mux, http.MethodPost, target+"?engine=crossplane", []byte(`{"inputs": {"db_name": "orders"}}`))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "XPostgres", body["config"].(map[string]any)["kind"])
+
+	rec, body = serve(t, mux, http.MethodPost, target, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	errBody := body["error"].(map[string]any)
+	assert.Equal(t, storage.CodeValidation, errBody["code"])
+	issues := errBody["details"].(map[string]any)["errors"].([]any)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "inputs.db_name", issues[0].(map[string]any)["path"])
+
+	rec, _ = serve(t, mux, http.MethodPost, target+"?engine=pulumi", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec, _ = serve(t, mux, http.MethodPost, "/api/v1/components/postgres/versions/9.9.9/preview", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestComponentHandler_Checksums(t *testing.T) {
+	mux, store := newServer(t)
+
+	body, err := json.ToJSON(newComponent("postgres", "1.0.0"))
+	require.NoError(t, err)
+	rec, created := serve(t, mux, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	checksum := created["metadata"].(map[string]any)["checksum"].(string)
+	assert.True(t, strings.HasPrefix(checksum, models.ChecksumPrefix))
+
+	_, history := serveAs(t, mux, maintainer, http.MethodGet, "/api/v1/components/postgres/versions", nil)
+	versions := history["versions"].([]any)
+	require.Len(t, versions, 1)
+	assert.Equal(t, checksum, versions[0].(map[string]any)["checksum"])
+
+	_, fetched := serveAs(t, mux, maintainer, http.MethodGet, "/api/v1/components/postgres/versions/1.0.0", nil)
+	assert.Equal(t, checksum, fetched["metadata"].(map[string]any)["checksum"])
+
+	tampered, err := store.GetComponent(context.Background(), "postgres", "1.0.0")
+	require.NoError(t, err)
+	tampered.Deployment.Version = "1.6.0"
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), []*models.Component{tampered}))
+
+	rec, _ = serveAs(t, mux, maintainer, http.MethodGet, "/api/v1/components/postgres/versions/1.0.0", nil)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	_, err = store.GetComponent(context.Background(), "postgres", "1.0.0")
+	assert.ErrorIs(t, err, storage.ErrIntegrity)
+}
+
+func TestComponentHandler_Signatures(t *testing.T) {
+	signed := newComponent("postgres", "1.0.0")
+	signed.Metadata.Signatures = []models.Signature{{KeyID: "platform-2024", Publisher: "platform-team", Value: "c2lnbmF0dXJl"}}
+	require.NoError(t, signed.SetChecksum())
+	mux, _ := newServer(t, signed, newComponent("postgres", "1.1.0"))
+
+	rec, body := serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.0.0/signatures", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "postgres:1.0.0", body["component"])
+	assert.Equal(t, signed.Metadata.Checksum, body["checksum"])
+	assert.Equal(t, []any{map[string]any{"key_id": "platform-2024", "publisher": "platform-team", "value": "c2lnbmF0dXJl"}}, body["signatures"])
+
+	_, body = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.1.0/signatures", nil)
+	assert.Equal(t, []any{}, body["signatures"])
+
+	rec, _ = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/2.0.0/signatures", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestComponentHandler_ListComponentsByVersion(t *testing.T) {
+	mux, _ := newServer(t,
+		newComponent("postgres", "1.4.0"),
+		newComponent("postgres", "2.0.0"),
+		newComponent("postgres", "2.3.0"),
+		newComponent("vpc", "2.1.0"),
+		newComponent("vpc", "3.0.0"),
+	)
+
+	ids := func(body map[string]any) []string {
+		var ids []string
+		for _, component := range body["components"].([]any) {
+			c := component.(map[string]any)
+			ids = append(ids, c["name"].(string)+":"+c["version"].(string))
+		}
+		return ids
+	}
+
+	rec, body := serve(t, mux, http.MethodGet, "/api/v1/components?version=%5E2", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.ElementsMatch(t, []string{"postgres:2.0.0", "postgres:2.3.0", "vpc:2.1.0"}, ids(body))
+
+	_, body = serve(t, mux, http.MethodGet, "/api/v1/components?version=%5E2&highest_only=true", nil)
+	assert.ElementsMatch(t, []string{"postgres:2.3.0", "vpc:2.1.0"}, ids(body))
+
+	_, body = serve(t, mux, http.MethodGet, "/api/v1/components?major=3", nil)
+	assert.Equal(t, []string{"vpc:3.0.0"}, ids(body))
+
+	_, body = serve(t, mux, http.MethodGet, "/api/v1/components?highest_only=1&provider=aws", nil)
+	assert.ElementsMatch(t, []string{"postgres:2.3.0", "vpc:3.0.0"}, ids(body))
+
+	for _, query := range []string{"version=%5Ebanana", "major=two", "major=-1", "highest_only=maybe"} {
+		rec, body = serve(t, mux, http.MethodGet, "/api/v1/components?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Equal(t, storage.CodeValidation, body["error"].(map[string]any)["code"], query)
+	}
+}
+
+func TestComponentHandler_DraftLifecycle(t *testing.T) {
+	mux, _ := newServer(t)
+	const other = "data@example.com"
+	versionURL := "/api/v1/components/postgres/versions/1.0.0"
+
+	body, err := json.ToJSON(newComponent("postgres", "1.0.0"))
+	require.NoError(t, err)
+	rec, created := serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "draft", created["metadata"].(map[string]any)["status"])
+
+	for _, user := range []string{"", other} {
+		rec, _ = serveAs(t, mux, user, http.MethodGet, versionURL, nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, "drafts are hidden from %q", user)
+		rec, _ = serveAs(t, mux, user, http.MethodGet, versionURL+"/schema/inputs", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		_, list := serveAs(t, mux, user, http.MethodGet, "/api/v1/components", nil)
+		assert.Empty(t, list["components"])
+		_, history := serveAs(t, mux, user, http.MethodGet, "/api/v1/components/postgres/versions", nil)
+		assert.Empty(t, history["versions"])
+	}
+	_, list := serveAs(t, mux, maintainer, http.MethodGet, "/api/v1/components", nil)
+	assert.Len(t, list["components"], 1)
+	_, history := serveAs(t, mux, maintainer, http.MethodGet, "/api/v1/components/postgres/versions", nil)
+	require.Len(t, history["versions"], 1)
+	assert.Equal(t, "draft", history["versions"].([]any)[0].(map[string]any)["status"])
+
+	edited := newComponent("postgres", "1.0.0")
+	edited.Description = "Managed PostgreSQL"
+	body, err = json.ToJSON(edited)
+	require.NoError(t, err)
+	rec, _ = serveAs(t, mux, other, http.MethodPost, "/api/v1/components", body)
+	assert.Equal(t, http.StatusConflict, rec.Code, "only maintainers edit a draft")
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec, _ = serveAs(t, mux, other, http.MethodPost, versionURL+"/promote", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec, change := serveAs(t, mux, maintainer, http.MethodPost, versionURL+"/promote", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "promote", change["change_type"])
+	assert.Equal(t, maintainer, change["changed_by"])
+
+	rec, fetched := serve(t, mux, http.MethodGet, versionURL, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "active", fetched["metadata"].(map[string]any)["status"])
+	assert.Equal(t, "Managed PostgreSQL", fetched["description"])
+
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components", body)
+	assert.Equal(t, http.StatusConflict, rec.Code, "active versions are immutable")
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, versionURL+"/promote", nil)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	breaking := newComponent("postgres", "1.1.0")
+	breaking.Inputs[0].Name = "database"
+	body, err = json.ToJSON(breaking)
+	require.NoError(t, err)
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec, failed := serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components/postgres/versions/1.1.0/promote", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	issues := failed["error"].(map[string]any)["details"].(map[string]any)["errors"].([]any)
+	require.Len(t, issues, 1)
+	assert.Equal(t, models.RuleVersionBump, issues[0].(map[string]any)["rule"])
+
+	_, changes := serve(t, mux, http.MethodGet, "/api/v1/components/postgres/changes", nil)
+	require.Len(t, changes["changes"], 1)
+	assert.Equal(t, "1.0.0", changes["changes"].([]any)[0].(map[string]any)["version"])
+}
+
+func TestComponentHandler_Approvals(t *testing.T) {
+	mux, store := newServer(t)
+	const reviewer = "network@example.com"
+	versionURL := "/api/v1/components/vpc/versions/1.0.0"
+
+	taxonomyStore := store.(storage.TaxonomyStore)
+	taxonomy, err := taxonomyStore.GetTaxonomy(context.Background())
+	require.NoError(t, err)
+	taxonomy.SetCategory(models.TaxonomyCategory{
+		TaxonomyTerm:      models.TaxonomyTerm{Name: "networking", DisplayName: "Networking"},
+		RequiredApprovals: 1,
+	})
+	require.NoError(t, taxonomyStore.PutTaxonomy(context.Background(), taxonomy))
+
+	component := newComponent("vpc", "1.0.0")
+	component.Category = "networking"
+	component.Maintainers = append(component.Maintainers, reviewer)
+	component.Metadata.PublishedBy = reviewer
+	body, err := json.ToJSON(component)
+	require.NoError(t, err)
+	rec, created := serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, maintainer, created["metadata"].(map[string]any)["published_by"], "the publisher is the caller")
+
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, versionURL+"/promote", nil)
+	assert.Equal(t, http.StatusConflict, rec.Code, "networking drafts need an approval")
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, versionURL+"/approve", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "publishers cannot approve their own drafts")
+	rec, _ = serveAs(t, mux, "data@example.com", http.MethodPost, versionURL+"/approve", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec, approved := serveAs(t, mux, reviewer, http.MethodPost, versionURL+"/approve", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Len(t, approved["metadata"].(map[string]any)["approvals"], 1)
+
+	component.Description = "Shared VPC"
+	body, err = json.ToJSON(component)
+	require.NoError(t, err)
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, versionURL+"/promote", nil)
+	assert.Equal(t, http.StatusConflict, rec.Code, "editing the draft expires its approvals")
+
+	rec, _ = serveAs(t, mux, reviewer, http.MethodPost, versionURL+"/approve", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec, change := serveAs(t, mux, maintainer, http.MethodPost, versionURL+"/promote", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, []any{reviewer}, change["approvers"])
+
+	_, changes := serve(t, mux, http.MethodGet, "/api/v1/components/vpc/changes", nil)
+	require.Len(t, changes["changes"], 3, "both approvals are recorded, including the expired one")
+	for i, changeType := range []string{"approve", "approve", "promote"} {
+		assert.Equal(t, changeType, changes["changes"].([]any)[i].(map[string]any)["change_type"])
+	}
+	assert.Equal(t, reviewer, changes["changes"].([]any)[0].(map[string]any)["changed_by"])
+	assert.Equal(t, []any{reviewer}, changes["changes"].([]any)[2].(map[string]any)["approvers"])
+
+	const sockpuppet = "vpc-bot@example.com"
+	hijacked := newComponent("vpc", "1.1.0")
+	hijacked.Category = "networking"
+	hijacked.Maintainers = []string{maintainer, sockpuppet}
+	body, err = json.ToJSON(hijacked)
+	require.NoError(t, err)
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec, _ = serveAs(t, mux, sockpuppet, http.MethodPost, "/api/v1/components/vpc/versions/1.1.0/approve", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "drafts cannot choose their approvers")
+
+	anonymous := newComponent("subnet", "1.0.0")
+	anonymous.Category = "networking"
+	anonymous.Maintainers = append(anonymous.Maintainers, reviewer)
+	body, err = json.ToJSON(anonymous)
+	require.NoError(t, err)
+	rec, _ = serve(t, mux, http.MethodPost, "/api/v1/components", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components/subnet/versions/1.0.0/approve", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec, _ = serveAs(t, mux, reviewer, http.MethodPost, "/api/v1/components/subnet/versions/1.0.0/promote", nil)
+	assert.Equal(t, http.StatusConflict, rec.Code, "drafts published anonymously are not promoted")
+}
+
+func TestComponentHandler_Deprecation(t *testing.T) {
+	mux, _ := newServer(t,
+		newComponent("postgres", "1.0.0"),
+		newComponent("postgres", "1.1.0"),
+		newComponent("mysql", "1.0.0"),
+		newComponent("mysql", "2.0.0"),
+	)
+	versionURL := "/api/v1/components/postgres/versions/1.0.0"
+
+	rec, _ := serve(t, mux, http.MethodGet, versionURL, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(DeprecationHeader))
+	assert.Empty(t, rec.Header().Get(WarningHeader))
+
+	sunset := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+	body, err := json.ToJSON(models.Deprecation{
+		Reason:      "Replaced by Aurora",
+		Replacement: &models.Replacement{Name: "aurora", Version: "^1.0.0"},
+		SunsetAt:    &sunset,
+	})
+	require.NoError(t, err)
+	rec, _ = serveAs(t, mux, "data@example.com", http.MethodPost, versionURL+"/deprecate", body)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "only maintainers deprecate")
+	rec, deprecated := serveAs(t, mux, maintainer, http.MethodPost, versionURL+"/deprecate", body)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	metadata := deprecated["metadata"].(map[string]any)
+	assert.Equal(t, "deprecated", metadata["status"])
+	assert.Equal(t, "Replaced by Aurora", metadata["deprecation"].(map[string]any)["reason"])
+
+	for _, target := range []string{versionURL, versionURL + "/schema/inputs", versionURL + "/signatures"} {
+		rec, _ = serve(t, mux, http.MethodGet, target, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Regexp(t, `^@\d+$`, rec.Header().Get(DeprecationHeader), target)
+		assert.Equal(t, sunset.Format(http.TimeFormat), rec.Header().Get(SunsetHeader), target)
+		assert.Contains(t, rec.Header().Get(WarningHeader), `299 - "postgres:1.0.0 is deprecated: Replaced by Aurora; use aurora@^1.0.0 instead`)
+	}
+	rec, _ = serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions/1.1.0", nil)
+	assert.Empty(t, rec.Header().Get(DeprecationHeader), "other versions are not deprecated")
+
+	_, history := serve(t, mux, http.MethodGet, "/api/v1/components/postgres/versions", nil)
+	versions := history["versions"].([]any)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "deprecated", versions[1].(map[string]any)["status"])
+	assert.NotEmpty(t, versions[1].(map[string]any)["sunset_at"])
+
+	rec, all := serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components/mysql/deprecate", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	components := all["components"].([]any)
+	require.Len(t, components, 2)
+	assert.Equal(t, "2.0.0", components[0].(map[string]any)["version"])
+	for _, component := range components {
+		assert.Equal(t, "deprecated", component.(map[string]any)["metadata"].(map[string]any)["status"])
+	}
+	rec, _ = serve(t, mux, http.MethodGet, "/api/v1/components/mysql/versions/1.0.0", nil)
+	assert.NotEmpty(t, rec.Header().Get(DeprecationHeader))
+	assert.Empty(t, rec.Header().Get(SunsetHeader))
+
+	rec, _ = serveAs(t, mux, maintainer, http.MethodPost, "/api/v1/components/redis/deprecate", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	_, changes := serve(t, mux, http.MethodGet, "/api/v1/components/mysql/changes", nil)
+	assert.Len(t, changes["changes"], 2)
+}
+
+func TestComponentHandler_ListWarnings(t *testing.T) {
+	deprecated := func(c *models.Component, sunset time.Time) *models.Component {
+		deprecatedAt := sunset.Add(-30 * 24 * time.Hour)
+		c.Metadata.Deprecated = true
+		c.Metadata.DeprecatedAt = &deprecatedAt
+		c.Metadata.Status = models.VersionStatusDeprecated
+		c.Metadata.Deprecation = &models.Deprecation{Reason: "Replaced by Aurora", SunsetAt: &sunset}
+		return c
+	}
+	mux, _ := newServer(t,
+		deprecated(newComponent("postgres", "1.0.0"), time.Now().Add(-time.Hour)),
+		deprecated(newComponent("postgres", "1.1.0"), time.Now().Add(24*time.Hour)),
+		newComponent("postgres", "2.0.0"),
+	)
+
+	for _, target := range []string{"/api/v1/components", "/api/v1/components/postgres/versions"} {
+		rec, body := serve(t, mux, http.MethodGet, target, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		warnings := body["warnings"].([]any)
+		require.Len(t, warnings, 2, target)
+		codes := map[string]any{}
+		for _, warning := range warnings {
+			codes[warning.(map[string]any)["version"].(string)] = warning.(map[string]any)["code"]
+		}
+		assert.Equal(t, map[string]any{"1.0.0": models.WarningSunset, "1.1.0": models.WarningDeprecated}, codes, target)
+
+		assert.Len(t, rec.Header().Values(WarningHeader), 2, "every warning has a header: %s", target)
+		assert.Empty(t, rec.Header().Get(DeprecationHeader), "the list itself is not deprecated: %s", target)
+		assert.Empty(t, rec.Header().Get(SunsetHeader), target)
+	}
+
+	rec, body := serve(t, mux, http.MethodGet, "/api/v1/components?version=^2.0.0", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, body, "warnings")
+	assert.Empty(t, rec.Header().Values(WarningHeader))
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// HealthHandler serves the health endpoint.
+type HealthHandler struct {
+	store  storage.ComponentStore
+	logger logging.Logger
+}
+
+// NewHealthHandler creates a HealthHandler that checks store.
+func NewHealthHandler(store storage.ComponentStore, logger logging.Logger) *HealthHandler {
+	return &HealthHandler{
+		store:  store,
+		logger: logger.With("component", "health_handler"),
+	}
+}
+
+// Register adds the health routes to mux.
+func (h *HealthHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /health", h.health)
+}
+
+func (h *HealthHandler) health(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.HealthCheck(r.Context()); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
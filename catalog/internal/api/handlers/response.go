@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// CodeInternal is returned for errors that do not come from the storage
+// layer. Their message is not exposed to clients.
+const CodeInternal = "INTERNAL_ERROR"
+
+// maxBodySize bounds request bodies.
+const maxBodySize = 1 << 20
+
+// UserHeader carries the identity of the caller, as set by the
+// authenticating proxy in front of the catalog. Drafts are only served to
+// the maintainers it names.
+const UserHeader = "X-Nestor-User"
+
+// Headers describing the deprecation of the component version served.
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	WarningHeader     = "Warning"
+)
+
+// ErrorResponse is the body of every error returned by the API.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes a single error.
+type ErrorBody struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	writeJSONAs(w, status, "application/json", v)
+}
+
+func writeJSONAs(w http.ResponseWriter, status int, contentType string, v any) {
+	data, err := json.ToJSON(v)
+	if err != nil {
+		http.Error(w, `{"error":{"code":"`+CodeInternal+`","message":"failed to encode response"}}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// writeError maps err to a status code with storage.StatusOf and writes it
+// as an ErrorResponse.
+func writeError(w http.ResponseWriter, r *http.Request, logger logging.Logger, err error) {
+	status, _ := storage.StatusOf(err)
+
+	body := ErrorBody{Code: CodeInternal, Message: "internal server error"}
+	var storageErr *storage.StorageError
+	if errors.As(err, &storageErr) {
+		body = ErrorBody{
+			Code:    storageErr.Code,
+			Message: storageErr.Message,
+			Details: storageErr.Details,
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		logger.ErrorContext(r.Context(), "request failed",
+			"method", r.Method, "path", r.URL.Path, "status", status, "error", err)
+	}
+
+	writeJSON(w, status, ErrorResponse{Error: body})
+}
+
+// setDeprecationHeaders describes the deprecation of component with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers and its warnings with
+// Warning headers, so that clients notice deprecations without inspecting
+// the body.
+func setDeprecationHeaders(w http.ResponseWriter, component *models.Component) {
+	if !component.IsDeprecated() {
+		return
+	}
+	if deprecatedAt := component.Metadata.DeprecatedAt; deprecatedAt != nil {
+		w.Header().Set(DeprecationHeader, fmt.Sprintf("@%d", deprecatedAt.Unix()))
+	}
+	if sunset := component.SunsetAt(); sunset != nil {
+		w.Header().Set(SunsetHeader, sunset.UTC().Format(http.TimeFormat))
+	}
+	setWarningHeaders(w, component.Warnings(time.Now()))
+}
+
+// setWarningHeaders adds a Warning header for each of warnings. Responses
+// listing several versions only carry these, as the Deprecation and Sunset
+// headers describe the resource served.
+func setWarningHeaders(w http.ResponseWriter, warnings []models.Warning) {
+	for _, warning := range warnings {
+		w.Header().Add(WarningHeader, fmt.Sprintf("299 - %q", warning.Message))
+	}
+}
+
+// requestUser returns the caller of r, or an empty string for anonymous
+// requests.
+func requestUser(r *http.Request) string {
+	return r.Header.Get(UserHeader)
+}
+
+// requestURL returns the absolute URL of r, without its query.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// TaxonomyHandler serves the managed taxonomy and its admin operations.
+type TaxonomyHandler struct {
+	taxonomy storage.TaxonomyStore
+	store    storage.ComponentStore
+	logger   logging.Logger
+}
+
+// NewTaxonomyHandler creates a TaxonomyHandler. store is used to refuse the
+// removal of terms that components still use.
+func NewTaxonomyHandler(taxonomy storage.TaxonomyStore, store storage.ComponentStore, logger logging.Logger) *TaxonomyHandler {
+	return &TaxonomyHandler{
+		taxonomy: taxonomy,
+		store:    store,
+		logger:   logger.With("component", "taxonomy_handler"),
+	}
+}
+
+// Register adds the taxonomy routes to mux.
+func (h *TaxonomyHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/taxonomy", h.getTaxonomy)
+	mux.HandleFunc("PUT /api/v1/taxonomy", h.putTaxonomy)
+	mux.HandleFunc("PUT /api/v1/taxonomy/providers/{name}", h.putProvider)
+	mux.HandleFunc("DELETE /api/v1/taxonomy/providers/{name}", h.deleteProvider)
+	mux.HandleFunc("PUT /api/v1/taxonomy/categories/{name}", h.putCategory)
+	mux.HandleFunc("DELETE /api/v1/taxonomy/categories/{name}", h.deleteCategory)
+}
+
+func (h *TaxonomyHandler) getTaxonomy(w http.ResponseWriter, r *http.Request) {
+	taxonomy, err := h.taxonomy.GetTaxonomy(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, taxonomy)
+}
+
+// putTaxonomy replaces the whole taxonomy. The body carries the revision it
+// was read at.
+func (h *TaxonomyHandler) putTaxonomy(w http.ResponseWriter, r *http.Request) {
+	var taxonomy models.Taxonomy
+	if err := readBody(w, r, &taxonomy); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	h.save(w, r, &taxonomy)
+}
+
+func (h *TaxonomyHandler) putProvider(w http.ResponseWriter, r *http.Request) {
+	var term models.TaxonomyTerm
+	if err := readBody(w, r, &term); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	if err := checkName(r, term.Name); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	term.Name = r.PathValue("name")
+
+	h.update(w, r, func(taxonomy *models.Taxonomy) error {
+		taxonomy.SetProvider(term)
+		return nil
+	})
+}
+
+func (h *TaxonomyHandler) deleteProvider(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	h.update(w, r, func(taxonomy *models.Taxonomy) error {
+		if !taxonomy.RemoveProvider(name) {
+			return storage.NewResourceNotFoundError("provider", name)
+		}
+		return h.checkUnused(r, "provider", name, storage.ComponentFilters{Providers: []string{name}})
+	})
+}
+
+func (h *TaxonomyHandler) putCategory(w http.ResponseWriter, r *http.Request) {
+	var category models.TaxonomyCategory
+	if err := readBody(w, r, &category); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	if err := checkName(r, category.Name); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	category.Name = r.PathValue("name")
+
+	h.update(w, r, func(taxonomy *models.Taxonomy) error {
+		taxonomy.SetCategory(category)
+		return nil
+	})
+}
+
+func (h *TaxonomyHandler) deleteCategory(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	h.update(w, r, func(taxonomy *models.Taxonomy) error {
+		if !taxonomy.RemoveCategory(name) {
+			return storage.NewResourceNotFoundError("category", name)
+		}
+		return h.checkUnused(r, "category", name, storage.ComponentFilters{Categories: []string{name}})
+	})
+}
+
+// update applies change to the current taxonomy and stores it. Concurrent
+// updates are reported as conflicts rather than retried.
+func (h *TaxonomyHandler) update(w http.ResponseWriter, r *http.Request, change func(*models.Taxonomy) error) {
+	taxonomy, err := h.taxonomy.GetTaxonomy(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	if err := change(taxonomy); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	h.save(w, r, taxonomy)
+}
+
+func (h *TaxonomyHandler) save(w http.ResponseWriter, r *http.Request, taxonomy *models.Taxonomy) {
+	if err := h.taxonomy.PutTaxonomy(r.Context(), taxonomy); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, taxonomy)
+}
+
+// checkUnused refuses to remove a term that published components, drafts
+// included, still use.
+func (h *TaxonomyHandler) checkUnused(r *http.Request, kind, name string, filters storage.ComponentFilters) error {
+	filters.IncludeDrafts = true
+	var used []string
+	err := storage.ForEachPage(r.Context(), h.store, filters, 100, "", func(page *storage.ComponentList) error {
+		for _, component := range page.Components {
+			used = append(used, component.GetID())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(used) > 0 {
+		return storage.NewConflictError("taxonomy", fmt.Sprintf("%s '%s' is used by %d component versions", kind, name, len(used))).
+			WithDetail("components", used)
+	}
+	return nil
+}
+
+// checkName rejects a body whose name differs from the one in the path.
+func checkName(r *http.Request, name string) error {
+	if name != "" && name != r.PathValue("name") {
+		return storage.NewValidationError("name", fmt.Sprintf("body name '%s' does not match path name '%s'", name, r.PathValue("name")))
+	}
+	return nil
+}
+
+// readBody decodes the JSON request body into v.
+func readBody(w http.ResponseWriter, r *http.Request, v any) error {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		return storage.NewValidationError("body", "request body is unreadable or too large").WithCause(err)
+	}
+	if err := json.FromJSON(data, v); err != nil {
+		return storage.NewValidationError("body", "request body is not valid JSON").WithCause(err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+func newTaxonomyServer(t *testing.T, components ...*models.Component) (*http.ServeMux, storage.ComponentStore) {
+	t.Helper()
+
+	mux, store := newServer(t, components...)
+	NewTaxonomyHandler(store.(storage.TaxonomyStore), store, logging.NewNoop()).Register(mux)
+	return mux, store
+}
+
+func TestTaxonomyHandler_EvolveTaxonomy(t *testing.T) {
+	mux, _ := newTaxonomyServer(t)
+
+	rec, body := serve(t, mux, http.MethodGet, "/api/v1/taxonomy", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, float64(0), body["revision"])
+
+	data, err := json.ToJSON(models.TaxonomyTerm{DisplayName: "Amazon Web Services", Aliases: []string{"amazon"}})
+	require.NoError(t, err)
+	rec, body = serve(t, mux, http.MethodPut, "/api/v1/taxonomy/providers/aws", data)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, float64(1), body["revision"])
+
+	data, err = json.ToJSON(models.TaxonomyCategory{
+		TaxonomyTerm:  models.TaxonomyTerm{DisplayName: "Databases"},
+		SubCategories: []models.TaxonomyTerm{{Name: "relational", DisplayName: "Relational", Aliases: []string{"sql"}}},
+	})
+	require.NoError(t, err)
+	rec, _ = serve(t, mux, http.MethodPut, "/api/v1/taxonomy/categories/database", data)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// A stale revision is rejected.
+	data, err = json.ToJSON(models.Taxonomy{Revision: 1})
+	require.NoError(t, err)
+	rec, body = serve(t, mux, http.MethodPut, "/api/v1/taxonomy", data)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, storage.CodeConflict, body["error"].(map[string]any)["code"])
+
+	data, err = json.ToJSON(models.TaxonomyTerm{Name: "gcp", DisplayName: "Google Cloud"})
+	require.NoError(t, err)
+	rec, _ = serve(t, mux, http.MethodPut, "/api/v1/taxonomy/providers/aws", data)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	data, err = json.ToJSON(models.TaxonomyTerm{DisplayName: "Google Cloud", Aliases: []string{"Amazon"}})
+	require.NoError(t, err)
+	rec, body = serve(t, mux, http.MethodPut, "/api/v1/taxonomy/providers/gcp", data)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, body["error"].(map[string]any)["details"].(map[string]any)["errors"], 1)
+}
+
+func TestTaxonomyHandler_PublishAndFilter(t *testing.T) {
+	mux, store := newTaxonomyServer(t)
+	taxonomyStore := store.(storage.TaxonomyStore)
+	ctx := context.Background()
+
+	taxonomy, err := taxonomyStore.GetTaxonomy(ctx)
+	require.NoError(t, err)
+	taxonomy.SetProvider(models.TaxonomyTerm{Name: "aws", DisplayName: "AWS", Aliases: []string{"amazon"}})
+	taxonomy.SetCategory(models.TaxonomyCategory{TaxonomyTerm: models.TaxonomyTerm{Name: "database", DisplayName: "Databases"}})
+	require.NoError(t, taxonomyStore.PutTaxonomy(ctx, taxonomy))
+
+	component := newComponent("postgres", "1.0.0")
+	component.Provider = "Amazon"
+	data, err := json.ToJSON(component)
+	require.NoError(t, err)
+	rec, body := serve(t, mux, http.MethodPost, "/api/v1/components", data)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "aws", body["provider"])
+
+	component = newComponent("mysql", "1.0.0")
+	component.Provider = "oracle"
+	data, err = json.ToJSON(component)
+	require.NoError(t, err)
+	rec, body = serve(t, mux, http.MethodPost, "/api/v1/components", data)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	issues := body["error"].(map[string]any)["details"].(map[string]any)["errors"].([]any)
+	assert.Equal(t, "provider", issues[0].(map[string]any)["path"])
+
+	rec, body = serveAs(t, mux, maintainer, http.MethodGet, "/api/v1/components?provider=AMAZON", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, body["components"], 1)
+
+	rec, body = serve(t, mux, http.MethodDelete, "/api/v1/taxonomy/providers/aws", nil)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, []any{"postgres:1.0.0"}, body["error"].(map[string]any)["details"].(map[string]any)["components"])
+
+	rec, _ = serve(t, mux, http.MethodDelete, "/api/v1/taxonomy/providers/gcp", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
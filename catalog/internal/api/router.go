@@ -0,0 +1,23 @@
+// Package api exposes the catalog over HTTP.
+package api
+
+import (
+	"net/http"
+
+	"github.com/HatiCode/nestor/catalog/internal/api/handlers"
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// NewRouter returns an http.Handler serving the catalog API from store. The
+// taxonomy admin routes are only served when store implements
+// storage.TaxonomyStore.
+func NewRouter(store storage.ComponentStore, logger logging.Logger) http.Handler {
+	mux := http.NewServeMux()
+	handlers.NewComponentHandler(store, logger).Register(mux)
+	handlers.NewHealthHandler(store, logger).Register(mux)
+	if taxonomy, ok := store.(storage.TaxonomyStore); ok {
+		handlers.NewTaxonomyHandler(taxonomy, store, logger).Register(mux)
+	}
+	return mux
+}
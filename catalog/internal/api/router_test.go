@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/api/handlers"
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	_ "github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/catalog/pkg/signing"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+const maintainer = "platform@example.com"
+
+func newComponent() *models.Component {
+	return &models.Component{
+		Name:        "postgres",
+		Version:     "1.0.0",
+		Provider:    "aws",
+		Category:    "database",
+		Maintainers: []string{maintainer},
+		Inputs: []models.InputSpec{
+			{Name: "encrypted", Type: "bool", Description: "Encrypt storage", Default: true},
+		},
+		Outputs: []models.OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Database endpoint"},
+		},
+		Deployment: models.DeploymentSpec{
+			Engine:  "terraform",
+			Version: "1.5.0",
+			Config:  map[string]any{"source": "git::https://example.com/postgres", "module_version": "1.0.0"},
+		},
+	}
+}
+
+func serve(t *testing.T, router http.Handler, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.ToJSON(body)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(data))
+	req.Header.Set(handlers.UserHeader, maintainer)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNewRouter_ChecksPublishAndPromote(t *testing.T) {
+	frozen := false
+	store, err := storage.NewComponentStore(&storage.StorageConfig{
+		Type:     "memory",
+		Policies: []string{"../policy/testdata"},
+		Checks: []storage.PublishCheck{func(ctx context.Context, component *models.Component) (models.ValidationErrors, error) {
+			if frozen {
+				return nil, storage.NewValidationError("component", "publishing is frozen")
+			}
+			return nil, nil
+		}},
+	}, nil, logging.NewNoop())
+	require.NoError(t, err)
+	router := NewRouter(store, logging.NewNoop())
+	versionURL := "/api/v1/components/postgres/versions/1.0.0"
+
+	unencrypted := newComponent()
+	unencrypted.Inputs[0].Default = false
+	rec := serve(t, router, http.MethodPost, "/api/v1/components", unencrypted)
+	require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "aws-database-encryption")
+
+	rec = serve(t, router, http.MethodPost, "/api/v1/components", newComponent())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	frozen = true
+	rec = serve(t, router, http.MethodPost, versionURL+"/promote", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "promotions are checked again")
+	assert.Contains(t, rec.Body.String(), "publishing is frozen")
+
+	frozen = false
+	rec = serve(t, router, http.MethodPost, versionURL+"/promote", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	for _, route := range []struct{ method, target string }{
+		{http.MethodGet, "/api/v1/components/postgres/changes"},
+		{http.MethodGet, "/api/v1/taxonomy"},
+		{http.MethodPost, versionURL + "/deprecate"},
+	} {
+		rec = serve(t, router, route.method, route.target, nil)
+		assert.Equal(t, http.StatusOK, rec.Code, "%s %s: %s", route.method, route.target, rec.Body.String())
+	}
+
+	vpc := newComponent()
+	vpc.Name = "vpc"
+	vpc.Category = "networking"
+	rec = serve(t, router, http.MethodPost, "/api/v1/components", vpc)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "policy 'networking-maintainers'", "warn violations are returned on publish")
+
+	rec = serve(t, router, http.MethodPost, "/api/v1/components/vpc/versions/1.0.0/promote", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "policy 'networking-maintainers'", "and on promotion")
+}
+
+func TestNewRouter_SignedComponentsUseCanonicalTaxonomyNames(t *testing.T) {
+	ctx := context.Background()
+	publicKey, privateKey, err := signing.GenerateKey()
+	require.NoError(t, err)
+	keysPath := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keysPath, []byte("keys:\n  - id: platform-2024\n    publisher: platform-team\n    public_key: "+publicKey+"\n"), 0o600))
+	key, err := signing.ParsePrivateKey(privateKey)
+	require.NoError(t, err)
+
+	store, err := storage.NewComponentStore(&storage.StorageConfig{
+		Type:    "memory",
+		Signing: &storage.SigningStorageConfig{Keys: keysPath, Required: true},
+	}, nil, logging.NewNoop())
+	require.NoError(t, err)
+	taxonomyStore := store.(storage.TaxonomyStore)
+	taxonomy, err := taxonomyStore.GetTaxonomy(ctx)
+	require.NoError(t, err)
+	taxonomy.SetProvider(models.TaxonomyTerm{Name: "aws", DisplayName: "AWS", Aliases: []string{"amazon"}})
+	taxonomy.SetCategory(models.TaxonomyCategory{TaxonomyTerm: models.TaxonomyTerm{Name: "database", DisplayName: "Databases", Aliases: []string{"db"}}})
+	require.NoError(t, taxonomyStore.PutTaxonomy(ctx, taxonomy))
+	router := NewRouter(store, logging.NewNoop())
+
+	aliased := newComponent()
+	aliased.Provider = "Amazon"
+	require.NoError(t, signing.Sign(aliased, "platform-2024", key))
+	rec := serve(t, router, http.MethodPost, "/api/v1/components", aliased)
+	require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), models.RuleCanonical)
+	assert.Contains(t, rec.Body.String(), "provider 'Amazon' must be given by its canonical name 'aws'")
+
+	canonical := newComponent()
+	require.NoError(t, signing.Sign(canonical, "platform-2024", key))
+	rec = serve(t, router, http.MethodPost, "/api/v1/components", canonical)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = serve(t, router, http.MethodPost, "/api/v1/components/postgres/versions/1.0.0/promote", nil)
+	require.Equal(t, http.StatusOK, rec.Code, "the signature still verifies at promotion: %s", rec.Body.String())
+
+	stored, err := store.GetComponent(ctx, "postgres", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "platform-team", stored.Metadata.Signatures[0].Publisher)
+}
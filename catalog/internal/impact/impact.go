@@ -0,0 +1,256 @@
+// Package impact answers reverse-dependency questions: which component
+// versions depend on a component, and what happens to them if one of its
+// versions is deprecated or yanked.
+package impact
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+const pageSize = 100
+
+// Action is a lifecycle change whose impact can be analysed.
+type Action string
+
+const (
+	// ActionDeprecate keeps the version resolvable but discourages its use.
+	ActionDeprecate Action = "deprecate"
+	// ActionYank removes the version from resolution.
+	ActionYank Action = "yank"
+)
+
+// Dependent is a component version declaring a dependency on another
+// component, or on a capability it provides.
+type Dependent struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Constraint string `json:"constraint"`
+	Optional   bool   `json:"optional,omitempty"`
+	Condition  string `json:"condition,omitempty"`
+	// Capability names the capability depended on, when the dependency is
+	// not on the component itself.
+	Capability string `json:"capability,omitempty"`
+}
+
+// Impact is a dependent whose constraint currently admits the analysed
+// version.
+type Impact struct {
+	Dependent
+	// Alternatives lists the other versions that still satisfy the
+	// constraint after the action, highest first. For a capability they
+	// are the name@version of every remaining provider.
+	Alternatives []string `json:"alternatives"`
+	// Stranded is true when no alternative exists: after a yank the
+	// dependent no longer resolves, after a deprecation it can only
+	// resolve to deprecated versions.
+	Stranded bool `json:"stranded"`
+}
+
+// Report lists every dependent affected by an action on name@version.
+type Report struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Action   Action   `json:"action"`
+	Affected []Impact `json:"affected"`
+	// Unaffected counts dependents whose constraint already excludes the
+	// version.
+	Unaffected int `json:"unaffected"`
+}
+
+// Stranded returns the affected dependents left without an alternative.
+func (r *Report) Stranded() []Impact {
+	var stranded []Impact
+	for _, impact := range r.Affected {
+		if impact.Stranded {
+			stranded = append(stranded, impact)
+		}
+	}
+	return stranded
+}
+
+// Dependents returns every component version that depends on name or on one
+// of capabilities, ordered by name and version.
+func Dependents(ctx context.Context, store storage.ComponentStore, name string, capabilities ...string) ([]Dependent, error) {
+	var dependents []Dependent
+	for _, target := range append([]string{name}, capabilities...) {
+		filters := storage.ComponentFilters{HasDependency: target}
+		err := storage.ForEachPage(ctx, store, filters, pageSize, "", func(page *storage.ComponentList) error {
+			for _, component := range page.Components {
+				for _, dep := range component.Dependencies {
+					if dep.Name != target {
+						continue
+					}
+					dependent := Dependent{
+						Name:       component.Name,
+						Version:    component.Version,
+						Constraint: dep.Version,
+						Optional:   dep.Optional,
+						Condition:  dep.Condition,
+					}
+					if target != name {
+						dependent.Capability = target
+					}
+					dependents = append(dependents, dependent)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dependents of %s: %w", target, err)
+		}
+	}
+
+	slices.SortFunc(dependents, func(a, b Dependent) int {
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			models.CompareVersions(a.Version, b.Version),
+			cmp.Compare(a.Capability, b.Capability),
+		)
+	})
+	return dependents, nil
+}
+
+// Analyze reports which dependents of name, or of the capabilities version
+// provides, would be affected by applying action to version.
+func Analyze(ctx context.Context, store storage.ComponentStore, name, version string, action Action) (*Report, error) {
+	if action != ActionDeprecate && action != ActionYank {
+		return nil, storage.NewValidationError("action", fmt.Sprintf("unknown action %q", action))
+	}
+
+	target, err := models.ParseSemanticVersion(version)
+	if err != nil {
+		return nil, storage.NewValidationError("version", err.Error()).WithCause(err)
+	}
+	component, err := store.GetComponent(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := remainingVersions(ctx, store, name, version, action)
+	if err != nil {
+		return nil, err
+	}
+	alternatives := map[string][]alternative{"": make([]alternative, 0, len(remaining))}
+	for _, info := range remaining {
+		alternatives[""] = append(alternatives[""], alternative{id: info.Raw, version: info})
+	}
+	for _, capability := range component.Provides {
+		if alternatives[capability], err = remainingProviders(ctx, store, capability, name, version, action); err != nil {
+			return nil, err
+		}
+	}
+
+	dependents, err := Dependents(ctx, store, name, component.Provides...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Name: name, Version: version, Action: action, Affected: []Impact{}}
+	parser := models.NewConstraintParser()
+	for _, dependent := range dependents {
+		constraint, err := parser.Parse(dependent.Constraint)
+		if err != nil {
+			// A constraint we cannot read may well admit the version.
+			report.Affected = append(report.Affected, Impact{Dependent: dependent, Stranded: true})
+			continue
+		}
+		if !constraint.Satisfies(target) {
+			report.Unaffected++
+			continue
+		}
+
+		impact := Impact{Dependent: dependent, Alternatives: []string{}}
+		for _, candidate := range alternatives[dependent.Capability] {
+			if constraint.Satisfies(candidate.version) {
+				impact.Alternatives = append(impact.Alternatives, candidate.id)
+			}
+		}
+		impact.Stranded = len(impact.Alternatives) == 0
+		report.Affected = append(report.Affected, impact)
+	}
+
+	return report, nil
+}
+
+// alternative is a version a dependent could resolve to instead of the one
+// analysed. id is reported in Impact.Alternatives.
+type alternative struct {
+	id      string
+	version *models.SemanticVersionInfo
+}
+
+// remainingProviders returns the versions providing capability that
+// dependents could still resolve to once action is applied to name@version,
+// ordered by provider name then highest version first.
+func remainingProviders(ctx context.Context, store storage.ComponentStore, capability, name, version string, action Action) ([]alternative, error) {
+	providing := make(map[string]map[string]bool)
+	filters := storage.ComponentFilters{ProvidesDependency: capability}
+	err := storage.ForEachPage(ctx, store, filters, pageSize, "", func(page *storage.ComponentList) error {
+		for _, component := range page.Components {
+			if providing[component.Name] == nil {
+				providing[component.Name] = make(map[string]bool)
+			}
+			providing[component.Name][component.Version] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list providers of %s: %w", capability, err)
+	}
+
+	var providers []alternative
+	for _, provider := range slices.Sorted(maps.Keys(providing)) {
+		excluded := ""
+		if provider == name {
+			excluded = version
+		}
+		remaining, err := remainingVersions(ctx, store, provider, excluded, action)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range remaining {
+			if providing[provider][info.Raw] {
+				providers = append(providers, alternative{id: provider + "@" + info.Raw, version: info})
+			}
+		}
+	}
+	return providers, nil
+}
+
+// remainingVersions returns the versions of name dependents could still
+// resolve to once action is applied to version, highest first.
+func remainingVersions(ctx context.Context, store storage.ComponentStore, name, version string, action Action) ([]*models.SemanticVersionInfo, error) {
+	history, err := store.GetVersionHistory(ctx, name)
+	if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+		return nil, fmt.Errorf("failed to read versions of %s: %w", name, err)
+	}
+
+	var remaining []*models.SemanticVersionInfo
+	for _, entry := range history {
+		switch {
+		case entry.Version == version,
+			entry.IsYanked(),
+			entry.Status == models.VersionStatusDraft,
+			action == ActionDeprecate && entry.IsDeprecated():
+			continue
+		}
+		info, err := models.ParseSemanticVersion(entry.Version)
+		if err != nil {
+			continue
+		}
+		remaining = append(remaining, info)
+	}
+
+	slices.SortFunc(remaining, func(a, b *models.SemanticVersionInfo) int {
+		return b.Compare(a)
+	})
+	return remaining, nil
+}
@@ -0,0 +1,153 @@
+package impact
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// component builds a component from "name@version" followed by its
+// dependencies as "name@constraint".
+func component(id string, deps ...string) *models.Component {
+	name, version, _ := strings.Cut(id, "@")
+	c := &models.Component{
+		Name:       name,
+		Version:    version,
+		Provider:   "aws",
+		Category:   "networking",
+		Deployment: models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+	}
+	for _, dep := range deps {
+		depName, constraint, _ := strings.Cut(dep, "@")
+		c.Dependencies = append(c.Dependencies, models.Dependency{Name: depName, Type: "component", Version: constraint})
+	}
+	return c
+}
+
+// statusStore reports a fixed status for some versions.
+type statusStore struct {
+	storage.ComponentStore
+	statuses map[string]models.VersionStatus
+}
+
+func (s statusStore) GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error) {
+	history, err := s.ComponentStore.GetVersionHistory(ctx, name)
+	for i, version := range history {
+		if status, ok := s.statuses[name+"@"+version.Version]; ok {
+			history[i].Status = status
+		}
+	}
+	return history, err
+}
+
+func newStore(t *testing.T) storage.ComponentStore {
+	t.Helper()
+
+	store := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), []*models.Component{
+		component("vpc@1.1.0"),
+		component("vpc@1.2.0"),
+		component("vpc@1.3.0"),
+		component("vpc@2.0.0"),
+		component("rds@1.0.0", "vpc@^1.0.0"),
+		component("rds@1.1.0", "vpc@~1.2.0"),
+		component("eks@3.0.0", "vpc@1.2.0"),
+		component("lambda@1.0.0", "vpc@^2.0.0"),
+		component("s3@1.0.0"),
+	}))
+	return statusStore{
+		ComponentStore: store,
+		statuses:       map[string]models.VersionStatus{"vpc@1.3.0": models.VersionStatusDeprecated},
+	}
+}
+
+func TestDependents(t *testing.T) {
+	dependents, err := Dependents(context.Background(), newStore(t), "vpc")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Dependent{
+		{Name: "eks", Version: "3.0.0", Constraint: "1.2.0"},
+		{Name: "lambda", Version: "1.0.0", Constraint: "^2.0.0"},
+		{Name: "rds", Version: "1.0.0", Constraint: "^1.0.0"},
+		{Name: "rds", Version: "1.1.0", Constraint: "~1.2.0"},
+	}, dependents)
+
+	dependents, err = Dependents(context.Background(), newStore(t), "s3")
+	require.NoError(t, err)
+	assert.Empty(t, dependents)
+}
+
+func TestAnalyze(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("yank", func(t *testing.T) {
+		report, err := Analyze(ctx, newStore(t), "vpc", "1.2.0", ActionYank)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Unaffected)
+		require.Len(t, report.Affected, 3)
+		assert.Equal(t, "eks", report.Affected[0].Name)
+		assert.True(t, report.Affected[0].Stranded)
+		assert.Equal(t, []string{"1.3.0", "1.1.0"}, report.Affected[1].Alternatives, "deprecated versions still resolve")
+		assert.Equal(t, "rds@1.1.0", report.Affected[2].Name+"@"+report.Affected[2].Version)
+		assert.True(t, report.Affected[2].Stranded)
+		assert.Len(t, report.Stranded(), 2)
+	})
+
+	t.Run("deprecate", func(t *testing.T) {
+		report, err := Analyze(ctx, newStore(t), "vpc", "1.2.0", ActionDeprecate)
+		require.NoError(t, err)
+		require.Len(t, report.Affected, 3)
+		assert.Equal(t, []string{"1.1.0"}, report.Affected[1].Alternatives, "deprecated versions are no alternative")
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := Analyze(ctx, newStore(t), "vpc", "9.0.0", ActionYank)
+		assert.ErrorIs(t, err, storage.ErrResourceNotFound)
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		_, err := Analyze(ctx, newStore(t), "vpc", "1.2.0", "delete")
+		assert.ErrorIs(t, err, storage.ErrValidation)
+	})
+}
+
+func TestAnalyze_Capabilities(t *testing.T) {
+	ctx := context.Background()
+	provides := func(c *models.Component, capabilities ...string) *models.Component {
+		c.Provides = capabilities
+		return c
+	}
+	store := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(ctx, []*models.Component{
+		provides(component("vpc@1.1.0"), "network"),
+		provides(component("vpc@1.2.0"), "network"),
+		provides(component("shared-vpc@1.0.0"), "network"),
+		component("shared-vpc@2.0.0"),
+		component("ecs@1.0.0", "network@>=1.0.0"),
+		component("eks@1.0.0", "network@^1.2.0", "vpc@^1.0.0"),
+	}))
+
+	dependents, err := Dependents(ctx, store, "vpc", "network")
+	require.NoError(t, err)
+	assert.Equal(t, []Dependent{
+		{Name: "ecs", Version: "1.0.0", Constraint: ">=1.0.0", Capability: "network"},
+		{Name: "eks", Version: "1.0.0", Constraint: "^1.0.0"},
+		{Name: "eks", Version: "1.0.0", Constraint: "^1.2.0", Capability: "network"},
+	}, dependents)
+
+	report, err := Analyze(ctx, store, "vpc", "1.2.0", ActionYank)
+	require.NoError(t, err)
+	require.Len(t, report.Affected, 3, "dependents of the capabilities the version provides are affected")
+	assert.Equal(t, []string{"shared-vpc@1.0.0", "vpc@1.1.0"}, report.Affected[0].Alternatives, "only versions providing the capability are alternatives")
+	assert.Equal(t, []string{"1.1.0"}, report.Affected[1].Alternatives)
+	assert.True(t, report.Affected[2].Stranded)
+}
@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint records how far a migration got so that an interrupted run can
+// resume from the next unread page of the source.
+type Checkpoint struct {
+	NextToken string    `json:"next_token"`
+	Read      int       `json:"read"`
+	Written   int       `json:"written"`
+	Changes   int       `json:"changes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointStore persists migration checkpoints.
+type CheckpointStore interface {
+	// Load returns the last saved checkpoint, or nil if there is none.
+	Load(ctx context.Context) (*Checkpoint, error)
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+	Clear(ctx context.Context) error
+}
+
+// FileCheckpoint stores checkpoints as a JSON file on local disk.
+type FileCheckpoint struct {
+	path string
+}
+
+// NewFileCheckpoint creates a CheckpointStore backed by the file at path.
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+func (f *FileCheckpoint) Load(ctx context.Context) (*Checkpoint, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", f.path, err)
+	}
+	return &checkpoint, nil
+}
+
+// Save writes the checkpoint atomically so a crash mid-write never leaves a
+// truncated file behind.
+func (f *FileCheckpoint) Save(ctx context.Context, checkpoint *Checkpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (f *FileCheckpoint) Clear(ctx context.Context) error {
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
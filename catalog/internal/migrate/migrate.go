@@ -0,0 +1,281 @@
+// Package migrate copies a catalog from one ComponentStore to another, for
+// example to move an environment from DynamoDB to a different backend.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// DefaultPageSize is the number of components read from the source per page.
+const DefaultPageSize int32 = 100
+
+// Options controls a migration run.
+type Options struct {
+	// PageSize is the number of components read from the source per page.
+	PageSize int32
+	// DryRun reads the whole source and reports what would be written
+	// without touching the target or the checkpoint.
+	DryRun bool
+	// Verify compares both sides component by component once the copy is done.
+	Verify bool
+	// Checkpoints records progress after every page so an interrupted run
+	// can resume. Nil disables resumption.
+	Checkpoints CheckpointStore
+	// AllowRepublish migrates to targets that do not implement
+	// storage.BulkWriter by publishing every version again with
+	// StoreComponent. Versions are then validated again and stored as
+	// drafts with new timestamps, and their changes are not copied. Without
+	// it, such targets are rejected.
+	AllowRepublish bool
+	Logger         logging.Logger
+}
+
+// Report summarises a migration run.
+type Report struct {
+	Read    int `json:"read"`
+	Written int `json:"written"`
+	// Existing counts components already present in the target. It is only
+	// computed on dry runs.
+	Existing int `json:"existing"`
+	// Changes counts the changes copied with the components.
+	Changes int  `json:"changes"`
+	Pages   int  `json:"pages"`
+	DryRun  bool `json:"dry_run"`
+	Resumed bool `json:"resumed"`
+	// Taxonomy is true when the source has a taxonomy, which is copied
+	// unless DryRun is set.
+	Taxonomy bool `json:"taxonomy"`
+	// BulkWrites is true when the target accepted verbatim batched writes.
+	// Without them, see Options.AllowRepublish, versions are republished
+	// as drafts, their timestamps are reset and their changes are lost.
+	BulkWrites   bool          `json:"bulk_writes"`
+	Verification *VerifyReport `json:"verification,omitempty"`
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   time.Time     `json:"finished_at"`
+}
+
+// Migrate streams every component version from source into target, page by
+// page, with the changes recorded for each component and the taxonomy.
+// Versions and changes are written as they are, so statuses, timestamps and
+// history carry over. Targets that cannot write them verbatim are rejected
+// unless opts.AllowRepublish is set.
+func Migrate(ctx context.Context, source, target storage.ComponentStore, opts Options) (*Report, error) {
+	if source == nil || target == nil {
+		return nil, storage.NewValidationError("store", "source and target stores are required")
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.NewNoop()
+	}
+	logger = logger.With("component", "catalog_migration")
+
+	report := &Report{
+		DryRun:    opts.DryRun,
+		StartedAt: time.Now(),
+	}
+
+	bulk, ok := target.(storage.BulkWriter)
+	report.BulkWrites = ok
+	if !ok {
+		if !opts.AllowRepublish {
+			return nil, storage.NewValidationError("target", "target store cannot write components verbatim, "+
+				"migrating to it would reset the status, timestamps and changes of every version")
+		}
+		logger.WarnContext(ctx, "target does not support bulk writes, versions are republished as drafts without their timestamps and changes")
+	}
+
+	copied, err := copyTaxonomy(ctx, source, target, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.Taxonomy = copied
+	drafts, _ := source.(storage.DraftStore)
+	// migrated holds the components whose changes were copied.
+	migrated := make(map[string]bool)
+
+	token := ""
+	if opts.Checkpoints != nil && !opts.DryRun {
+		checkpoint, err := opts.Checkpoints.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil {
+			token = checkpoint.NextToken
+			report.Read = checkpoint.Read
+			report.Written = checkpoint.Written
+			report.Changes = checkpoint.Changes
+			report.Resumed = true
+			logger.InfoContext(ctx, "resuming migration from checkpoint",
+				"read", checkpoint.Read, "written", checkpoint.Written)
+		}
+	}
+
+	err = storage.ForEachPage(ctx, source, storage.ComponentFilters{IncludeDrafts: true}, opts.PageSize, token, func(page *storage.ComponentList) error {
+		report.Pages++
+		report.Read += len(page.Components)
+
+		if opts.DryRun {
+			existing, err := countExisting(ctx, target, page.Components)
+			if err != nil {
+				return err
+			}
+			report.Existing += existing
+			return nil
+		}
+
+		if err := writePage(ctx, target, bulk, page.Components); err != nil {
+			return err
+		}
+		report.Written += len(page.Components)
+
+		if bulk != nil && drafts != nil {
+			changes, err := copyChanges(ctx, drafts, bulk, page.Components, migrated)
+			if err != nil {
+				return err
+			}
+			report.Changes += changes
+		}
+
+		if opts.Checkpoints != nil {
+			if err := opts.Checkpoints.Save(ctx, &Checkpoint{
+				NextToken: page.NextToken,
+				Read:      report.Read,
+				Written:   report.Written,
+				Changes:   report.Changes,
+				UpdatedAt: time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+
+		logger.DebugContext(ctx, "migrated page", "size", len(page.Components), "written", report.Written)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if opts.Checkpoints != nil && !opts.DryRun {
+		if err := opts.Checkpoints.Clear(ctx); err != nil {
+			return report, err
+		}
+	}
+
+	if opts.Verify {
+		verification, err := Verify(ctx, source, target, opts.PageSize)
+		if err != nil {
+			return report, err
+		}
+		report.Verification = verification
+	}
+
+	report.FinishedAt = time.Now()
+	logger.InfoContext(ctx, "migration finished",
+		"read", report.Read, "written", report.Written, "dry_run", report.DryRun,
+		"duration", report.FinishedAt.Sub(report.StartedAt))
+
+	return report, nil
+}
+
+func writePage(ctx context.Context, target storage.ComponentStore, bulk storage.BulkWriter, components []*models.Component) error {
+	if len(components) == 0 {
+		return nil
+	}
+
+	if bulk != nil {
+		if err := bulk.PutComponents(ctx, components); err != nil {
+			return fmt.Errorf("failed to write components: %w", err)
+		}
+		return nil
+	}
+
+	for _, component := range components {
+		if _, err := target.StoreComponent(ctx, component); err != nil {
+			return fmt.Errorf("failed to write component %s: %w", component.GetID(), err)
+		}
+	}
+	return nil
+}
+
+// copyTaxonomy copies the taxonomy of source to target, if source ever
+// stored one, and reports whether it has one.
+func copyTaxonomy(ctx context.Context, source, target storage.ComponentStore, dryRun bool) (bool, error) {
+	sourceTaxonomies, ok := source.(storage.TaxonomyStore)
+	if !ok {
+		return false, nil
+	}
+	taxonomy, err := sourceTaxonomies.GetTaxonomy(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to read taxonomy: %w", err)
+	}
+	if taxonomy.Revision == 0 {
+		return false, nil
+	}
+
+	targetTaxonomies, ok := target.(storage.TaxonomyStore)
+	if !ok {
+		return true, storage.NewValidationError("target", "target store cannot store the taxonomy of the source")
+	}
+	if dryRun {
+		return true, nil
+	}
+	current, err := targetTaxonomies.GetTaxonomy(ctx)
+	if err != nil {
+		return true, fmt.Errorf("failed to read taxonomy of target: %w", err)
+	}
+	taxonomy.Revision = current.Revision
+	if err := targetTaxonomies.PutTaxonomy(ctx, taxonomy); err != nil {
+		return true, fmt.Errorf("failed to write taxonomy: %w", err)
+	}
+	return true, nil
+}
+
+// copyChanges copies the changes of the components in page that are not in
+// migrated yet, adds them to migrated and returns the number of changes
+// copied. A resumed run may copy the changes of a component again, which
+// replaces them.
+func copyChanges(ctx context.Context, source storage.DraftStore, target storage.BulkWriter, components []*models.Component, migrated map[string]bool) (int, error) {
+	copied := 0
+	for _, component := range components {
+		if migrated[component.Name] {
+			continue
+		}
+		changes, err := source.GetChanges(ctx, component.Name)
+		if err != nil {
+			return copied, fmt.Errorf("failed to read changes of %s: %w", component.Name, err)
+		}
+		if len(changes) > 0 {
+			if err := target.PutChanges(ctx, changes); err != nil {
+				return copied, fmt.Errorf("failed to write changes of %s: %w", component.Name, err)
+			}
+		}
+		migrated[component.Name] = true
+		copied += len(changes)
+	}
+	return copied, nil
+}
+
+func countExisting(ctx context.Context, target storage.ComponentStore, components []*models.Component) (int, error) {
+	existing := 0
+	for _, component := range components {
+		_, err := target.GetComponent(ctx, component.Name, component.Version)
+		switch {
+		case err == nil:
+			existing++
+		case errors.Is(err, storage.ErrResourceNotFound):
+		default:
+			return existing, fmt.Errorf("failed to check component %s in target: %w", component.GetID(), err)
+		}
+	}
+	return existing, nil
+}
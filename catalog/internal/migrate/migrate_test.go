@@ -0,0 +1,258 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+func newComponent(name, version string, createdAt time.Time) *models.Component {
+	return &models.Component{
+		Name:     name,
+		Version:  version,
+		Provider: "aws",
+		Category: "database",
+		Inputs: []models.InputSpec{
+			{Name: "db_name", Type: "string", Description: "Database name"},
+		},
+		Outputs: []models.OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Database endpoint"},
+		},
+		Deployment: models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt.Add(time.Hour),
+	}
+}
+
+func seed(t *testing.T, count int) storage.ComponentStore {
+	t.Helper()
+
+	store := memory.NewComponentStore(logging.NewNoop())
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var components []*models.Component
+	for i := range count {
+		components = append(components, newComponent(fmt.Sprintf("component-%02d", i/3), fmt.Sprintf("1.%d.0", i%3), created))
+	}
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), components))
+	return store
+}
+
+// storeOnly hides any optional interfaces of the wrapped store.
+type storeOnly struct {
+	storage.ComponentStore
+}
+
+// failingStore fails every write after the first n components.
+type failingStore struct {
+	storage.ComponentStore
+	remaining int
+}
+
+func (s *failingStore) PutComponents(ctx context.Context, components []*models.Component) error {
+	if s.remaining < len(components) {
+		return storage.NewStorageUnavailableError("injected failure")
+	}
+	s.remaining -= len(components)
+	return s.ComponentStore.(storage.BulkWriter).PutComponents(ctx, components)
+}
+
+func (s *failingStore) PutChanges(ctx context.Context, changes []models.ComponentChange) error {
+	return s.ComponentStore.(storage.BulkWriter).PutChanges(ctx, changes)
+}
+
+func TestMigrate_CopiesEverythingAndVerifies(t *testing.T) {
+	ctx := context.Background()
+	source := seed(t, 25)
+	target := memory.NewComponentStore(logging.NewNoop())
+
+	report, err := Migrate(ctx, source, target, Options{PageSize: 10, Verify: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 25, report.Read)
+	assert.Equal(t, 25, report.Written)
+	assert.Equal(t, 3, report.Pages)
+	assert.True(t, report.BulkWrites)
+	require.NotNil(t, report.Verification)
+	assert.True(t, report.Verification.OK())
+	assert.Equal(t, 25, report.Verification.TargetCount)
+
+	original, err := source.GetComponent(ctx, "component-00", "1.1.0")
+	require.NoError(t, err)
+	copied, err := target.GetComponent(ctx, "component-00", "1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, original.CreatedAt, copied.CreatedAt)
+	assert.Equal(t, original.UpdatedAt, copied.UpdatedAt)
+
+	history, err := target.GetVersionHistory(ctx, "component-00")
+	require.NoError(t, err)
+	assert.Len(t, history, 3)
+}
+
+func TestMigrate_CopiesChangesAndTaxonomy(t *testing.T) {
+	ctx := context.Background()
+	source := seed(t, 6)
+	target := memory.NewComponentStore(logging.NewNoop())
+
+	taxonomy := &models.Taxonomy{
+		Providers:  []models.TaxonomyTerm{{Name: "aws", DisplayName: "Amazon Web Services"}},
+		Categories: []models.TaxonomyCategory{{TaxonomyTerm: models.TaxonomyTerm{Name: "database", DisplayName: "Databases"}}},
+	}
+	require.NoError(t, source.(storage.TaxonomyStore).PutTaxonomy(ctx, taxonomy))
+	promoted := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	changes := []models.ComponentChange{
+		{ID: "promote:component-01:1.0.0", ComponentName: "component-01", Version: "1.0.0", ChangeType: models.ChangeTypePromote, ChangedAt: promoted},
+		{ID: "promote:component-01:1.1.0", ComponentName: "component-01", Version: "1.1.0", ChangeType: models.ChangeTypePromote, ChangedAt: promoted.AddDate(0, 1, 0)},
+	}
+	require.NoError(t, source.(storage.BulkWriter).PutChanges(ctx, changes))
+
+	report, err := Migrate(ctx, source, target, Options{PageSize: 2})
+	require.NoError(t, err)
+	assert.True(t, report.Taxonomy)
+	assert.Equal(t, 2, report.Changes, "changes are copied once, whichever page their component spans")
+
+	copied, err := target.(storage.DraftStore).GetChanges(ctx, "component-01")
+	require.NoError(t, err)
+	assert.Equal(t, changes, copied)
+
+	copiedTaxonomy, err := target.(storage.TaxonomyStore).GetTaxonomy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, taxonomy.Providers, copiedTaxonomy.Providers)
+	require.Len(t, copiedTaxonomy.Categories, 1)
+	assert.Equal(t, "database", copiedTaxonomy.Categories[0].Name)
+
+	_, err = Migrate(ctx, source, target, Options{})
+	require.NoError(t, err, "migrating again replaces the taxonomy and the changes")
+	copied, err = target.(storage.DraftStore).GetChanges(ctx, "component-01")
+	require.NoError(t, err)
+	assert.Len(t, copied, 2)
+}
+
+func TestMigrate_DryRunDoesNotWrite(t *testing.T) {
+	ctx := context.Background()
+	source := seed(t, 6)
+	target := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, target.(storage.BulkWriter).PutComponents(ctx, []*models.Component{
+		newComponent("component-00", "1.0.0", time.Now()),
+	}))
+
+	report, err := Migrate(ctx, source, target, Options{DryRun: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Read)
+	assert.Equal(t, 0, report.Written)
+	assert.Equal(t, 1, report.Existing)
+
+	list, err := target.ListComponents(ctx, storage.ComponentFilters{}, storage.Pagination{Limit: 100})
+	require.NoError(t, err)
+	assert.Len(t, list.Components, 1)
+}
+
+func TestMigrate_ResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	source := seed(t, 9)
+	backing := memory.NewComponentStore(logging.NewNoop())
+	checkpoints := NewFileCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	_, err := Migrate(ctx, source, &failingStore{ComponentStore: backing, remaining: 4}, Options{
+		PageSize:    4,
+		Checkpoints: checkpoints,
+	})
+	require.Error(t, err)
+
+	checkpoint, err := checkpoints.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, 4, checkpoint.Written)
+
+	report, err := Migrate(ctx, source, backing, Options{
+		PageSize:    4,
+		Checkpoints: checkpoints,
+		Verify:      true,
+	})
+	require.NoError(t, err)
+	assert.True(t, report.Resumed)
+	assert.Equal(t, 9, report.Written)
+	assert.True(t, report.Verification.OK())
+
+	checkpoint, err = checkpoints.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint, "checkpoint should be cleared after a complete run")
+}
+
+func TestMigrate_FallsBackToStoreComponent(t *testing.T) {
+	ctx := context.Background()
+	source := seed(t, 3)
+	target := memory.NewComponentStore(logging.NewNoop())
+
+	_, err := Migrate(ctx, source, storeOnly{target}, Options{})
+	require.ErrorIs(t, err, storage.ErrValidation, "targets that would reset statuses and timestamps are rejected")
+	assert.Contains(t, err.Error(), "cannot write components verbatim")
+
+	report, err := Migrate(ctx, source, storeOnly{target}, Options{AllowRepublish: true})
+	require.NoError(t, err)
+	assert.False(t, report.BulkWrites)
+	assert.Equal(t, 3, report.Written)
+
+	list, err := target.ListComponents(ctx, storage.ComponentFilters{IncludeDrafts: true}, storage.Pagination{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, list.Components, 3)
+	assert.True(t, list.Components[0].IsDraft(), "republished versions are drafts")
+}
+
+func TestVerify_ReportsDifferences(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := memory.NewComponentStore(logging.NewNoop())
+	target := memory.NewComponentStore(logging.NewNoop())
+
+	changed := newComponent("vpc", "1.0.0", created)
+	changed.Description = "edited by hand"
+
+	require.NoError(t, source.(storage.BulkWriter).PutComponents(ctx, []*models.Component{
+		newComponent("vpc", "1.0.0", created),
+		newComponent("rds", "1.0.0", created),
+	}))
+	require.NoError(t, target.(storage.BulkWriter).PutComponents(ctx, []*models.Component{
+		changed,
+		newComponent("s3", "1.0.0", created),
+	}))
+
+	report, err := Verify(ctx, source, target, 0)
+	require.NoError(t, err)
+
+	assert.False(t, report.OK())
+	assert.Equal(t, []string{"rds:1.0.0"}, report.Missing)
+	assert.Equal(t, []string{"s3:1.0.0"}, report.Unexpected)
+	assert.Equal(t, []string{"vpc:1.0.0"}, report.Mismatched)
+}
+
+func TestDigest_IgnoresRepresentationDifferences(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	a := newComponent("vpc", "1.0.0", created)
+	b := newComponent("vpc", "1.0.0", created.In(time.FixedZone("CEST", 2*60*60)))
+	b.UpdatedAt = a.UpdatedAt
+	a.Deployment.Config = nil
+	b.Deployment.Config = map[string]any{}
+	sunset := created.AddDate(0, 6, 0)
+	sunsetCEST := sunset.In(time.FixedZone("CEST", 2*60*60))
+	a.Metadata.Deprecation = &models.Deprecation{SunsetAt: &sunset}
+	b.Metadata.Deprecation = &models.Deprecation{SunsetAt: &sunsetCEST}
+
+	digestA, err := Digest(a)
+	require.NoError(t, err)
+	digestB, err := Digest(b)
+	require.NoError(t, err)
+	assert.Equal(t, digestA, digestB)
+}
@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// VerifyReport compares two stores component by component.
+type VerifyReport struct {
+	SourceCount    int    `json:"source_count"`
+	TargetCount    int    `json:"target_count"`
+	SourceChecksum string `json:"source_checksum"`
+	TargetChecksum string `json:"target_checksum"`
+	// Missing lists component IDs present in the source but not the target.
+	Missing []string `json:"missing,omitempty"`
+	// Unexpected lists component IDs present in the target but not the source.
+	Unexpected []string `json:"unexpected,omitempty"`
+	// Mismatched lists component IDs whose content differs between stores.
+	Mismatched []string `json:"mismatched,omitempty"`
+}
+
+// OK reports whether both stores hold exactly the same components.
+func (r *VerifyReport) OK() bool {
+	return r.SourceChecksum == r.TargetChecksum &&
+		len(r.Missing) == 0 && len(r.Unexpected) == 0 && len(r.Mismatched) == 0
+}
+
+// Verify checksums every component version in both stores and reports any
+// difference. The per-store checksum covers all versions, so two stores with
+// equal checksums hold the same catalog.
+func Verify(ctx context.Context, source, target storage.ComponentStore, pageSize int32) (*VerifyReport, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	sourceDigests, err := digestStore(ctx, source, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum source: %w", err)
+	}
+	targetDigests, err := digestStore(ctx, target, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum target: %w", err)
+	}
+
+	report := &VerifyReport{
+		SourceCount:    len(sourceDigests),
+		TargetCount:    len(targetDigests),
+		SourceChecksum: aggregate(sourceDigests),
+		TargetChecksum: aggregate(targetDigests),
+	}
+
+	for id, digest := range sourceDigests {
+		targetDigest, ok := targetDigests[id]
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, id)
+		case targetDigest != digest:
+			report.Mismatched = append(report.Mismatched, id)
+		}
+	}
+	for id := range targetDigests {
+		if _, ok := sourceDigests[id]; !ok {
+			report.Unexpected = append(report.Unexpected, id)
+		}
+	}
+
+	slices.Sort(report.Missing)
+	slices.Sort(report.Unexpected)
+	slices.Sort(report.Mismatched)
+
+	return report, nil
+}
+
+func digestStore(ctx context.Context, store storage.ComponentStore, pageSize int32) (map[string]string, error) {
+	digests := make(map[string]string)
+	err := storage.ForEachPage(ctx, store, storage.ComponentFilters{IncludeDrafts: true}, pageSize, "", func(page *storage.ComponentList) error {
+		for _, component := range page.Components {
+			digest, err := Digest(component)
+			if err != nil {
+				return err
+			}
+			digests[component.GetID()] = digest
+		}
+		return nil
+	})
+	return digests, err
+}
+
+// aggregate hashes the sorted list of per-component digests.
+func aggregate(digests map[string]string) string {
+	ids := make([]string, 0, len(digests))
+	for id := range digests {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	h := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(h, "%s %s\n", id, digests[id])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Digest returns a SHA-256 over the canonical JSON form of the whole
+// component, metadata and timestamps included (see models.CanonicalDigest).
+// Timestamps are compared in UTC and empty values are dropped, so backends
+// that store nil and empty collections differently still agree.
+func Digest(component *models.Component) (string, error) {
+	normalized := *component
+	normalized.CreatedAt = component.CreatedAt.UTC()
+	normalized.UpdatedAt = component.UpdatedAt.UTC()
+	if component.Metadata.DeprecatedAt != nil {
+		deprecatedAt := component.Metadata.DeprecatedAt.UTC()
+		normalized.Metadata.DeprecatedAt = &deprecatedAt
+	}
+	if deprecation := component.Metadata.Deprecation; deprecation != nil && deprecation.SunsetAt != nil {
+		normalized.Metadata.Deprecation = deprecation.Clone()
+		*normalized.Metadata.Deprecation.SunsetAt = deprecation.SunsetAt.UTC()
+	}
+
+	digest, err := models.CanonicalDigest(&normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode component %s: %w", component.GetID(), err)
+	}
+	return digest, nil
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+// TestCase checks the outcome of the policies on a sample component. The
+// component is written like a published one and only needs the fields the
+// policies look at.
+type TestCase struct {
+	Name      string         `yaml:"name"`
+	Component map[string]any `yaml:"component"`
+	// Deny and Warn list the policies the component is expected to violate.
+	// Every other policy must pass.
+	Deny []string `yaml:"deny,omitempty"`
+	Warn []string `yaml:"warn,omitempty"`
+}
+
+// TestResult is the outcome of a test case.
+type TestResult struct {
+	Name       string
+	Violations Violations
+	// Failures explains how the outcome differs from the expectations, it is
+	// empty when the test passed.
+	Failures []string
+}
+
+// Passed reports whether the outcome matched the expectations.
+func (r TestResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Run evaluates every test case.
+func (e *Engine) Run(tests []TestCase) []TestResult {
+	results := make([]TestResult, len(tests))
+	for i, test := range tests {
+		results[i] = e.run(test)
+	}
+	return results
+}
+
+func (e *Engine) run(test TestCase) TestResult {
+	result := TestResult{Name: test.Name}
+
+	component, err := decodeComponent(test.Component)
+	if err != nil {
+		result.Failures = []string{err.Error()}
+		return result
+	}
+	result.Violations = e.Evaluate(component)
+
+	expected := make(map[string]Enforcement, len(test.Deny)+len(test.Warn))
+	for _, name := range test.Deny {
+		expected[name] = EnforcementDeny
+	}
+	for _, name := range test.Warn {
+		expected[name] = EnforcementWarn
+	}
+
+	violated := make(map[string]Enforcement)
+	for _, violation := range result.Violations {
+		violated[violation.Policy] = violation.Enforcement
+	}
+
+	for _, policy := range e.policies {
+		want, wantViolation := expected[policy.Name]
+		have, violatedPolicy := violated[policy.Name]
+		switch {
+		case wantViolation && !violatedPolicy:
+			result.Failures = append(result.Failures, fmt.Sprintf("expected policy '%s' to %s, but it passed", policy.Name, want))
+		case !wantViolation && violatedPolicy:
+			result.Failures = append(result.Failures, fmt.Sprintf("expected policy '%s' to pass, but it %s: %s", policy.Name, have, reasons(result.Violations, policy.Name)))
+		case wantViolation && want != have:
+			result.Failures = append(result.Failures, fmt.Sprintf("expected policy '%s' to %s, but it is enforced as %s", policy.Name, want, have))
+		}
+		delete(expected, policy.Name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(expected)) {
+		result.Failures = append(result.Failures, fmt.Sprintf("expected unknown policy '%s'", name))
+	}
+
+	return result
+}
+
+func decodeComponent(fields map[string]any) (*models.Component, error) {
+	data, err := json.ToJSON(fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid component: %w", err)
+	}
+	var component models.Component
+	if err := json.FromJSON(data, &component); err != nil {
+		return nil, fmt.Errorf("invalid component: %w", err)
+	}
+	return &component, nil
+}
+
+func reasons(violations Violations, policy string) string {
+	var messages []string
+	for _, violation := range violations {
+		if violation.Policy == policy {
+			messages = append(messages, violation.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,473 @@
+// Package policy evaluates declarative governance policies against
+// components before they are published.
+//
+// Policies are loaded from YAML files:
+//
+//	policies:
+//	  - name: aws-database-encryption
+//	    description: AWS databases are encrypted unless opted out
+//	    enforcement: deny
+//	    match:
+//	      providers: [aws]
+//	      categories: [database]
+//	    rules:
+//	      - input: encrypted
+//	        type: bool
+//	        default: true
+//	  - name: networking-maintainers
+//	    enforcement: warn
+//	    match:
+//	      categories: [networking]
+//	    rules:
+//	      - field: maintainers
+//	        min_count: 2
+//
+// A policy applies to the components selected by match, every component when
+// match is empty, and is violated when any of its rules fails. Violations of
+// deny policies reject the component; violations of warn policies are only
+// reported.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+// Enforcement decides what happens when a policy is violated.
+type Enforcement string
+
+const (
+	// EnforcementDeny rejects the component.
+	EnforcementDeny Enforcement = "deny"
+	// EnforcementWarn accepts the component and reports the violation.
+	EnforcementWarn Enforcement = "warn"
+)
+
+// File is the content of a policy file.
+type File struct {
+	Policies []Policy   `yaml:"policies"`
+	Tests    []TestCase `yaml:"tests,omitempty"`
+}
+
+// Policy is a named set of rules applied to the components it matches.
+type Policy struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description,omitempty"`
+	Enforcement Enforcement `yaml:"enforcement"`
+	Match       Match       `yaml:"match,omitempty"`
+	Rules       []Rule      `yaml:"rules"`
+}
+
+// Match selects components by provider, category, subcategory, deployment
+// engine and labels. Each non-empty criterion must hold; lists match when
+// any entry does.
+type Match struct {
+	Providers     []string          `yaml:"providers,omitempty"`
+	Categories    []string          `yaml:"categories,omitempty"`
+	SubCategories []string          `yaml:"sub_categories,omitempty"`
+	Engines       []string          `yaml:"engines,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty"`
+}
+
+// Rule is a single assertion on a component. It either checks the values of
+// Field or the declaration of Input.
+type Rule struct {
+	// Field is one of name, provider, category, sub_category, description,
+	// maintainers, provides, conflicts_with, dependencies, engines,
+	// deployment.engine, labels.<key> or annotations.<key>.
+	Field    string   `yaml:"field,omitempty"`
+	Required bool     `yaml:"required,omitempty"`
+	In       []string `yaml:"in,omitempty"`
+	NotIn    []string `yaml:"not_in,omitempty"`
+	Pattern  string   `yaml:"pattern,omitempty"`
+	MinCount *int     `yaml:"min_count,omitempty"`
+	MaxCount *int     `yaml:"max_count,omitempty"`
+
+	// Input requires the component to declare an input of that name, with
+	// the given Type and Default when they are set.
+	Input   string `yaml:"input,omitempty"`
+	Type    string `yaml:"type,omitempty"`
+	Default any    `yaml:"default,omitempty"`
+
+	// Message replaces the generated reason of a failure.
+	Message string `yaml:"message,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Violation is a failed rule of a policy.
+type Violation struct {
+	Policy      string      `json:"policy"`
+	Enforcement Enforcement `json:"enforcement"`
+	// Path locates the offending field in the JSON form of the component.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Violations is the outcome of evaluating a component.
+type Violations []Violation
+
+// Denied reports whether any violation comes from a deny policy.
+func (v Violations) Denied() bool {
+	return slices.ContainsFunc(v, func(violation Violation) bool {
+		return violation.Enforcement == EnforcementDeny
+	})
+}
+
+// Issues converts the violations to validation issues: deny violations are
+// errors and warn violations are warnings.
+func (v Violations) Issues() models.ValidationErrors {
+	issues := make(models.ValidationErrors, 0, len(v))
+	for _, violation := range v {
+		severity := models.SeverityError
+		if violation.Enforcement == EnforcementWarn {
+			severity = models.SeverityWarning
+		}
+		issues = append(issues, models.ValidationIssue{
+			Path:     violation.Path,
+			Rule:     models.RulePolicy,
+			Message:  fmt.Sprintf("policy '%s': %s", violation.Policy, violation.Message),
+			Severity: severity,
+		})
+	}
+	return issues
+}
+
+// Engine evaluates a fixed set of policies.
+type Engine struct {
+	policies []Policy
+}
+
+// New checks policies and returns an engine evaluating them in order.
+func New(policies []Policy) (*Engine, error) {
+	names := make(map[string]bool, len(policies))
+	compiled := make([]Policy, len(policies))
+	for i, policy := range policies {
+		if err := policy.compile(); err != nil {
+			return nil, fmt.Errorf("policy at index %d: %w", i, err)
+		}
+		if names[policy.Name] {
+			return nil, fmt.Errorf("policy '%s' is declared more than once", policy.Name)
+		}
+		names[policy.Name] = true
+		compiled[i] = policy
+	}
+	return &Engine{policies: compiled}, nil
+}
+
+// Load reads policy files and directories of .yaml and .yml files, and
+// returns an engine for all the policies they declare along with their test
+// cases.
+func Load(paths ...string) (*Engine, []TestCase, error) {
+	var policies []Policy
+	var tests []TestCase
+	for _, path := range paths {
+		files, err := policyFiles(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range files {
+			content, err := readFile(file)
+			if err != nil {
+				return nil, nil, err
+			}
+			policies = append(policies, content.Policies...)
+			tests = append(tests, content.Tests...)
+		}
+	}
+
+	engine, err := New(policies)
+	if err != nil {
+		return nil, nil, err
+	}
+	return engine, tests, nil
+}
+
+// Policies returns the policies of the engine.
+func (e *Engine) Policies() []Policy {
+	return slices.Clone(e.policies)
+}
+
+// Evaluate returns every violation of the policies matching component.
+func (e *Engine) Evaluate(component *models.Component) Violations {
+	var violations Violations
+	for _, policy := range e.policies {
+		if !policy.Match.matches(component) {
+			continue
+		}
+		for _, rule := range policy.Rules {
+			for _, failure := range rule.check(component) {
+				if rule.Message != "" {
+					failure.message = rule.Message
+				}
+				violations = append(violations, Violation{
+					Policy:      policy.Name,
+					Enforcement: policy.Enforcement,
+					Path:        failure.path,
+					Message:     failure.message,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+func policyFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(file); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies: %w", err)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+func readFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies: %w", err)
+	}
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+func (p *Policy) compile() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	switch p.Enforcement {
+	case EnforcementDeny, EnforcementWarn:
+	case "":
+		p.Enforcement = EnforcementDeny
+	default:
+		return fmt.Errorf("policy '%s' has unknown enforcement '%s'", p.Name, p.Enforcement)
+	}
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("policy '%s' has no rules", p.Name)
+	}
+
+	rules := make([]Rule, len(p.Rules))
+	for i, rule := range p.Rules {
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("policy '%s' rule at index %d: %w", p.Name, i, err)
+		}
+		rules[i] = rule
+	}
+	p.Rules = rules
+	return nil
+}
+
+func (r *Rule) compile() error {
+	switch {
+	case r.Field == "" && r.Input == "":
+		return errors.New("either field or input is required")
+	case r.Field != "" && r.Input != "":
+		return errors.New("field and input are mutually exclusive")
+	case r.Input != "":
+		if r.Type != "" {
+			if _, err := models.ParseType(r.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !knownField(r.Field) {
+		return fmt.Errorf("unknown field '%s'", r.Field)
+	}
+	if r.Type != "" || r.Default != nil {
+		return errors.New("type and default only apply to input rules")
+	}
+	if r.Pattern != "" {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		r.pattern = pattern
+	}
+	return nil
+}
+
+func (m Match) matches(component *models.Component) bool {
+	if len(m.Providers) > 0 && !slices.Contains(m.Providers, component.Provider) {
+		return false
+	}
+	if len(m.Categories) > 0 && !slices.Contains(m.Categories, component.Category) {
+		return false
+	}
+	if len(m.SubCategories) > 0 && !slices.Contains(m.SubCategories, component.SubCategory) {
+		return false
+	}
+	if len(m.Engines) > 0 && !slices.ContainsFunc(m.Engines, component.SupportsEngine) {
+		return false
+	}
+	for key, value := range m.Labels {
+		if !component.HasLabel(key, value) {
+			return false
+		}
+	}
+	return true
+}
+
+type failure struct {
+	path    string
+	message string
+}
+
+func (r *Rule) check(component *models.Component) []failure {
+	if r.Input != "" {
+		return r.checkInput(component)
+	}
+
+	values, path := fieldValues(component, r.Field)
+	fail := func(format string, args ...any) []failure {
+		return []failure{{path: path, message: fmt.Sprintf(format, args...)}}
+	}
+
+	if r.Required && len(values) == 0 {
+		return fail("%s is required", r.Field)
+	}
+	if r.MinCount != nil && len(values) < *r.MinCount {
+		return fail("%s must have at least %d entries, has %d", r.Field, *r.MinCount, len(values))
+	}
+	if r.MaxCount != nil && len(values) > *r.MaxCount {
+		return fail("%s must have at most %d entries, has %d", r.Field, *r.MaxCount, len(values))
+	}
+
+	var failures []failure
+	for _, value := range values {
+		switch {
+		case len(r.In) > 0 && !slices.Contains(r.In, value):
+			failures = append(failures, fail("%s '%s' is not one of: %s", r.Field, value, strings.Join(r.In, ", "))...)
+		case slices.Contains(r.NotIn, value):
+			failures = append(failures, fail("%s '%s' is not allowed", r.Field, value)...)
+		case r.pattern != nil && !r.pattern.MatchString(value):
+			failures = append(failures, fail("%s '%s' must match pattern '%s'", r.Field, value, r.Pattern)...)
+		}
+	}
+	return failures
+}
+
+func (r *Rule) checkInput(component *models.Component) []failure {
+	i := slices.IndexFunc(component.Inputs, func(input models.InputSpec) bool {
+		return input.Name == r.Input
+	})
+	if i < 0 {
+		return []failure{{path: "inputs", message: fmt.Sprintf("input '%s' must be declared", r.Input)}}
+	}
+	input := component.Inputs[i]
+	path := fmt.Sprintf("inputs[%d]", i)
+
+	var failures []failure
+	if r.Type != "" {
+		want := models.MustParseType(r.Type)
+		if have, err := input.ParsedType(); err != nil || have.String() != want.String() {
+			failures = append(failures, failure{
+				path:    path + ".type",
+				message: fmt.Sprintf("input '%s' must be of type %s", r.Input, want),
+			})
+		}
+	}
+	if r.Default != nil && !sameValue(input.Default, r.Default) {
+		failures = append(failures, failure{
+			path:    path + ".default",
+			message: fmt.Sprintf("input '%s' must default to %v", r.Input, r.Default),
+		})
+	}
+	return failures
+}
+
+// sameValue compares values through their JSON encoding, so that numbers
+// decoded from YAML and JSON compare equal
+func sameValue(a, b any) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ja, errA := json.ToJSON(a)
+	jb, errB := json.ToJSON(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+var scalarFields = []string{"name", "provider", "category", "sub_category", "description", "deployment.engine"}
+
+var listFields = []string{"maintainers", "provides", "conflicts_with", "dependencies", "engines"}
+
+func knownField(field string) bool {
+	if slices.Contains(scalarFields, field) || slices.Contains(listFields, field) {
+		return true
+	}
+	prefix, key, ok := strings.Cut(field, ".")
+	return ok && key != "" && (prefix == "labels" || prefix == "annotations")
+}
+
+// fieldValues returns the non-empty values of field and its path in the
+// JSON form of component
+func fieldValues(component *models.Component, field string) ([]string, string) {
+	var values []string
+	switch field {
+	case "name":
+		values = []string{component.Name}
+	case "provider":
+		values = []string{component.Provider}
+	case "category":
+		values = []string{component.Category}
+	case "sub_category":
+		values = []string{component.SubCategory}
+	case "description":
+		values = []string{component.Description}
+	case "deployment.engine":
+		values = []string{component.Deployment.Engine}
+	case "maintainers":
+		values = component.Maintainers
+	case "provides":
+		values = component.Provides
+	case "conflicts_with":
+		values = component.ConflictsWith
+	case "engines":
+		values = component.Engines()
+	case "dependencies":
+		for _, dep := range component.Dependencies {
+			values = append(values, dep.Name)
+		}
+	default:
+		prefix, key, _ := strings.Cut(field, ".")
+		entries := component.Labels
+		if prefix == "annotations" {
+			entries = component.Annotations
+		}
+		values = []string{entries[key]}
+		field = fmt.Sprintf("%s[%q]", prefix, key)
+	}
+
+	return slices.DeleteFunc(slices.Clone(values), func(value string) bool { return value == "" }), field
+}
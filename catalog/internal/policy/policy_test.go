@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+func loadEngine(t *testing.T) (*Engine, []TestCase) {
+	t.Helper()
+	engine, tests, err := Load("testdata")
+	require.NoError(t, err)
+	return engine, tests
+}
+
+func newComponent() *models.Component {
+	return &models.Component{
+		Name:     "postgres",
+		Version:  "1.0.0",
+		Provider: "aws",
+		Category: "database",
+		Inputs: []models.InputSpec{
+			{Name: "db_name", Type: "string", Description: "Database name"},
+			{Name: "encrypted", Type: "bool", Description: "Encrypt storage", Default: true},
+		},
+		Outputs: []models.OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Database endpoint"},
+		},
+		Deployment: models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+	}
+}
+
+func TestEngine_TestCases(t *testing.T) {
+	engine, tests := loadEngine(t)
+	require.Len(t, engine.Policies(), 3)
+	require.Len(t, tests, 4)
+
+	for _, result := range engine.Run(tests) {
+		assert.True(t, result.Passed(), "%s: %v", result.Name, result.Failures)
+	}
+}
+
+func TestEngine_RunReportsMismatches(t *testing.T) {
+	engine, _ := loadEngine(t)
+
+	results := engine.Run([]TestCase{{
+		Name:      "wrong expectations",
+		Component: map[string]any{"name": "postgres", "provider": "amazon", "category": "database"},
+		Warn:      []string{"approved-providers", "missing-policy"},
+	}})
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed())
+	assert.Equal(t, []string{
+		"expected policy 'approved-providers' to warn, but it is enforced as deny",
+		"expected unknown policy 'missing-policy'",
+	}, results[0].Failures)
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	engine, _ := loadEngine(t)
+
+	component := newComponent()
+	assert.Empty(t, engine.Evaluate(component))
+
+	component.Inputs[1].Type = "string"
+	component.Inputs[1].Default = "yes"
+	violations := engine.Evaluate(component)
+	assert.True(t, violations.Denied())
+	assert.Equal(t, Violations{
+		{Policy: "aws-database-encryption", Enforcement: EnforcementDeny, Path: "inputs[1].type", Message: "input 'encrypted' must be of type bool"},
+		{Policy: "aws-database-encryption", Enforcement: EnforcementDeny, Path: "inputs[1].default", Message: "input 'encrypted' must default to true"},
+	}, violations)
+
+	component.Inputs = component.Inputs[:1]
+	violations = engine.Evaluate(component)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "inputs", violations[0].Path)
+
+	issues := violations.Issues()
+	assert.Equal(t, models.ValidationIssue{
+		Path:     "inputs",
+		Rule:     models.RulePolicy,
+		Message:  "policy 'aws-database-encryption': input 'encrypted' must be declared",
+		Severity: models.SeverityError,
+	}, issues[0])
+}
+
+func TestEngine_FieldRules(t *testing.T) {
+	one := 1
+	engine, err := New([]Policy{{
+		Name: "conventions",
+		Rules: []Rule{
+			{Field: "labels.team", Required: true},
+			{Field: "name", Pattern: "^[a-z]+$"},
+			{Field: "engines", NotIn: []string{"pulumi"}},
+			{Field: "dependencies", MaxCount: &one},
+		},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, EnforcementDeny, engine.Policies()[0].Enforcement)
+
+	component := newComponent()
+	component.Name = "postgres-16"
+	component.AdditionalDeployments = []models.DeploymentSpec{{Engine: "pulumi", Version: "3.0.0"}}
+	component.Dependencies = []models.Dependency{{Name: "vpc"}, {Name: "kms-key"}}
+
+	var messages []string
+	for _, violation := range engine.Evaluate(component) {
+		messages = append(messages, violation.Path+": "+violation.Message)
+	}
+	assert.Equal(t, []string{
+		`labels["team"]: labels.team is required`,
+		"name: name 'postgres-16' must match pattern '^[a-z]+$'",
+		"engines: engines 'pulumi' is not allowed",
+		"dependencies: dependencies must have at most 1 entries, has 2",
+	}, messages)
+}
+
+func TestNew_RejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []Policy
+		want     string
+	}{
+		{"missing name", []Policy{{Rules: []Rule{{Field: "name"}}}}, "name is required"},
+		{"unknown enforcement", []Policy{{Name: "p", Enforcement: "block", Rules: []Rule{{Field: "name"}}}}, "unknown enforcement 'block'"},
+		{"no rules", []Policy{{Name: "p"}}, "policy 'p' has no rules"},
+		{"empty rule", []Policy{{Name: "p", Rules: []Rule{{}}}}, "either field or input is required"},
+		{"unknown field", []Policy{{Name: "p", Rules: []Rule{{Field: "owner"}}}}, "unknown field 'owner'"},
+		{"invalid pattern", []Policy{{Name: "p", Rules: []Rule{{Field: "name", Pattern: "("}}}}, "invalid pattern"},
+		{"invalid type", []Policy{{Name: "p", Rules: []Rule{{Input: "x", Type: "text"}}}}, "invalid type 'text'"},
+		{"duplicate", []Policy{{Name: "p", Rules: []Rule{{Field: "name"}}}, {Name: "p", Rules: []Rule{{Field: "name"}}}}, "policy 'p' is declared more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.policies)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,313 @@
+// Package snapshot exports a whole catalog to a portable, compressed bundle and
+// imports it back into any ComponentStore.
+//
+// A bundle is a gzip-compressed tar archive holding two files:
+//
+//	manifest.json      schema version, record counts and file checksums
+//	components.ndjson  one component version per line, ordered by name and version
+//
+// The manifest comes first so readers can reject an incompatible bundle
+// before decoding any record.
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// SchemaVersion is the bundle layout written by Export. Import accepts
+// bundles up to and including this version.
+const SchemaVersion = 1
+
+const (
+	manifestFile   = "manifest.json"
+	componentsFile = "components.ndjson"
+	exportPageSize = 100
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	SchemaVersion int                 `json:"schema_version"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Components    int                 `json:"components"`
+	Versions      int                 `json:"versions"`
+	Files         map[string]FileInfo `json:"files"`
+}
+
+// FileInfo records the size, record count and checksum of a bundle file.
+type FileInfo struct {
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	Records int    `json:"records"`
+}
+
+// ConflictPolicy decides what Import does with versions already in the store.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps whatever is already stored.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwriteDrafts replaces stored drafts and keeps every other
+	// stored version, since published versions are immutable.
+	ConflictOverwriteDrafts ConflictPolicy = "overwrite-drafts"
+	// ConflictFail aborts the import, before anything is written, if any
+	// version in the bundle already exists.
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// ImportReport summarises an import.
+type ImportReport struct {
+	Manifest    Manifest `json:"manifest"`
+	Imported    int      `json:"imported"`
+	Overwritten int      `json:"overwritten"`
+	Skipped     int      `json:"skipped"`
+	// BulkWrites is true when the store accepted verbatim writes, which
+	// keeps the original timestamps.
+	BulkWrites bool `json:"bulk_writes"`
+}
+
+// Export writes every component version in store to w as a bundle.
+func Export(ctx context.Context, store storage.ComponentStore, w io.Writer) (*Manifest, error) {
+	var components []*models.Component
+	err := storage.ForEachPage(ctx, store, storage.ComponentFilters{IncludeDrafts: true}, exportPageSize, "", func(page *storage.ComponentList) error {
+		components = append(components, page.Components...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(components, compareComponents)
+
+	var records bytes.Buffer
+	encoder := json.NewEncoder(&records)
+	encoder.SetEscapeHTML(false)
+	names := make(map[string]struct{})
+	for _, component := range components {
+		if err := encoder.Encode(component); err != nil {
+			return nil, fmt.Errorf("failed to encode component %s: %w", component.GetID(), err)
+		}
+		names[component.Name] = struct{}{}
+	}
+
+	sum := sha256.Sum256(records.Bytes())
+	manifest := &Manifest{
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     time.Now().UTC(),
+		Components:    len(names),
+		Versions:      len(components),
+		Files: map[string]FileInfo{
+			componentsFile: {
+				SHA256:  hex.EncodeToString(sum[:]),
+				Size:    int64(records.Len()),
+				Records: len(components),
+			},
+		},
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeFile(tw, manifestFile, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := writeFile(tw, componentsFile, records.Bytes(), manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish compression: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Import reads a bundle from r and writes its component versions to store,
+// resolving versions that already exist according to policy. The whole
+// bundle is checked against its manifest before anything is written.
+func Import(ctx context.Context, store storage.ComponentStore, r io.Reader, policy ConflictPolicy) (*ImportReport, error) {
+	switch policy {
+	case ConflictSkip, ConflictOverwriteDrafts, ConflictFail:
+	default:
+		return nil, storage.NewValidationError("policy", fmt.Sprintf("unknown conflict policy %q", policy))
+	}
+
+	manifest, components, err := readBundle(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Manifest: *manifest}
+
+	statuses := make(map[string]map[string]models.VersionStatus)
+	var pending []*models.Component
+	for _, component := range components {
+		versions, ok := statuses[component.Name]
+		if !ok {
+			versions, err = versionStatuses(ctx, store, component.Name)
+			if err != nil {
+				return nil, err
+			}
+			statuses[component.Name] = versions
+		}
+
+		status, exists := versions[component.Version]
+		switch {
+		case !exists:
+			report.Imported++
+		case policy == ConflictFail:
+			return nil, storage.NewComponentExistsError(component.Name, component.Version).
+				WithDetail("operation", "Import")
+		case policy == ConflictOverwriteDrafts && status == models.VersionStatusDraft:
+			report.Overwritten++
+		default:
+			report.Skipped++
+			continue
+		}
+		pending = append(pending, component)
+	}
+
+	bulk, ok := store.(storage.BulkWriter)
+	report.BulkWrites = ok
+	if ok {
+		if err := bulk.PutComponents(ctx, pending); err != nil {
+			return nil, fmt.Errorf("failed to import components: %w", err)
+		}
+		return report, nil
+	}
+
+	for _, component := range pending {
+		if _, err := store.StoreComponent(ctx, component); err != nil {
+			return nil, fmt.Errorf("failed to import component %s: %w", component.GetID(), err)
+		}
+	}
+	return report, nil
+}
+
+func versionStatuses(ctx context.Context, store storage.ComponentStore, name string) (map[string]models.VersionStatus, error) {
+	history, err := store.GetVersionHistory(ctx, name)
+	if err != nil && !errors.Is(err, storage.ErrResourceNotFound) {
+		return nil, fmt.Errorf("failed to read version history of %s: %w", name, err)
+	}
+
+	statuses := make(map[string]models.VersionStatus, len(history))
+	for _, version := range history {
+		statuses[version.Version] = version.Status
+	}
+	return statuses, nil
+}
+
+// readBundle decodes and verifies a bundle.
+func readBundle(r io.Reader) (*Manifest, []*models.Component, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, invalidBundle("not a gzip stream", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var manifest *Manifest
+	var records []byte
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, invalidBundle("corrupt archive", err)
+		}
+
+		switch header.Name {
+		case manifestFile:
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, nil, invalidBundle("corrupt manifest", err)
+			}
+			if manifest.SchemaVersion < 1 || manifest.SchemaVersion > SchemaVersion {
+				return nil, nil, invalidBundle(fmt.Sprintf("unsupported schema version %d", manifest.SchemaVersion), nil)
+			}
+		case componentsFile:
+			if manifest == nil {
+				return nil, nil, invalidBundle("manifest must precede records", nil)
+			}
+			if records, err = io.ReadAll(tr); err != nil {
+				return nil, nil, invalidBundle("corrupt records", err)
+			}
+		}
+	}
+
+	if manifest == nil {
+		return nil, nil, invalidBundle("missing manifest", nil)
+	}
+
+	info, ok := manifest.Files[componentsFile]
+	if !ok {
+		return nil, nil, invalidBundle("manifest does not describe "+componentsFile, nil)
+	}
+	sum := sha256.Sum256(records)
+	if hex.EncodeToString(sum[:]) != info.SHA256 {
+		return nil, nil, invalidBundle("checksum mismatch for "+componentsFile, nil)
+	}
+
+	var components []*models.Component
+	decoder := json.NewDecoder(bytes.NewReader(records))
+	for decoder.More() {
+		var component models.Component
+		if err := decoder.Decode(&component); err != nil {
+			return nil, nil, invalidBundle(fmt.Sprintf("corrupt record %d", len(components)+1), err)
+		}
+		components = append(components, &component)
+	}
+
+	if len(components) != info.Records || len(components) != manifest.Versions {
+		return nil, nil, invalidBundle(fmt.Sprintf("manifest lists %d versions but bundle holds %d", manifest.Versions, len(components)), nil)
+	}
+
+	return manifest, components, nil
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		return fmt.Errorf("failed to write %s header: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func invalidBundle(reason string, cause error) error {
+	return storage.NewValidationError("bundle", reason).WithCause(cause)
+}
+
+func compareComponents(a, b *models.Component) int {
+	return cmp.Or(cmp.Compare(a.Name, b.Name), models.CompareVersions(a.Version, b.Version))
+}
@@ -0,0 +1,258 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+func newComponent(name, version string) *models.Component {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	return &models.Component{
+		Name:        name,
+		Version:     version,
+		Provider:    "aws",
+		Category:    "networking",
+		Description: "<b>escaped?</b>",
+		Inputs: []models.InputSpec{
+			{Name: "cidr", Type: "string", Description: "VPC CIDR block"},
+		},
+		Outputs: []models.OutputSpec{
+			{Name: "vpc_id", Type: "string", Description: "VPC identifier"},
+		},
+		Deployment: models.DeploymentSpec{
+			Engine:  "terraform",
+			Version: "1.5.0",
+			Config:  map[string]any{"source": "git::https://example.com/vpc", "module_version": "1.0.0"},
+		},
+		CreatedAt: created,
+		UpdatedAt: created.Add(24 * time.Hour),
+	}
+}
+
+func newStore(t *testing.T, components ...*models.Component) storage.ComponentStore {
+	t.Helper()
+	store := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), components))
+	return store
+}
+
+// draftStore reports every stored version as a draft.
+type draftStore struct {
+	storage.ComponentStore
+}
+
+func (s draftStore) GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error) {
+	history, err := s.ComponentStore.GetVersionHistory(ctx, name)
+	for i := range history {
+		history[i].Status = models.VersionStatusDraft
+	}
+	return history, err
+}
+
+func (s draftStore) PutComponents(ctx context.Context, components []*models.Component) error {
+	return s.ComponentStore.(storage.BulkWriter).PutComponents(ctx, components)
+}
+
+func (s draftStore) PutChanges(ctx context.Context, changes []models.ComponentChange) error {
+	return s.ComponentStore.(storage.BulkWriter).PutChanges(ctx, changes)
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := newStore(t,
+		newComponent("vpc", "1.0.0"),
+		newComponent("vpc", "1.1.0"),
+		newComponent("rds", "2.0.0"),
+	)
+
+	var bundle bytes.Buffer
+	manifest, err := Export(ctx, source, &bundle)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, manifest.SchemaVersion)
+	assert.Equal(t, 2, manifest.Components)
+	assert.Equal(t, 3, manifest.Versions)
+
+	target := memory.NewComponentStore(logging.NewNoop())
+	report, err := Import(ctx, target, &bundle, ConflictFail)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Imported)
+	assert.True(t, report.BulkWrites)
+
+	original, err := source.GetComponent(ctx, "vpc", "1.1.0")
+	require.NoError(t, err)
+	restored, err := target.GetComponent(ctx, "vpc", "1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, original, restored)
+}
+
+func TestExport_IsOrderedAndUnescaped(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t,
+		newComponent("vpc", "1.10.0"),
+		newComponent("vpc", "1.2.0"),
+		newComponent("alb", "1.0.0"),
+	)
+
+	var bundle bytes.Buffer
+	_, err := Export(ctx, store, &bundle)
+	require.NoError(t, err)
+
+	files := readArchive(t, bundle.Bytes())
+	require.Contains(t, files, manifestFile)
+	records := string(files[componentsFile])
+
+	assert.Contains(t, records, "<b>escaped?</b>")
+	alb := bytes.Index(files[componentsFile], []byte(`"alb:1.0.0"`))
+	v12 := bytes.Index(files[componentsFile], []byte(`"vpc:1.2.0"`))
+	v110 := bytes.Index(files[componentsFile], []byte(`"vpc:1.10.0"`))
+	assert.True(t, alb < v12 && v12 < v110, "records should be ordered by name then semantic version")
+}
+
+func TestImport_ConflictPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	var bundle bytes.Buffer
+	_, err := Export(ctx, newStore(t, newComponent("vpc", "1.0.0"), newComponent("vpc", "1.1.0")), &bundle)
+	require.NoError(t, err)
+
+	existing := newComponent("vpc", "1.0.0")
+	existing.Description = "already here"
+
+	t.Run("skip", func(t *testing.T) {
+		target := newStore(t, existing)
+		report, err := Import(ctx, target, bytes.NewReader(bundle.Bytes()), ConflictSkip)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Imported)
+		assert.Equal(t, 1, report.Skipped)
+
+		kept, err := target.GetComponent(ctx, "vpc", "1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, "already here", kept.Description)
+	})
+
+	t.Run("fail writes nothing", func(t *testing.T) {
+		target := newStore(t, existing)
+		_, err := Import(ctx, target, bytes.NewReader(bundle.Bytes()), ConflictFail)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, storage.ErrComponentExists)
+
+		_, err = target.GetComponent(ctx, "vpc", "1.1.0")
+		assert.ErrorIs(t, err, storage.ErrComponentNotFound)
+	})
+
+	t.Run("overwrite drafts", func(t *testing.T) {
+		target := draftStore{newStore(t, existing)}
+		report, err := Import(ctx, target, bytes.NewReader(bundle.Bytes()), ConflictOverwriteDrafts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Overwritten)
+
+		replaced, err := target.GetComponent(ctx, "vpc", "1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, "<b>escaped?</b>", replaced.Description)
+	})
+
+	t.Run("overwrite drafts keeps published versions", func(t *testing.T) {
+		target := newStore(t, existing)
+		report, err := Import(ctx, target, bytes.NewReader(bundle.Bytes()), ConflictOverwriteDrafts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Equal(t, 0, report.Overwritten)
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		_, err := Import(ctx, newStore(t), bytes.NewReader(bundle.Bytes()), "merge")
+		assert.ErrorIs(t, err, storage.ErrValidation)
+	})
+}
+
+func TestImport_RejectsTamperedBundles(t *testing.T) {
+	ctx := context.Background()
+
+	var bundle bytes.Buffer
+	_, err := Export(ctx, newStore(t, newComponent("vpc", "1.0.0")), &bundle)
+	require.NoError(t, err)
+	files := readArchive(t, bundle.Bytes())
+
+	tests := []struct {
+		name  string
+		files [][2]string
+		want  string
+	}{
+		{
+			name:  "modified records",
+			files: [][2]string{{manifestFile, string(files[manifestFile])}, {componentsFile, string(bytes.Replace(files[componentsFile], []byte("vpc"), []byte("vpn"), 1))}},
+			want:  "checksum mismatch",
+		},
+		{
+			name:  "missing manifest",
+			files: [][2]string{{componentsFile, string(files[componentsFile])}},
+			want:  "manifest must precede records",
+		},
+		{
+			name:  "future schema",
+			files: [][2]string{{manifestFile, `{"schema_version": 99}`}},
+			want:  "unsupported schema version 99",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := memory.NewComponentStore(logging.NewNoop())
+			_, err := Import(ctx, target, bytes.NewReader(writeArchive(t, tt.files)), ConflictFail)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, storage.ErrValidation)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	_, err = Import(ctx, memory.NewComponentStore(logging.NewNoop()), bytes.NewReader([]byte("not a bundle")), ConflictFail)
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
+
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = content
+	}
+}
+
+func writeArchive(t *testing.T, files [][2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0o644, Size: int64(len(file[1]))}))
+		_, err := fmt.Fprint(tw, file[1])
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HatiCode/nestor/catalog/internal/policy"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/catalog/pkg/signing"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// PublishCheck inspects a component before it is published. Stores run their
+// checks when a version is stored as a draft and again when it is promoted,
+// once the component was validated against the taxonomy, so a version only
+// becomes active if it still passes them. A check rejects the component by
+// returning an error, usually a ValidationError, and may record details on
+// it, such as the publishers of its signatures. Issues that do not block the
+// publication are returned as warnings.
+type PublishCheck func(ctx context.Context, component *models.Component) (models.ValidationErrors, error)
+
+// RunChecks runs checks in order and returns the warnings they raised, or the
+// error of the first one rejecting component.
+func RunChecks(ctx context.Context, checks []PublishCheck, component *models.Component) (models.ValidationErrors, error) {
+	var warnings models.ValidationErrors
+	for _, check := range checks {
+		issues, err := check(ctx, component)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, issues...)
+	}
+	return warnings, nil
+}
+
+// PolicyCheck evaluates the policies of engine. Components violating a deny
+// policy are rejected with a validation error listing the violations; warn
+// violations are logged and returned as warnings.
+func PolicyCheck(engine *policy.Engine, logger logging.Logger) PublishCheck {
+	logger = logger.With("component", "policy")
+	return func(ctx context.Context, component *models.Component) (models.ValidationErrors, error) {
+		violations := engine.Evaluate(component)
+		if violations.Denied() {
+			return nil, NewComponentValidationError(violations.Issues())
+		}
+		for _, violation := range violations {
+			logger.WarnContext(ctx, "component violates policy",
+				"name", component.Name, "version", component.Version,
+				"policy", violation.Policy, "path", violation.Path, "reason", violation.Message)
+		}
+		return violations.Issues().Warnings(), nil
+	}
+}
+
+// SignatureCheck verifies the signatures of components against keys.
+// Components with a signature that is invalid or made by an untrusted key
+// are rejected with a validation error, as are components whose checksum
+// does not match their definition and unsigned components when required is
+// set. The publisher of each signature is recorded from keys.
+func SignatureCheck(keys *signing.KeySet, required bool, logger logging.Logger) PublishCheck {
+	logger = logger.With("component", "signing")
+	return func(ctx context.Context, component *models.Component) (models.ValidationErrors, error) {
+		if len(component.Metadata.Signatures) == 0 && !required {
+			return nil, nil
+		}
+
+		signers, err := keys.Verify(component)
+		if err != nil {
+			return nil, NewValidationError("metadata.signatures", err.Error()).
+				WithDetail("component", component.GetID()).
+				WithCause(err)
+		}
+
+		for i := range component.Metadata.Signatures {
+			component.Metadata.Signatures[i].Publisher = signers[i].Publisher
+		}
+		logger.InfoContext(ctx, "component signatures verified",
+			"name", component.Name, "version", component.Version, "signatures", len(signers))
+		return nil, nil
+	}
+}
+
+// PublishChecks returns the checks configured by c: the policies, then the
+// signature verification, then Checks. It loads the policy and key files.
+func (c *StorageConfig) PublishChecks(logger logging.Logger) ([]PublishCheck, error) {
+	var checks []PublishCheck
+	if len(c.Policies) > 0 {
+		engine, _, err := policy.Load(c.Policies...)
+		if err != nil {
+			return nil, NewConfigurationError("policies", fmt.Sprintf("failed to load policies: %v", err))
+		}
+		checks = append(checks, PolicyCheck(engine, logger))
+	}
+	if c.Signing != nil {
+		keys, err := signing.LoadKeySet(c.Signing.Keys)
+		if err != nil {
+			return nil, NewConfigurationError("signing.keys", fmt.Sprintf("failed to load signing keys: %v", err))
+		}
+		checks = append(checks, SignatureCheck(keys, c.Signing.Required, logger))
+	}
+	return append(checks, c.Checks...), nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/catalog/pkg/signing"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+func awsDatabase() *models.Component {
+	component := draftComponent("1.0.0")
+	component.Provider = "aws"
+	component.Inputs = append(component.Inputs, models.InputSpec{Name: "encrypted", Type: "bool", Description: "Encrypt storage", Default: true})
+	return component
+}
+
+func TestPublishChecks(t *testing.T) {
+	ctx := context.Background()
+	publicKey, privateKey, err := signing.GenerateKey()
+	require.NoError(t, err)
+	keysPath := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keysPath, []byte("keys:\n  - id: platform-2024\n    publisher: platform-team\n    public_key: "+publicKey+"\n"), 0o600))
+
+	called := 0
+	config := &StorageConfig{
+		Type:     "memory",
+		Policies: []string{"../policy/testdata"},
+		Signing:  &SigningStorageConfig{Keys: keysPath, Required: true},
+		Checks: []PublishCheck{func(ctx context.Context, component *models.Component) (models.ValidationErrors, error) {
+			called++
+			return nil, nil
+		}},
+	}
+	require.NoError(t, config.Validate())
+	checks, err := config.PublishChecks(logging.NewNoop())
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+
+	denied := awsDatabase()
+	denied.Inputs[1].Default = false
+	_, err = RunChecks(ctx, checks, denied)
+	require.ErrorIs(t, err, ErrValidation)
+	var issues models.ValidationErrors
+	require.ErrorAs(t, err, &issues)
+	require.Len(t, issues, 1)
+	assert.Equal(t, models.RulePolicy, issues[0].Rule)
+	assert.Equal(t, "inputs[1].default", issues[0].Path)
+	assert.Zero(t, called, "checks stop at the first rejection")
+
+	unsigned := awsDatabase()
+	_, err = RunChecks(ctx, checks, unsigned)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, err, signing.ErrUnsigned)
+
+	key, err := signing.ParsePrivateKey(privateKey)
+	require.NoError(t, err)
+	signed := awsDatabase()
+	require.NoError(t, signing.Sign(signed, "platform-2024", key))
+	warnings, err := RunChecks(ctx, checks, signed)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "platform-team", signed.Metadata.Signatures[0].Publisher)
+	assert.Equal(t, 1, called)
+
+	config.Signing.Keys = ""
+	assert.ErrorIs(t, config.Validate(), ErrConfiguration)
+	config.Signing.Keys = filepath.Join(t.TempDir(), "missing.yaml")
+	_, err = config.PublishChecks(logging.NewNoop())
+	assert.ErrorIs(t, err, ErrConfiguration)
+}
+
+func TestPolicyCheck_WarnViolationsPass(t *testing.T) {
+	config := &StorageConfig{Policies: []string{"../policy/testdata"}}
+	checks, err := config.PublishChecks(logging.NewNoop())
+	require.NoError(t, err)
+
+	vpc := awsDatabase()
+	vpc.Name = "vpc"
+	vpc.Category = "networking"
+	warnings, err := RunChecks(context.Background(), checks, vpc)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, models.ValidationIssue{
+		Path:     "maintainers",
+		Rule:     models.RulePolicy,
+		Message:  "policy 'networking-maintainers': networking components need at least two maintainers",
+		Severity: models.SeverityWarning,
+	}, warnings[0])
+}
+
+func TestSignatureCheck(t *testing.T) {
+	ctx := context.Background()
+	publicKey, privateKey, err := signing.GenerateKey()
+	require.NoError(t, err)
+	public, err := signing.ParsePublicKey(publicKey)
+	require.NoError(t, err)
+	keys, err := signing.NewKeySet(signing.Key{ID: "platform-2024", Publisher: "platform-team", PublicKey: public})
+	require.NoError(t, err)
+	key, err := signing.ParsePrivateKey(privateKey)
+	require.NoError(t, err)
+
+	optional := SignatureCheck(keys, false, logging.NewNoop())
+	_, err = optional(ctx, awsDatabase())
+	assert.NoError(t, err, "unsigned components are accepted unless required")
+
+	_, rogueKey, err := signing.GenerateKey()
+	require.NoError(t, err)
+	rogue, err := signing.ParsePrivateKey(rogueKey)
+	require.NoError(t, err)
+	untrusted := awsDatabase()
+	require.NoError(t, signing.Sign(untrusted, "rogue", rogue))
+	_, err = optional(ctx, untrusted)
+	assert.ErrorIs(t, err, signing.ErrUntrustedKey)
+
+	tampered := awsDatabase()
+	require.NoError(t, signing.Sign(tampered, "platform-2024", key))
+	tampered.Inputs[1].Default = false
+	_, err = optional(ctx, tampered)
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorIs(t, err, models.ErrChecksumMismatch, "a definition differing from the signed one is rejected")
+}
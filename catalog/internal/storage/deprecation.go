@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// DeprecationStore is implemented by stores that can deprecate published
+// versions. Deprecated versions stay resolvable until their sunset date.
+type DeprecationStore interface {
+	// DeprecateVersion deprecates a version on behalf of by, one of its
+	// maintainers, and returns it. Deprecating a version again replaces its
+	// deprecation details. See Deprecate.
+	DeprecateVersion(ctx context.Context, name, version, by string, deprecation models.Deprecation) (*models.Component, error)
+	// DeprecateComponent deprecates every published version of a component
+	// that was not yanked and returns them, latest first. Either every
+	// version is deprecated or none is.
+	DeprecateComponent(ctx context.Context, name, by string, deprecation models.Deprecation) ([]*models.Component, error)
+}
+
+// Deprecate records deprecation on component at now and returns the change
+// to record. Drafts and yanked versions cannot be deprecated, and only the
+// maintainers of a version can deprecate it. A new sunset date cannot be in
+// the past; a version deprecated again keeps its original deprecation time.
+func Deprecate(component *models.Component, deprecation models.Deprecation, by string, now time.Time) (*models.ComponentChange, error) {
+	if status := component.Status(); status == models.VersionStatusDraft || status == models.VersionStatusYanked {
+		return nil, NewConflictError("component version", fmt.Sprintf("%s is %s and cannot be deprecated", component.GetID(), status)).
+			WithDetail("status", status)
+	}
+	if !component.IsMaintainer(by) {
+		return nil, NewValidationError("deprecated_by", fmt.Sprintf("'%s' is not a maintainer of %s", by, component.Name))
+	}
+	if issues := deprecation.Validate(); issues.HasErrors() {
+		return nil, NewComponentValidationError(issues)
+	}
+	if sunset := deprecation.SunsetAt; sunset != nil && sunset.Before(now) {
+		current := component.SunsetAt()
+		if current == nil || !current.Equal(*sunset) {
+			return nil, NewValidationError("sunset_at", fmt.Sprintf("sunset date %s is in the past", sunset.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	if component.Metadata.DeprecatedAt == nil {
+		deprecatedAt := now
+		component.Metadata.DeprecatedAt = &deprecatedAt
+	}
+	component.Metadata.Deprecated = true
+	component.Metadata.Deprecation = deprecation.Clone()
+	component.Metadata.Status = models.VersionStatusDeprecated
+	component.UpdatedAt = now
+
+	return &models.ComponentChange{
+		ID:            fmt.Sprintf("%s:%s", models.ChangeTypeDeprecate, component.GetID()),
+		ComponentName: component.Name,
+		Version:       component.Version,
+		ChangeType:    models.ChangeTypeDeprecate,
+		ChangedBy:     by,
+		ChangedAt:     now,
+		GitCommit:     component.Metadata.GitCommit,
+		Summary:       component.Warnings(now)[0].Message,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+func TestDeprecate(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	sunset := now.Add(90 * 24 * time.Hour)
+	deprecation := models.Deprecation{
+		Reason:      "Replaced by Aurora",
+		Replacement: &models.Replacement{Name: "aurora"},
+		SunsetAt:    &sunset,
+	}
+
+	component := draftComponent("1.0.0")
+	component.Metadata.Status = models.VersionStatusActive
+	require.NoError(t, component.SetChecksum())
+	checksum := component.Metadata.Checksum
+
+	change, err := Deprecate(component, deprecation, "platform@example.com", now)
+	require.NoError(t, err)
+	assert.True(t, component.IsDeprecated())
+	assert.Equal(t, models.VersionStatusDeprecated, component.Status())
+	assert.Equal(t, now, *component.Metadata.DeprecatedAt)
+	assert.Equal(t, &sunset, component.SunsetAt())
+	assert.Equal(t, checksum, component.Metadata.Checksum)
+	assert.NoError(t, component.VerifyChecksum(), "deprecation does not change the definition")
+
+	assert.Equal(t, "deprecate:postgres:1.0.0", change.ID)
+	assert.Equal(t, models.ChangeTypeDeprecate, change.ChangeType)
+	assert.Equal(t, "platform@example.com", change.ChangedBy)
+	assert.Equal(t, "postgres:1.0.0 is deprecated: Replaced by Aurora; use aurora instead; it will be sunset on 2025-05-30", change.Summary)
+
+	later := now.Add(time.Hour)
+	deprecation.Reason = "Replaced by Aurora Serverless"
+	_, err = Deprecate(component, deprecation, "platform@example.com", later)
+	require.NoError(t, err)
+	assert.Equal(t, now, *component.Metadata.DeprecatedAt, "deprecating again keeps the deprecation time")
+	assert.Equal(t, "Replaced by Aurora Serverless", component.Metadata.Deprecation.Reason)
+
+	_, err = Deprecate(component, deprecation, "data@example.com", later)
+	assert.ErrorIs(t, err, ErrValidation, "only maintainers deprecate")
+
+	past := now.Add(-time.Hour)
+	_, err = Deprecate(component, models.Deprecation{SunsetAt: &past}, "platform@example.com", now)
+	assert.ErrorIs(t, err, ErrValidation, "a new sunset date cannot be in the past")
+	_, err = Deprecate(component, deprecation, "platform@example.com", sunset.Add(time.Hour))
+	assert.NoError(t, err, "an existing sunset date can be kept once it passed")
+
+	_, err = Deprecate(component, models.Deprecation{Replacement: &models.Replacement{Name: "Aurora DB"}}, "platform@example.com", now)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = Deprecate(draftComponent("2.0.0"), deprecation, "platform@example.com", now)
+	assert.ErrorIs(t, err, ErrConflict, "drafts cannot be deprecated")
+	yanked := draftComponent("1.1.0")
+	yanked.Metadata.Status = models.VersionStatusYanked
+	_, err = Deprecate(yanked, deprecation, "platform@example.com", now)
+	assert.ErrorIs(t, err, ErrConflict)
+}
@@ -182,6 +182,7 @@ func (c *Client) PutItem(ctx context.Context, input *dynamodb.PutItemInput) (*dy
 	result, err := c.client.PutItem(timeoutCtx, input)
 	if err != nil {
 		c.logger.ErrorContext(ctx, "PutItem failed", "error", err, "operation", "PutItem")
+		return nil, err
 	}
 
 	return result, nil
@@ -280,6 +281,22 @@ func (c *Client) BatchWriteItem(ctx context.Context, input *dynamodb.BatchWriteI
 	return result, nil
 }
 
+func (c *Client) TransactWriteItems(ctx context.Context, input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.logger.DebugContext(ctx, "executing TransactWriteItems", "operation", "TransactWriteItems", "items", len(input.TransactItems))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.config.QueryTimeout)
+	defer cancel()
+
+	result, err := c.client.TransactWriteItems(timeoutCtx, input)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "TransactWriteItems failed", "error", err, "operation", "TransactWriteItems")
+		return nil, err
+	}
+
+	c.logger.DebugContext(ctx, "TransactWriteItems completed", "operation", "TransactWriteItems")
+	return result, nil
+}
+
 func (c *Client) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
 	if input.TableName == nil {
 		input.TableName = aws.String(c.tableName)
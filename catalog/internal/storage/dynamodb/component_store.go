@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -26,6 +27,7 @@ type componentStore struct {
 	logger    logging.Logger
 	tableName string
 	config    *Config
+	checks    []storage.PublishCheck
 }
 
 // NewComponentStore creates a new DynamoDB-backed ComponentStore.
@@ -43,6 +45,11 @@ func NewComponentStore(config *storage.StorageConfig, cache cache.Cache, logger
 		return nil, fmt.Errorf("invalid DynamoDB config: %w", err)
 	}
 
+	checks, err := config.PublishChecks(logger)
+	if err != nil {
+		return nil, err
+	}
+
 	client, err := NewClient(dynamoConfig, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DynamoDB client: %w", err)
@@ -54,6 +61,7 @@ func NewComponentStore(config *storage.StorageConfig, cache cache.Cache, logger
 		logger:    logger.With("component", "dynamodb_component_store"),
 		tableName: dynamoConfig.GetTableName(),
 		config:    dynamoConfig,
+		checks:    checks,
 	}
 
 	if dynamoConfig.AutoCreateTable {
@@ -112,6 +120,11 @@ func (s *componentStore) GetComponent(ctx context.Context, name, version string)
 	}
 
 	component := dbItem.ToComponent()
+	if err := component.VerifyChecksum(); err != nil {
+		s.logger.ErrorContext(ctx, "component does not match its checksum",
+			"name", name, "version", version, "error", err)
+		return nil, storage.NewIntegrityError(component.GetID(), err)
+	}
 
 	if s.cache != nil {
 		cacheKey := s.buildComponentCacheKey(name, version)
@@ -135,6 +148,24 @@ func (s *componentStore) ListComponents(ctx context.Context, filters storage.Com
 		return nil, fmt.Errorf("invalid pagination: %w", err)
 	}
 
+	taxonomy, err := s.GetTaxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filters = filters.Normalized(taxonomy)
+
+	if filters.HighestOnly {
+		scan := func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+			result, err := s.client.Scan(ctx, input)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "failed to scan components", "error", err)
+				return nil, s.wrapDynamoDBError(err, "ListComponents", "", "")
+			}
+			return result, nil
+		}
+		return listHighestVersions(ctx, scan, s.buildScanInput(&filters, &storage.Pagination{}), filters, pagination, s.logger)
+	}
+
 	scanInput := s.buildScanInput(&filters, &pagination)
 
 	result, err := s.client.Scan(ctx, scanInput)
@@ -143,17 +174,7 @@ func (s *componentStore) ListComponents(ctx context.Context, filters storage.Com
 		return nil, s.wrapDynamoDBError(err, "ListComponents", "", "")
 	}
 
-	components := make([]*models.Component, 0, len(result.Items))
-	for _, item := range result.Items {
-		var dbItem ComponentItem
-		if err := attributevalue.UnmarshalMap(item, &dbItem); err != nil {
-			s.logger.WarnContext(ctx, "failed to unmarshal component", "error", err)
-			continue
-		}
-		components = append(components, dbItem.ToComponent())
-	}
-
-	components = s.applyPostScanFilters(components, &filters)
+	components := s.applyPostScanFilters(scannedComponents(ctx, result.Items, s.logger), &filters)
 	components = s.applySorting(components, pagination.SortBy, pagination.SortOrder)
 
 	var nextToken string
@@ -174,42 +195,214 @@ func (s *componentStore) ListComponents(ctx context.Context, filters storage.Com
 	return response, nil
 }
 
-// StoreComponent stores a component definition.
-func (s *componentStore) StoreComponent(ctx context.Context, component *models.Component) error {
+// scanFunc scans a page of the table.
+type scanFunc func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+
+// listHighestVersions lists the highest version of each component matching
+// filters. The versions of a component can span scan pages, so every page of
+// input is scanned before the highest versions are sorted and paginated like
+// in the other stores, with tokens holding an offset.
+func listHighestVersions(ctx context.Context, scan scanFunc, input *dynamodb.ScanInput, filters storage.ComponentFilters, pagination storage.Pagination, logger logging.Logger) (*storage.ComponentList, error) {
+	var components []*models.Component
+	for {
+		result, err := scan(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, component := range scannedComponents(ctx, result.Items, logger) {
+			if filters.Matches(component) {
+				components = append(components, component)
+			}
+		}
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	components = storage.HighestVersions(components)
+	storage.SortComponents(components, pagination.SortBy, pagination.SortOrder)
+	return storage.Paginate(components, pagination)
+}
+
+// scannedComponents decodes the components among scanned items.
+func scannedComponents(ctx context.Context, items []map[string]types.AttributeValue, logger logging.Logger) []*models.Component {
+	components := make([]*models.Component, 0, len(items))
+	for _, item := range items {
+		var dbItem ComponentItem
+		if err := attributevalue.UnmarshalMap(item, &dbItem); err != nil {
+			logger.WarnContext(ctx, "failed to unmarshal component", "error", err)
+			continue
+		}
+		if !strings.HasPrefix(dbItem.PK, "COMPONENT#") {
+			// The table also holds non-component items such as the taxonomy.
+			continue
+		}
+		components = append(components, dbItem.ToComponent())
+	}
+	return components
+}
+
+// StoreComponent stores a component definition as a draft, replacing the
+// draft of the same version if any. The write is conditional so that a
+// version promoted or edited concurrently is not overwritten. The warnings of
+// the validation and publish checks are returned.
+func (s *componentStore) StoreComponent(ctx context.Context, component *models.Component) (models.ValidationErrors, error) {
 	if component == nil {
-		return storage.NewValidationError("component", "component is required")
+		return nil, storage.NewValidationError("component", "component is required")
 	}
 
 	s.logger.InfoContext(ctx, "storing component",
 		"name", component.Name, "version", component.Version)
 
-	// For MVP, we'll use the validator from the models package
-	validator := models.NewComponentValidator()
-	if err := validator.Validate(component); err != nil {
-		return storage.NewValidationError("component", fmt.Sprintf("invalid component: %v", err))
+	taxonomy, err := s.GetTaxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := storage.ValidateComponent(component, taxonomy)
+	if err != nil {
+		return nil, err
+	}
+	checkWarnings, err := storage.RunChecks(ctx, s.checks, component)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, checkWarnings...)
+
+	existing, err := s.readComponent(ctx, component.Name, component.Version)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.PrepareDraft(component, existing); err != nil {
+		return nil, err
+	}
+	if err := component.SetChecksum(); err != nil {
+		return nil, err
 	}
 
 	dbItem := NewComponentItemFromComponent(component)
 	item, err := attributevalue.MarshalMap(dbItem)
 	if err != nil {
-		return fmt.Errorf("failed to marshal component: %w", err)
+		return nil, fmt.Errorf("failed to marshal component: %w", err)
 	}
 
-	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+	input := &dynamodb.PutItemInput{
 		TableName: aws.String(s.tableName),
 		Item:      item,
-	})
-	if err != nil {
+	}
+	if existing == nil {
+		input.ConditionExpression = aws.String("attribute_not_exists(PK)")
+	} else {
+		unchangedDraft(input, existing.Metadata.Checksum)
+	}
+
+	if _, err := s.client.PutItem(ctx, input); err != nil {
 		s.logger.ErrorContext(ctx, "failed to store component",
 			"name", component.Name, "version", component.Version, "error", err)
-		return s.wrapDynamoDBError(err, "StoreComponent", component.Name, component.Version)
+		return nil, s.wrapDynamoDBError(err, "StoreComponent", component.Name, component.Version)
 	}
 
-	s.invalidateComponentCaches(ctx, component.Name)
+	s.invalidateVersionCaches(ctx, component.Name, component.Version)
 
-	s.logger.InfoContext(ctx, "component stored successfully",
+	s.logger.InfoContext(ctx, "draft stored successfully",
 		"name", component.Name, "version", component.Version)
 
+	return warnings, nil
+}
+
+// PutComponents writes components verbatim using batched writes, keeping
+// their timestamps.
+func (s *componentStore) PutComponents(ctx context.Context, components []*models.Component) error {
+	requests := make([]types.WriteRequest, 0, len(components))
+	for i, component := range components {
+		if component == nil {
+			return storage.NewValidationError(fmt.Sprintf("components[%d]", i), "component is required")
+		}
+
+		item, err := attributevalue.MarshalMap(newComponentItem(component))
+		if err != nil {
+			return fmt.Errorf("failed to marshal component %s: %w", component.GetID(), err)
+		}
+		requests = append(requests, types.WriteRequest{
+			PutRequest: &types.PutRequest{Item: item},
+		})
+	}
+
+	batchSize := s.client.GetMaxBatchSize()
+	for start := 0; start < len(requests); start += batchSize {
+		end := min(start+batchSize, len(requests))
+		if err := s.writeBatch(ctx, requests[start:end]); err != nil {
+			s.logger.ErrorContext(ctx, "failed to write component batch",
+				"offset", start, "size", end-start, "error", err)
+			return err
+		}
+	}
+
+	for _, component := range components {
+		s.invalidateVersionCaches(ctx, component.Name, component.Version)
+	}
+
+	s.logger.DebugContext(ctx, "components written", "count", len(components))
+	return nil
+}
+
+// PutChanges writes changes verbatim using batched writes. A change recorded
+// at the same time for the same version is replaced.
+func (s *componentStore) PutChanges(ctx context.Context, changes []models.ComponentChange) error {
+	requests := make([]types.WriteRequest, 0, len(changes))
+	for i := range changes {
+		item, err := changeItem(&changes[i])
+		if err != nil {
+			return err
+		}
+		requests = append(requests, types.WriteRequest{
+			PutRequest: &types.PutRequest{Item: item},
+		})
+	}
+
+	batchSize := s.client.GetMaxBatchSize()
+	for start := 0; start < len(requests); start += batchSize {
+		end := min(start+batchSize, len(requests))
+		if err := s.writeBatch(ctx, requests[start:end]); err != nil {
+			s.logger.ErrorContext(ctx, "failed to write change batch",
+				"offset", start, "size", end-start, "error", err)
+			return err
+		}
+	}
+
+	s.logger.DebugContext(ctx, "changes written", "count", len(changes))
+	return nil
+}
+
+// writeBatch sends a single BatchWriteItem request and retries whatever
+// DynamoDB reports back as unprocessed, backing off between attempts.
+func (s *componentStore) writeBatch(ctx context.Context, requests []types.WriteRequest) error {
+	pending := map[string][]types.WriteRequest{s.tableName: requests}
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > s.config.MaxRetries {
+			return storage.NewThrottledError("unprocessed items remain after retries").
+				WithDetail("operation", "PutComponents").
+				WithDetail("unprocessed", len(pending[s.tableName]))
+		}
+
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(1<<attempt) * 50 * time.Millisecond):
+			}
+		}
+
+		result, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: pending,
+		})
+		if err != nil {
+			return s.wrapDynamoDBError(err, "PutComponents")
+		}
+		pending = result.UnprocessedItems
+	}
+
 	return nil
 }
 
@@ -243,14 +436,7 @@ func (s *componentStore) GetVersionHistory(ctx context.Context, name string) ([]
 			continue
 		}
 
-		component := dbItem.ToComponent()
-		version := models.ComponentVersion{
-			ComponentName: component.Name,
-			Version:       component.Version,
-			CreatedAt:     component.CreatedAt,
-			Status:        models.VersionStatusActive, // Simplified for MVP
-		}
-		versions = append(versions, version)
+		versions = append(versions, models.NewComponentVersion(dbItem.ToComponent()))
 	}
 
 	s.logger.DebugContext(ctx, "retrieved version history", "name", name, "count", len(versions))
@@ -264,7 +450,8 @@ func (s *componentStore) HealthCheck(ctx context.Context) error {
 	if err := s.client.Ping(ctx); err != nil {
 		s.logger.ErrorContext(ctx, "health check failed", "error", err)
 		return storage.NewStorageUnavailableError(err.Error()).
-			WithDetail("operation", "HealthCheck")
+			WithDetail("operation", "HealthCheck").
+			WithCause(err)
 	}
 
 	s.logger.DebugContext(ctx, "health check passed")
@@ -294,6 +481,18 @@ func (s *componentStore) invalidateComponentCaches(ctx context.Context, name str
 	}
 }
 
+// invalidateVersionCaches drops the cached copy of a version along with the
+// caches of its component.
+func (s *componentStore) invalidateVersionCaches(ctx context.Context, name, version string) {
+	s.invalidateComponentCaches(ctx, name)
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Delete(ctx, s.buildComponentCacheKey(name, version)); err != nil {
+		s.logger.WarnContext(ctx, "failed to invalidate component cache", "name", name, "version", version, "error", err)
+	}
+}
+
 func (s *componentStore) wrapDynamoDBError(err error, operation string, params ...string) error {
 	var name, version string
 	if len(params) > 0 {
@@ -311,26 +510,32 @@ func (s *componentStore) wrapDynamoDBError(err error, operation string, params .
 	case errors.As(err, &resourceNotFoundErr):
 		if name != "" {
 			return storage.NewComponentNotFoundError(name, version).
-				WithDetail("operation", operation)
+				WithDetail("operation", operation).
+				WithCause(err)
 		}
 		return storage.NewResourceNotFoundError("component", "unknown").
-			WithDetail("operation", operation)
+			WithDetail("operation", operation).
+			WithCause(err)
 
 	case errors.As(err, &conditionalCheckFailedErr):
 		if name != "" {
 			return storage.NewComponentExistsError(name, version).
-				WithDetail("operation", operation)
+				WithDetail("operation", operation).
+				WithCause(err)
 		}
 		return storage.NewResourceExistsError("component", "unknown").
-			WithDetail("operation", operation)
+			WithDetail("operation", operation).
+			WithCause(err)
 
 	case errors.As(err, &throttledErr):
 		return storage.NewThrottledError(err.Error()).
-			WithDetail("operation", operation)
+			WithDetail("operation", operation).
+			WithCause(err)
 
 	default:
 		return storage.NewStorageUnavailableError(err.Error()).
-			WithDetail("operation", operation)
+			WithDetail("operation", operation).
+			WithCause(err)
 	}
 }
 
@@ -437,27 +642,7 @@ func (s *componentStore) applyPostScanFilters(components []*models.Component, fi
 }
 
 func (s *componentStore) matchesFilters(component *models.Component, filters *storage.ComponentFilters) bool {
-	if filters.ActiveOnly && component.IsDeprecated() {
-		return false
-	}
-
-	if filters.CreatedAfter != nil && component.CreatedAt.Before(*filters.CreatedAfter) {
-		return false
-	}
-	if filters.CreatedBefore != nil && component.CreatedAt.After(*filters.CreatedBefore) {
-		return false
-	}
-
-	// Labels filtering removed for MVP - can be added back later if needed
-	// if len(filters.Labels) > 0 {
-	//     for key, value := range filters.Labels {
-	//         if !component.HasLabel(key, value) {
-	//             return false
-	//         }
-	//     }
-	// }
-
-	return true
+	return filters.Matches(component)
 }
 
 func (s *componentStore) applySorting(components []*models.Component, sortBy storage.SortField, sortOrder storage.SortOrder) []*models.Component {
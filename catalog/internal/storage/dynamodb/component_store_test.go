@@ -0,0 +1,102 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// pagedScan serves items as scan pages of the given sizes.
+func pagedScan(t *testing.T, items []map[string]types.AttributeValue, sizes ...int) scanFunc {
+	t.Helper()
+	return func(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		page := 0
+		if input.ExclusiveStartKey != nil {
+			require.NoError(t, attributevalue.Unmarshal(input.ExclusiveStartKey["page"], &page))
+		}
+		start := 0
+		for _, size := range sizes[:page] {
+			start += size
+		}
+		end := min(start+sizes[page], len(items))
+
+		output := &dynamodb.ScanOutput{Items: items[start:end]}
+		if page+1 < len(sizes) {
+			output.LastEvaluatedKey = map[string]types.AttributeValue{
+				"page": &types.AttributeValueMemberN{Value: strconv.Itoa(page + 1)},
+			}
+		}
+		return output, nil
+	}
+}
+
+// listAll follows the pages of list and returns the IDs of the components
+// listed.
+func listAll(t *testing.T, list func(pagination storage.Pagination) (*storage.ComponentList, error)) []string {
+	t.Helper()
+	var ids []string
+	pagination := storage.Pagination{Limit: 1}
+	for {
+		page, err := list(pagination)
+		require.NoError(t, err)
+		for _, component := range page.Components {
+			ids = append(ids, component.GetID())
+		}
+		if !page.HasMore {
+			return ids
+		}
+		pagination.NextToken = page.NextToken
+	}
+}
+
+func TestListHighestVersions_SpansScanPages(t *testing.T) {
+	ctx := context.Background()
+	var components []*models.Component
+	var items []map[string]types.AttributeValue
+	for _, id := range [][2]string{
+		{"postgres", "1.0.0"}, {"mysql", "3.0.0"},
+		{"postgres", "2.1.0"}, {"redis", "0.1.0"},
+		{"mysql", "1.0.0"}, {"postgres", "1.5.0"},
+	} {
+		component := fullComponent()
+		component.Name, component.Version = id[0], id[1]
+		components = append(components, component)
+		item, err := attributevalue.MarshalMap(newComponentItem(component))
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	taxonomy, err := attributevalue.MarshalMap(map[string]string{"PK": "TAXONOMY", "SK": "TAXONOMY"})
+	require.NoError(t, err)
+	items = append(items[:3], append([]map[string]types.AttributeValue{taxonomy}, items[3:]...)...)
+
+	reference := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, reference.(storage.BulkWriter).PutComponents(ctx, components))
+
+	for _, filters := range []storage.ComponentFilters{
+		{HighestOnly: true},
+		{HighestOnly: true, VersionConstraint: "<2.0.0"},
+	} {
+		require.NoError(t, filters.Validate())
+		want := listAll(t, func(pagination storage.Pagination) (*storage.ComponentList, error) {
+			return reference.ListComponents(ctx, filters, pagination)
+		})
+		got := listAll(t, func(pagination storage.Pagination) (*storage.ComponentList, error) {
+			return listHighestVersions(ctx, pagedScan(t, items, 2, 3, 2), &dynamodb.ScanInput{}, filters, pagination, logging.NewNoop())
+		})
+		assert.Equal(t, want, got, "constraint %q", filters.VersionConstraint)
+	}
+	assert.Equal(t, []string{"mysql:3.0.0", "postgres:2.1.0", "redis:0.1.0"}, listAll(t, func(pagination storage.Pagination) (*storage.ComponentList, error) {
+		return listHighestVersions(ctx, pagedScan(t, items, 2, 3, 2), &dynamodb.ScanInput{}, storage.ComponentFilters{HighestOnly: true}, pagination, logging.NewNoop())
+	}))
+}
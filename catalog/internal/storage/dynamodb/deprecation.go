@@ -0,0 +1,124 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// maxDeprecatedVersions is the number of versions DeprecateComponent can
+// deprecate at once: a transaction holds 100 items, and each version is
+// written with its change.
+const maxDeprecatedVersions = 50
+
+// DeprecateVersion deprecates a published version and records its change in
+// a single transaction. The write is conditional on the version being
+// unchanged since it was read.
+func (s *componentStore) DeprecateVersion(ctx context.Context, name, version, by string, deprecation models.Deprecation) (*models.Component, error) {
+	component, write, err := s.prepareDeprecation(ctx, name, version, by, deprecation, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.writeVersions(ctx, write); err != nil {
+		if conditionFailed(err) {
+			return nil, storage.NewConflictError("component version", fmt.Sprintf("%s changed while it was deprecated", component.GetID())).
+				WithCause(err)
+		}
+		s.logger.ErrorContext(ctx, "failed to deprecate component", "name", name, "version", version, "error", err)
+		return nil, s.wrapDynamoDBError(err, "DeprecateVersion", name, version)
+	}
+	s.invalidateVersionCaches(ctx, name, version)
+
+	s.logger.InfoContext(ctx, "version deprecated", "name", name, "version", version, "by", by)
+	return component, nil
+}
+
+// DeprecateComponent deprecates every published version of a component. Every
+// version is checked before any is written, then the versions and their
+// changes are written in a single transaction, so either all of them are
+// deprecated or none is.
+func (s *componentStore) DeprecateComponent(ctx context.Context, name, by string, deprecation models.Deprecation) ([]*models.Component, error) {
+	history, err := s.GetVersionHistory(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var deprecated []*models.Component
+	var writes []versionWrite
+	for _, version := range history {
+		if version.Status == models.VersionStatusDraft || version.Status == models.VersionStatusYanked {
+			continue
+		}
+		component, write, err := s.prepareDeprecation(ctx, name, version.Version, by, deprecation, now)
+		if err != nil {
+			return nil, err
+		}
+		deprecated = append(deprecated, component)
+		writes = append(writes, write)
+	}
+	if len(deprecated) == 0 {
+		return nil, storage.NewComponentNotFoundError(name, "").
+			WithDetail("operation", "DeprecateComponent")
+	}
+	if len(writes) > maxDeprecatedVersions {
+		return nil, storage.NewValidationError("versions", fmt.Sprintf("%s has %d published versions, at most %d can be deprecated at once; deprecate them one by one",
+			name, len(writes), maxDeprecatedVersions))
+	}
+
+	if err := s.writeVersions(ctx, writes...); err != nil {
+		if conditionFailed(err) {
+			return nil, storage.NewConflictError("component", fmt.Sprintf("a version of %s changed while it was deprecated", name)).
+				WithCause(err)
+		}
+		s.logger.ErrorContext(ctx, "failed to deprecate component", "name", name, "error", err)
+		return nil, s.wrapDynamoDBError(err, "DeprecateComponent", name)
+	}
+	for _, component := range deprecated {
+		s.invalidateVersionCaches(ctx, name, component.Version)
+	}
+
+	s.logger.InfoContext(ctx, "component deprecated", "name", name, "versions", len(deprecated), "by", by)
+	return deprecated, nil
+}
+
+// prepareDeprecation reads a version and deprecates it, returning the
+// deprecated version and its conditional write with its change.
+func (s *componentStore) prepareDeprecation(ctx context.Context, name, version, by string, deprecation models.Deprecation, now time.Time) (*models.Component, versionWrite, error) {
+	component, err := s.readComponent(ctx, name, version)
+	if err != nil {
+		return nil, versionWrite{}, err
+	}
+	if component == nil {
+		return nil, versionWrite{}, storage.NewComponentNotFoundError(name, version).
+			WithDetail("operation", "DeprecateVersion")
+	}
+	if err := component.VerifyChecksum(); err != nil {
+		return nil, versionWrite{}, storage.NewIntegrityError(component.GetID(), err)
+	}
+
+	status := component.Metadata.Status
+	change, err := storage.Deprecate(component, deprecation, by, now)
+	if err != nil {
+		return nil, versionWrite{}, err
+	}
+
+	item, err := attributevalue.MarshalMap(newComponentItem(component))
+	if err != nil {
+		return nil, versionWrite{}, fmt.Errorf("failed to marshal component: %w", err)
+	}
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	}
+	unchangedVersion(input, status, component.Metadata.Checksum)
+	return component, versionWrite{input: input, change: change}, nil
+}
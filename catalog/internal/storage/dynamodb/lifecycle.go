@@ -0,0 +1,393 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+// changeTimeLayout formats change times in sort keys. It has a fixed width
+// so that keys sort chronologically.
+const changeTimeLayout = "20060102T150405.000000000Z"
+
+// ChangeItem represents a ComponentChange stored in DynamoDB. Changes of a
+// component share a partition, sorted by time. The change is kept as a JSON
+// document since it is only ever read whole.
+type ChangeItem struct {
+	PK       string `dynamodbav:"PK"`
+	SK       string `dynamodbav:"SK"`
+	Document string `dynamodbav:"Document"`
+}
+
+// ApproveVersion records the approval of a draft and its change in a single
+// transaction. The write is conditional on the draft not being updated since
+// it was read, so that concurrent approvals are not lost.
+func (s *componentStore) ApproveVersion(ctx context.Context, name, version, by string) (*models.Component, error) {
+	draft, err := s.readComponent(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return nil, storage.NewComponentNotFoundError(name, version).
+			WithDetail("operation", "ApproveVersion")
+	}
+	if err := draft.VerifyChecksum(); err != nil {
+		return nil, storage.NewIntegrityError(draft.GetID(), err)
+	}
+
+	previous, err := s.previousVersion(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	taxonomy, err := s.GetTaxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt, err := attributevalue.Marshal(draft.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal update time: %w", err)
+	}
+	if err := storage.ApproveDraft(draft, previous, taxonomy, by); err != nil {
+		return nil, err
+	}
+
+	item, err := attributevalue.MarshalMap(newComponentItem(draft))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal component: %w", err)
+	}
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	}
+	unchangedDraft(input, draft.Metadata.Checksum)
+	input.ConditionExpression = aws.String(*input.ConditionExpression + " AND UpdatedAt = :updated_at")
+	input.ExpressionAttributeValues[":updated_at"] = updatedAt
+
+	if err := s.writeVersions(ctx, versionWrite{input: input, change: storage.ApprovalChange(draft, by)}); err != nil {
+		if conditionFailed(err) {
+			return nil, storage.NewConflictError("component version", fmt.Sprintf("%s changed while it was approved", draft.GetID())).
+				WithCause(err)
+		}
+		s.logger.ErrorContext(ctx, "failed to approve component", "name", name, "version", version, "error", err)
+		return nil, s.wrapDynamoDBError(err, "ApproveVersion", name, version)
+	}
+	s.invalidateVersionCaches(ctx, name, version)
+
+	s.logger.InfoContext(ctx, "draft approved", "name", name, "version", version, "by", by)
+	return draft, nil
+}
+
+// previousVersion returns the version a draft succeeds, or nil for the
+// first version of a component.
+func (s *componentStore) previousVersion(ctx context.Context, name, version string) (*models.Component, error) {
+	history, err := s.GetVersionHistory(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	previousVersion := storage.PreviousVersion(history, version)
+	if previousVersion == "" {
+		return nil, nil
+	}
+	return s.GetComponent(ctx, name, previousVersion)
+}
+
+// PromoteVersion makes a draft version active and records its change in a
+// single transaction. The write is conditional on the draft being unchanged
+// since it was validated.
+func (s *componentStore) PromoteVersion(ctx context.Context, name, version, by string) (*models.ComponentChange, error) {
+	draft, err := s.readComponent(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return nil, storage.NewComponentNotFoundError(name, version).
+			WithDetail("operation", "PromoteVersion")
+	}
+	if err := draft.VerifyChecksum(); err != nil {
+		return nil, storage.NewIntegrityError(draft.GetID(), err)
+	}
+
+	previous, err := s.previousVersion(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	taxonomy, err := s.GetTaxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checksum := draft.Metadata.Checksum
+	change, err := storage.PromoteDraft(draft, previous, taxonomy, by)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := storage.RunChecks(ctx, s.checks, draft)
+	if err != nil {
+		return nil, err
+	}
+	change.Warnings = append(change.Warnings, warnings...)
+
+	item, err := attributevalue.MarshalMap(newComponentItem(draft))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal component: %w", err)
+	}
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	}
+	unchangedDraft(input, checksum)
+
+	if err := s.writeVersions(ctx, versionWrite{input: input, change: change}); err != nil {
+		if conditionFailed(err) {
+			return nil, storage.NewConflictError("component version", fmt.Sprintf("%s changed while it was promoted", draft.GetID())).
+				WithCause(err)
+		}
+		s.logger.ErrorContext(ctx, "failed to promote component", "name", name, "version", version, "error", err)
+		return nil, s.wrapDynamoDBError(err, "PromoteVersion", name, version)
+	}
+	s.invalidateVersionCaches(ctx, name, version)
+
+	s.logger.InfoContext(ctx, "draft promoted", "name", name, "version", version, "by", by)
+	return change, nil
+}
+
+// GetChanges returns the changes recorded for a component, oldest first.
+func (s *componentStore) GetChanges(ctx context.Context, name string) ([]models.ComponentChange, error) {
+	if name == "" {
+		return nil, storage.NewValidationError("name", "component name is required")
+	}
+
+	result, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk_prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":        &types.AttributeValueMemberS{Value: changesPK(name)},
+			":sk_prefix": &types.AttributeValueMemberS{Value: "CHANGE#"},
+		},
+		ScanIndexForward: aws.Bool(true),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get changes", "name", name, "error", err)
+		return nil, s.wrapDynamoDBError(err, "GetChanges", name, "")
+	}
+
+	changes := make([]models.ComponentChange, 0, len(result.Items))
+	for _, item := range result.Items {
+		var changeItem ChangeItem
+		if err := attributevalue.UnmarshalMap(item, &changeItem); err != nil {
+			s.logger.WarnContext(ctx, "failed to unmarshal change", "error", err)
+			continue
+		}
+		var change models.ComponentChange
+		if err := json.FromJSON([]byte(changeItem.Document), &change); err != nil {
+			s.logger.WarnContext(ctx, "failed to decode change", "sk", changeItem.SK, "error", err)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// PurgeDrafts scans the table for drafts last updated before olderThan and
+// deletes them. A draft promoted or edited during the scan is kept.
+func (s *componentStore) PurgeDrafts(ctx context.Context, olderThan time.Time) ([]string, error) {
+	var purged []string
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := s.client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:                aws.String(s.tableName),
+			FilterExpression:         aws.String("begins_with(PK, :pk_prefix) AND #state = :draft"),
+			ExpressionAttributeNames: map[string]string{"#state": "State"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk_prefix": &types.AttributeValueMemberS{Value: "COMPONENT#"},
+				":draft":     &types.AttributeValueMemberS{Value: string(models.VersionStatusDraft)},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return purged, s.wrapDynamoDBError(err, "PurgeDrafts")
+		}
+
+		for _, item := range result.Items {
+			var dbItem ComponentItem
+			if err := attributevalue.UnmarshalMap(item, &dbItem); err != nil {
+				s.logger.WarnContext(ctx, "failed to unmarshal component", "error", err)
+				continue
+			}
+			if !dbItem.UpdatedAt.Before(olderThan) {
+				continue
+			}
+			deleted, err := s.deleteDraft(ctx, &dbItem)
+			if err != nil {
+				return purged, err
+			}
+			if deleted {
+				purged = append(purged, dbItem.Name+":"+dbItem.Version)
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	slices.Sort(purged)
+	s.logger.DebugContext(ctx, "drafts purged", "count", len(purged))
+	return purged, nil
+}
+
+// deleteDraft deletes the draft item unless it changed since it was read.
+func (s *componentStore) deleteDraft(ctx context.Context, item *ComponentItem) (bool, error) {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: item.PK},
+			"SK": &types.AttributeValueMemberS{Value: item.SK},
+		},
+		ConditionExpression:      aws.String("#state = :draft AND Checksum = :checksum"),
+		ExpressionAttributeNames: map[string]string{"#state": "State"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":draft":    &types.AttributeValueMemberS{Value: string(models.VersionStatusDraft)},
+			":checksum": &types.AttributeValueMemberS{Value: item.Checksum},
+		},
+	}
+	if _, err := s.client.DeleteItem(ctx, input); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return false, nil
+		}
+		return false, s.wrapDynamoDBError(err, "PurgeDrafts", item.Name, item.Version)
+	}
+	s.invalidateVersionCaches(ctx, item.Name, item.Version)
+	return true, nil
+}
+
+// readComponent reads a version bypassing the cache, returning nil when it
+// does not exist.
+func (s *componentStore) readComponent(ctx context.Context, name, version string) (*models.Component, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: s.buildComponentPK(name)},
+			"SK": &types.AttributeValueMemberS{Value: s.buildVersionSK(version)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, s.wrapDynamoDBError(err, "GetComponent", name, version)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var dbItem ComponentItem
+	if err := attributevalue.UnmarshalMap(result.Item, &dbItem); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal component: %w", err)
+	}
+	return dbItem.ToComponent(), nil
+}
+
+// versionWrite is a conditional write of a component version with the change
+// it records.
+type versionWrite struct {
+	input  *dynamodb.PutItemInput
+	change *models.ComponentChange
+}
+
+// writeVersions writes versions and their changes in a single transaction:
+// either every version is written with its change, or nothing is. See
+// conditionFailed for the error of a write whose condition no longer holds.
+func (s *componentStore) writeVersions(ctx context.Context, writes ...versionWrite) error {
+	items := make([]types.TransactWriteItem, 0, 2*len(writes))
+	for _, write := range writes {
+		change, err := changeItem(write.change)
+		if err != nil {
+			return err
+		}
+		items = append(items,
+			types.TransactWriteItem{Put: &types.Put{
+				TableName:                 write.input.TableName,
+				Item:                      write.input.Item,
+				ConditionExpression:       write.input.ConditionExpression,
+				ExpressionAttributeNames:  write.input.ExpressionAttributeNames,
+				ExpressionAttributeValues: write.input.ExpressionAttributeValues,
+			}},
+			types.TransactWriteItem{Put: &types.Put{
+				TableName: aws.String(s.tableName),
+				Item:      change,
+			}},
+		)
+	}
+	_, err := s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	return err
+}
+
+// conditionFailed reports whether err is a write or a transaction cancelled
+// because a condition no longer held.
+func conditionFailed(err error) bool {
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return true
+	}
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) {
+		for _, reason := range cancelled.CancellationReasons {
+			if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changeItem encodes change as the item recording it. Changes are keyed by
+// component, time and version.
+func changeItem(change *models.ComponentChange) (map[string]types.AttributeValue, error) {
+	document, err := json.ToJSON(change)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode change: %w", err)
+	}
+	item, err := attributevalue.MarshalMap(ChangeItem{
+		PK:       changesPK(change.ComponentName),
+		SK:       fmt.Sprintf("CHANGE#%s#%s", change.ChangedAt.UTC().Format(changeTimeLayout), change.Version),
+		Document: string(document),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal change: %w", err)
+	}
+	return item, nil
+}
+
+// unchangedDraft makes input conditional on the item being a draft that
+// still has checksum.
+func unchangedDraft(input *dynamodb.PutItemInput, checksum string) {
+	unchangedVersion(input, models.VersionStatusDraft, checksum)
+}
+
+// unchangedVersion makes input conditional on the item still having status
+// and checksum.
+func unchangedVersion(input *dynamodb.PutItemInput, status models.VersionStatus, checksum string) {
+	input.ConditionExpression = aws.String("#state = :state AND Checksum = :checksum")
+	input.ExpressionAttributeNames = map[string]string{"#state": "State"}
+	input.ExpressionAttributeValues = map[string]types.AttributeValue{
+		":state":    &types.AttributeValueMemberS{Value: string(status)},
+		":checksum": &types.AttributeValueMemberS{Value: checksum},
+	}
+}
+
+func changesPK(name string) string {
+	return "CHANGES#" + name
+}
@@ -0,0 +1,22 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionFailed(t *testing.T) {
+	assert.True(t, conditionFailed(fmt.Errorf("put: %w", &types.ConditionalCheckFailedException{})))
+	assert.True(t, conditionFailed(&types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("None")}, {Code: aws.String("ConditionalCheckFailed")}},
+	}), "a transaction is cancelled by the write whose condition failed")
+	assert.False(t, conditionFailed(&types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: aws.String("ThrottlingError")}},
+	}))
+	assert.False(t, conditionFailed(errors.New("connection reset")))
+}
@@ -0,0 +1,122 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+// taxonomyKey is the partition and sort key of the single taxonomy item.
+const taxonomyKey = "TAXONOMY"
+
+// TaxonomyItem represents the taxonomy stored in DynamoDB. The terms are
+// kept as a JSON document since they are always read and written together.
+type TaxonomyItem struct {
+	PK        string    `dynamodbav:"PK"`
+	SK        string    `dynamodbav:"SK"`
+	Revision  int64     `dynamodbav:"Revision"`
+	UpdatedAt time.Time `dynamodbav:"UpdatedAt"`
+	Document  string    `dynamodbav:"Document"`
+}
+
+// GetTaxonomy returns the current taxonomy.
+func (s *componentStore) GetTaxonomy(ctx context.Context) (*models.Taxonomy, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            taxonomyItemKey(),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get taxonomy", "error", err)
+		return nil, s.wrapDynamoDBError(err, "GetTaxonomy")
+	}
+	if result.Item == nil {
+		return &models.Taxonomy{}, nil
+	}
+
+	var item TaxonomyItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal taxonomy: %w", err)
+	}
+	var taxonomy models.Taxonomy
+	if err := json.FromJSON([]byte(item.Document), &taxonomy); err != nil {
+		return nil, fmt.Errorf("failed to decode taxonomy: %w", err)
+	}
+	taxonomy.Revision = item.Revision
+	taxonomy.UpdatedAt = item.UpdatedAt
+	return &taxonomy, nil
+}
+
+// PutTaxonomy replaces the taxonomy with a conditional write on its revision.
+func (s *componentStore) PutTaxonomy(ctx context.Context, taxonomy *models.Taxonomy) error {
+	if err := storage.ValidateTaxonomy(taxonomy); err != nil {
+		return err
+	}
+
+	next := *taxonomy
+	next.Revision++
+	next.UpdatedAt = time.Now().UTC()
+	document, err := json.ToJSON(next)
+	if err != nil {
+		return fmt.Errorf("failed to encode taxonomy: %w", err)
+	}
+
+	item, err := attributevalue.MarshalMap(TaxonomyItem{
+		PK:        taxonomyKey,
+		SK:        taxonomyKey,
+		Revision:  next.Revision,
+		UpdatedAt: next.UpdatedAt,
+		Document:  string(document),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal taxonomy: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:                           aws.String(s.tableName),
+		Item:                                item,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+	if taxonomy.Revision == 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(PK)")
+	} else {
+		input.ConditionExpression = aws.String("Revision = :revision")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":revision": &types.AttributeValueMemberN{Value: strconv.FormatInt(taxonomy.Revision, 10)},
+		}
+	}
+
+	if _, err := s.client.PutItem(ctx, input); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			var current TaxonomyItem
+			_ = attributevalue.UnmarshalMap(conditionErr.Item, &current)
+			return storage.NewRevisionConflictError(taxonomy.Revision, current.Revision).WithCause(err)
+		}
+		s.logger.ErrorContext(ctx, "failed to store taxonomy", "error", err)
+		return s.wrapDynamoDBError(err, "PutTaxonomy")
+	}
+
+	taxonomy.Revision = next.Revision
+	taxonomy.UpdatedAt = next.UpdatedAt
+	s.logger.InfoContext(ctx, "taxonomy stored successfully", "revision", taxonomy.Revision)
+	return nil
+}
+
+func taxonomyItemKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: taxonomyKey},
+		"SK": &types.AttributeValueMemberS{Value: taxonomyKey},
+	}
+}
@@ -2,6 +2,8 @@ package dynamodb
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/HatiCode/nestor/catalog/pkg/models"
@@ -14,27 +16,33 @@ type ComponentItem struct {
 	SK string `dynamodbav:"SK"`
 
 	// Component metadata
-	Name              string            `dynamodbav:"Name"`
-	DisplayName       string            `dynamodbav:"DisplayName"`
-	Description       string            `dynamodbav:"Description"`
-	Version           string            `dynamodbav:"Version"`
-	Provider          string            `dynamodbav:"Provider"`
-	Category          string            `dynamodbav:"Category"`
-	SubCategory       string            `dynamodbav:"SubCategory"`
-	ResourceType      string            `dynamodbav:"ResourceType"`
-	DeploymentEngines []string          `dynamodbav:"DeploymentEngines"`
-	Maturity          string            `dynamodbav:"Maturity"`
-	Maintainers       []string          `dynamodbav:"Maintainers"`
-	Documentation     []models.DocLink  `dynamodbav:"Documentation"`
-	CreatedAt         time.Time         `dynamodbav:"CreatedAt"`
-	UpdatedAt         time.Time         `dynamodbav:"UpdatedAt"`
-	DeprecatedAt      *time.Time        `dynamodbav:"DeprecatedAt,omitempty"`
-	GitRepository     string            `dynamodbav:"GitRepository"`
-	GitPath           string            `dynamodbav:"GitPath"`
-	GitCommit         string            `dynamodbav:"GitCommit"`
-	GitBranch         string            `dynamodbav:"GitBranch"`
-	Labels            map[string]string `dynamodbav:"Labels"`
-	Annotations       map[string]string `dynamodbav:"Annotations"`
+	Name              string              `dynamodbav:"Name"`
+	DisplayName       string              `dynamodbav:"DisplayName"`
+	Description       string              `dynamodbav:"Description"`
+	Version           string              `dynamodbav:"Version"`
+	Provider          string              `dynamodbav:"Provider"`
+	Category          string              `dynamodbav:"Category"`
+	SubCategory       string              `dynamodbav:"SubCategory"`
+	ResourceType      string              `dynamodbav:"ResourceType"`
+	DeploymentEngines []string            `dynamodbav:"DeploymentEngines"`
+	Maturity          string              `dynamodbav:"Maturity"`
+	Maintainers       []string            `dynamodbav:"Maintainers"`
+	Documentation     []models.DocLink    `dynamodbav:"Documentation"`
+	CreatedAt         time.Time           `dynamodbav:"CreatedAt"`
+	UpdatedAt         time.Time           `dynamodbav:"UpdatedAt"`
+	Deprecated        bool                `dynamodbav:"Deprecated"`
+	DeprecatedAt      *time.Time          `dynamodbav:"DeprecatedAt,omitempty"`
+	Deprecation       *models.Deprecation `dynamodbav:"Deprecation,omitempty"`
+	GitRepository     string              `dynamodbav:"GitRepository"`
+	GitPath           string              `dynamodbav:"GitPath"`
+	GitCommit         string              `dynamodbav:"GitCommit"`
+	GitBranch         string              `dynamodbav:"GitBranch"`
+	Checksum          string              `dynamodbav:"Checksum,omitempty"`
+	Signatures        []models.Signature  `dynamodbav:"Signatures,omitempty"`
+	PublishedBy       string              `dynamodbav:"PublishedBy,omitempty"`
+	Approvals         []models.Approval   `dynamodbav:"Approvals,omitempty"`
+	Labels            map[string]string   `dynamodbav:"Labels"`
+	Annotations       map[string]string   `dynamodbav:"Annotations"`
 
 	// Component spec
 	Dependencies   []models.Dependency          `dynamodbav:"Dependencies"`
@@ -42,6 +50,7 @@ type ComponentItem struct {
 	ConflictsWith  []string                     `dynamodbav:"ConflictsWith"`
 	RequiredInputs []models.InputSpec           `dynamodbav:"RequiredInputs"`
 	OptionalInputs []models.InputSpec           `dynamodbav:"OptionalInputs"`
+	InputOrder     []string                     `dynamodbav:"InputOrder"`
 	Outputs        []models.OutputSpec          `dynamodbav:"Outputs"`
 	EngineSpecs    map[string]models.EngineSpec `dynamodbav:"EngineSpecs"`
 
@@ -60,48 +69,106 @@ type ComponentItem struct {
 
 // ToComponent converts a DynamoDB item to a Component.
 func (item *ComponentItem) ToComponent() *models.Component {
-	// For MVP, we'll map the complex DynamoDB structure to the simplified Component model
-	// Combine RequiredInputs and OptionalInputs into a single Inputs slice
-	inputs := make([]models.InputSpec, 0, len(item.RequiredInputs)+len(item.OptionalInputs))
-	inputs = append(inputs, item.RequiredInputs...)
-	inputs = append(inputs, item.OptionalInputs...)
-
-	// Create a simplified deployment spec from the first engine spec
-	deployment := models.DeploymentSpec{
-		Engine:  "terraform", // Default for MVP
-		Version: "1.0.0",     // Default for MVP
-		Config:  make(map[string]any),
+	var specs []models.DeploymentSpec
+	for _, engine := range item.orderedEngines() {
+		spec := item.EngineSpecs[engine]
+		specs = append(specs, models.DeploymentSpec{
+			Engine:  engine,
+			Version: spec.Version,
+			Config:  spec.Config,
+		})
 	}
 
-	// If we have engine specs, use the first one
-	if len(item.EngineSpecs) > 0 {
-		for engine, spec := range item.EngineSpecs {
-			deployment.Engine = engine
-			deployment.Version = spec.Version
-			deployment.Config = spec.Config
-			break // Use first engine spec for MVP
+	var deployment models.DeploymentSpec
+	var additional []models.DeploymentSpec
+	if len(specs) > 0 {
+		deployment = specs[0]
+		if len(specs) > 1 {
+			additional = specs[1:]
 		}
 	}
 
 	return &models.Component{
-		Name:        item.Name,
-		Version:     item.Version,
-		Provider:    item.Provider,
-		Category:    item.Category,
-		Description: item.Description,
-		Inputs:      inputs,
-		Outputs:     item.Outputs,
-		Deployment:  deployment,
+		Name:                  item.Name,
+		Version:               item.Version,
+		Provider:              item.Provider,
+		Category:              item.Category,
+		SubCategory:           item.SubCategory,
+		Description:           item.Description,
+		Maintainers:           item.Maintainers,
+		Documentation:         item.Documentation,
+		Labels:                item.Labels,
+		Annotations:           item.Annotations,
+		Inputs:                item.inputs(),
+		Outputs:               item.Outputs,
+		Deployment:            deployment,
+		AdditionalDeployments: additional,
+		Dependencies:          item.Dependencies,
+		Provides:              item.Provides,
+		ConflictsWith:         item.ConflictsWith,
 		Metadata: models.ComponentMetadata{
 			GitCommit:    item.GitCommit,
-			Deprecated:   item.DeprecatedAt != nil,
+			Deprecated:   item.Deprecated || item.DeprecatedAt != nil,
 			DeprecatedAt: item.DeprecatedAt,
+			Deprecation:  item.Deprecation,
+			Checksum:     item.Checksum,
+			Signatures:   item.Signatures,
+			Status:       models.VersionStatus(item.State),
+			PublishedBy:  item.PublishedBy,
+			Approvals:    item.Approvals,
 		},
 		CreatedAt: item.CreatedAt,
 		UpdatedAt: item.UpdatedAt,
 	}
 }
 
+// orderedEngines returns the engines of EngineSpecs in publication order,
+// default engine first. Engines missing from DeploymentEngines, as in items
+// written before it was kept in order, follow alphabetically so the result
+// is at least stable.
+func (item *ComponentItem) orderedEngines() []string {
+	engines := make([]string, 0, len(item.EngineSpecs))
+	seen := make(map[string]bool, len(item.EngineSpecs))
+	for _, engine := range item.DeploymentEngines {
+		if _, ok := item.EngineSpecs[engine]; ok && !seen[engine] {
+			engines = append(engines, engine)
+			seen[engine] = true
+		}
+	}
+
+	for _, engine := range slices.Sorted(maps.Keys(item.EngineSpecs)) {
+		if !seen[engine] {
+			engines = append(engines, engine)
+		}
+	}
+	return engines
+}
+
+// inputs merges required and optional inputs back into declaration order.
+func (item *ComponentItem) inputs() []models.InputSpec {
+	total := len(item.RequiredInputs) + len(item.OptionalInputs)
+	if total == 0 {
+		return nil
+	}
+
+	merged := make([]models.InputSpec, 0, total)
+	merged = append(merged, item.RequiredInputs...)
+	merged = append(merged, item.OptionalInputs...)
+
+	if len(item.InputOrder) != total {
+		return merged
+	}
+
+	position := make(map[string]int, total)
+	for i, name := range item.InputOrder {
+		position[name] = i
+	}
+	slices.SortStableFunc(merged, func(a, b models.InputSpec) int {
+		return position[a.Name] - position[b.Name]
+	})
+	return merged
+}
+
 // NewComponentItemFromComponent creates a new ComponentItem from a Component.
 func NewComponentItemFromComponent(component *models.Component) *ComponentItem {
 	if component == nil {
@@ -114,59 +181,75 @@ func NewComponentItemFromComponent(component *models.Component) *ComponentItem {
 	}
 	component.UpdatedAt = now
 
-	// For MVP, split inputs into required and optional based on validation rules
+	return newComponentItem(component)
+}
+
+// newComponentItem maps a Component to a ComponentItem as is, without
+// touching its timestamps.
+func newComponentItem(component *models.Component) *ComponentItem {
+	// Inputs are split by requirement for querying; InputOrder keeps the
+	// declaration order so ToComponent can restore it.
 	var requiredInputs, optionalInputs []models.InputSpec
+	inputOrder := make([]string, 0, len(component.Inputs))
 	for _, input := range component.Inputs {
 		if input.Validation.Required {
 			requiredInputs = append(requiredInputs, input)
 		} else {
 			optionalInputs = append(optionalInputs, input)
 		}
+		inputOrder = append(inputOrder, input.Name)
 	}
 
-	// Create engine specs from deployment spec
+	// One engine spec per engine; DeploymentEngines keeps their order
 	engineSpecs := make(map[string]models.EngineSpec)
-	engineSpecs[component.Deployment.Engine] = models.EngineSpec{
-		Engine:  component.Deployment.Engine,
-		Version: component.Deployment.Version,
-		Config:  component.Deployment.Config,
+	for _, spec := range component.DeploymentSpecs() {
+		engineSpecs[spec.Engine] = models.EngineSpec{
+			Engine:  spec.Engine,
+			Version: spec.Version,
+			Config:  spec.Config,
+		}
 	}
 
 	item := &ComponentItem{
-		// Component metadata - map from simplified model
 		Name:              component.Name,
 		DisplayName:       component.Name, // Use name as display name for MVP
 		Description:       component.Description,
 		Version:           component.Version,
 		Provider:          component.Provider,
 		Category:          component.Category,
-		SubCategory:       "",                                    // Not in MVP model
-		ResourceType:      "infrastructure",                      // Default for MVP
-		DeploymentEngines: []string{component.Deployment.Engine}, // Single engine for MVP
-		Maturity:          "stable",                              // Default for MVP
-		Maintainers:       []string{},                            // Empty for MVP
-		Documentation:     []models.DocLink{},                    // Empty for MVP
+		SubCategory:       component.SubCategory,
+		ResourceType:      "infrastructure", // Default for MVP
+		DeploymentEngines: component.Engines(),
+		Maturity:          "stable", // Default for MVP
+		Maintainers:       component.Maintainers,
+		Documentation:     component.Documentation,
 		CreatedAt:         component.CreatedAt,
 		UpdatedAt:         component.UpdatedAt,
+		Deprecated:        component.Metadata.Deprecated,
 		DeprecatedAt:      component.Metadata.DeprecatedAt,
+		Deprecation:       component.Metadata.Deprecation,
 		GitRepository:     "", // Not in MVP model
 		GitPath:           "", // Not in MVP model
 		GitCommit:         component.Metadata.GitCommit,
-		GitBranch:         "",                      // Not in MVP model
-		Labels:            make(map[string]string), // Empty for MVP
-		Annotations:       make(map[string]string), // Empty for MVP
-
-		// Component spec - map from simplified model
-		Dependencies:   []models.Dependency{}, // Empty for MVP
-		Provides:       []string{},            // Empty for MVP
-		ConflictsWith:  []string{},            // Empty for MVP
+		GitBranch:         "", // Not in MVP model
+		Checksum:          component.Metadata.Checksum,
+		Signatures:        component.Metadata.Signatures,
+		PublishedBy:       component.Metadata.PublishedBy,
+		Approvals:         component.Metadata.Approvals,
+		Labels:            component.Labels,
+		Annotations:       component.Annotations,
+
+		Dependencies:   component.Dependencies,
+		Provides:       component.Provides,
+		ConflictsWith:  component.ConflictsWith,
 		RequiredInputs: requiredInputs,
 		OptionalInputs: optionalInputs,
+		InputOrder:     inputOrder,
 		Outputs:        component.Outputs,
 		EngineSpecs:    engineSpecs,
 
 		// Component status - defaults for MVP
-		State:            "active",                // Default for MVP
+		State:            string(component.Status()),
 		UsageCount:       0,                       // Default for MVP
 		LastUsed:         nil,                     // Default for MVP
 		ValidationStatus: "valid",                 // Default for MVP
@@ -0,0 +1,142 @@
+package dynamodb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+func fullComponent() *models.Component {
+	created := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	deprecated := created.Add(48 * time.Hour)
+	sunset := created.Add(90 * 24 * time.Hour)
+	minLength := 3
+
+	return &models.Component{
+		Name:        "postgres",
+		Version:     "2.1.0",
+		Provider:    "aws",
+		Category:    "database",
+		SubCategory: "relational",
+		Description: "Managed PostgreSQL",
+		Maintainers: []string{"platform@example.com"},
+		Documentation: []models.DocLink{
+			{Title: "Runbook", URL: "https://docs.example.com/postgres", Type: "runbook"},
+		},
+		Labels:      map[string]string{"team": "platform"},
+		Annotations: map[string]string{"nestor.io/slack": "#platform"},
+		Inputs: []models.InputSpec{
+			{Name: "instance_class", Type: "string", Description: "Instance class", Default: "db.t3.micro"},
+			{Name: "db_name", Type: "string", Description: "Database name", Validation: models.Validation{Required: true, MinLength: &minLength}},
+			{Name: "storage_gb", Type: "number", Description: "Allocated storage", Default: 20.0},
+		},
+		Outputs: []models.OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Endpoint"},
+		},
+		Deployment: models.DeploymentSpec{
+			Engine:  "crossplane",
+			Version: "1.14.0",
+			Config:  map[string]any{"apiVersion": "database.example.org/v1alpha1", "kind": "XPostgres", "compositionRef": map[string]any{"name": "xpostgres"}},
+		},
+		AdditionalDeployments: []models.DeploymentSpec{
+			{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/postgres", "module_version": "1.0.0"}},
+			{Engine: "pulumi", Version: "3.0.0"},
+		},
+		Dependencies: []models.Dependency{
+			{Name: "vpc", Type: "component", Version: "^1.2.0", Condition: "inputs.private"},
+		},
+		Provides:      []string{"postgres-database"},
+		ConflictsWith: []string{"legacy-postgres"},
+		Metadata: models.ComponentMetadata{
+			GitCommit:    "abc123",
+			Deprecated:   true,
+			DeprecatedAt: &deprecated,
+			Deprecation: &models.Deprecation{
+				Reason:      "Replaced by Aurora",
+				Replacement: &models.Replacement{Name: "aurora", Version: "^1.0.0"},
+				SunsetAt:    &sunset,
+			},
+			Checksum:    "sha256:0000",
+			Signatures:  []models.Signature{{KeyID: "platform-2024", Publisher: "platform-team", Value: "c2lnbmF0dXJl"}},
+			Status:      models.VersionStatusActive,
+			PublishedBy: "alice@example.com",
+			Approvals:   []models.Approval{{By: "bob@example.com", At: created, Checksum: "sha256:0000"}},
+		},
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+	}
+}
+
+func TestComponentItem_RoundTripIsLossless(t *testing.T) {
+	component := fullComponent()
+
+	av, err := attributevalue.MarshalMap(newComponentItem(component))
+	require.NoError(t, err)
+
+	var item ComponentItem
+	require.NoError(t, attributevalue.UnmarshalMap(av, &item))
+
+	assert.Equal(t, component, item.ToComponent())
+}
+
+func TestComponentItem_RoundTripKeepsChecksumValid(t *testing.T) {
+	component := fullComponent()
+	component.Inputs[2].Default = 20
+	require.NoError(t, component.SetChecksum())
+
+	av, err := attributevalue.MarshalMap(newComponentItem(component))
+	require.NoError(t, err)
+
+	var item ComponentItem
+	require.NoError(t, attributevalue.UnmarshalMap(av, &item))
+
+	restored := item.ToComponent()
+	assert.Equal(t, component.Metadata.Checksum, restored.Metadata.Checksum)
+	assert.NoError(t, restored.VerifyChecksum())
+}
+
+func TestComponentItem_KeepsDeprecatedFlagWithoutDate(t *testing.T) {
+	component := fullComponent()
+	component.Metadata.DeprecatedAt = nil
+
+	restored := newComponentItem(component).ToComponent()
+	assert.True(t, restored.Metadata.Deprecated)
+}
+
+func TestComponentItem_PrimaryEngine(t *testing.T) {
+	item := &ComponentItem{
+		DeploymentEngines: []string{"terraform"},
+		EngineSpecs: map[string]models.EngineSpec{
+			"pulumi":    {Engine: "pulumi", Version: "3.0.0"},
+			"terraform": {Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+		},
+	}
+	for range 20 {
+		component := item.ToComponent()
+		assert.Equal(t, "terraform", component.Deployment.Engine)
+		assert.Equal(t, []string{"terraform", "pulumi"}, component.Engines())
+	}
+
+	item.DeploymentEngines = nil
+	assert.Equal(t, []string{"pulumi", "terraform"}, item.ToComponent().Engines(), "legacy items fall back to engine names in order")
+
+	item.EngineSpecs = nil
+	assert.Equal(t, models.DeploymentSpec{}, item.ToComponent().Deployment, "no engine must not invent one")
+}
+
+func TestComponentItem_LegacyInputOrder(t *testing.T) {
+	item := &ComponentItem{
+		RequiredInputs: []models.InputSpec{{Name: "b"}},
+		OptionalInputs: []models.InputSpec{{Name: "a"}},
+	}
+
+	inputs := item.ToComponent().Inputs
+	require.Len(t, inputs, 2)
+	assert.Equal(t, "b", inputs[0].Name)
+	assert.Equal(t, "a", inputs[1].Name)
+}
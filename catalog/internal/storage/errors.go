@@ -1,7 +1,35 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// Stable error codes. These are part of the public contract of the catalog
+// and must not change once released; add new codes instead.
+const (
+	CodeResourceNotFound       = "RESOURCE_NOT_FOUND"
+	CodeComponentNotFound      = "COMPONENT_NOT_FOUND"
+	CodeVersionNotFound        = "VERSION_NOT_FOUND"
+	CodeResourceExists         = "RESOURCE_EXISTS"
+	CodeComponentExists        = "COMPONENT_EXISTS"
+	CodeValidation             = "VALIDATION_ERROR"
+	CodeStorageUnavailable     = "STORAGE_UNAVAILABLE"
+	CodeThrottled              = "THROTTLED"
+	CodeConfiguration          = "CONFIGURATION_ERROR"
+	CodeInvalidVersion         = "INVALID_VERSION"
+	CodeInvalidDateRange       = "INVALID_DATE_RANGE"
+	CodeEmptySearchQuery       = "EMPTY_SEARCH_QUERY"
+	CodeUnsupportedStorageType = "UNSUPPORTED_STORAGE_TYPE"
+	CodeStorageNotAvailable    = "STORAGE_NOT_AVAILABLE"
+	CodeInvalidInput           = "INVALID_INPUT"
+	CodeInvalidConfig          = "INVALID_CONFIG"
+	CodeConflict               = "CONFLICT"
+	CodeIntegrity              = "INTEGRITY_ERROR"
 )
 
 // StorageError is the base error type for all storage-related error.
@@ -9,19 +37,40 @@ type StorageError struct {
 	Code    string         `json:"code"`
 	Message string         `json:"message"`
 	Details map[string]any `json:"details,omitempty"`
+	Cause   error          `json:"-"`
 }
 
 func (e *StorageError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying cause, if any.
+func (e *StorageError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is a StorageError carrying the same code.
+func (e *StorageError) Is(target error) bool {
+	return hasCode(target, e.Code)
+}
+
 // WithDetail adds a detail to the error.
 func (e *StorageError) WithDetail(key string, value any) *StorageError {
+	e.setDetail(key, value)
+	return e
+}
+
+// WithCause records the error that caused this one.
+func (e *StorageError) WithCause(cause error) *StorageError {
+	e.Cause = cause
+	return e
+}
+
+func (e *StorageError) setDetail(key string, value any) {
 	if e.Details == nil {
 		e.Details = make(map[string]any)
 	}
 	e.Details[key] = value
-	return e
 }
 
 // NewStorageError creates a new StorageError.
@@ -33,6 +82,12 @@ func NewStorageError(code, message string) *StorageError {
 	}
 }
 
+// hasCode reports whether target is a StorageError with the given code.
+func hasCode(target error, code string) bool {
+	t, ok := target.(*StorageError)
+	return ok && t.Code != "" && t.Code == code
+}
+
 // ResourceNotFoundError indicates a requested resource was not found.
 type ResourceNotFoundError struct {
 	*StorageError
@@ -43,7 +98,7 @@ type ResourceNotFoundError struct {
 func NewResourceNotFoundError(resourceType, resourceID string) *ResourceNotFoundError {
 	return &ResourceNotFoundError{
 		StorageError: NewStorageError(
-			"RESOURCE_NOT_FOUND",
+			CodeResourceNotFound,
 			fmt.Sprintf("%s not found: %s", resourceType, resourceID),
 		),
 		ResourceType: resourceType,
@@ -51,6 +106,26 @@ func NewResourceNotFoundError(resourceType, resourceID string) *ResourceNotFound
 	}
 }
 
+// Is matches ErrResourceNotFound, and models.ErrNotFound so that callers
+// outside the catalog recognise missing components.
+func (e *ResourceNotFoundError) Is(target error) bool {
+	return target == models.ErrNotFound || hasCode(target, CodeResourceNotFound)
+}
+
+func (e *ResourceNotFoundError) Unwrap() error {
+	return e.StorageError
+}
+
+func (e *ResourceNotFoundError) WithDetail(key string, value any) *ResourceNotFoundError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ResourceNotFoundError) WithCause(cause error) *ResourceNotFoundError {
+	e.Cause = cause
+	return e
+}
+
 // ComponentNotFoundError is a specific type of ResourceNotFoundError.
 type ComponentNotFoundError struct {
 	*ResourceNotFoundError
@@ -63,13 +138,33 @@ func NewComponentNotFoundError(name, version string) *ComponentNotFoundError {
 	if version != "" {
 		id = fmt.Sprintf("%s:%s", name, version)
 	}
+	base := NewResourceNotFoundError("component", id)
+	base.Code = CodeComponentNotFound
 	return &ComponentNotFoundError{
-		ResourceNotFoundError: NewResourceNotFoundError("component", id),
+		ResourceNotFoundError: base,
 		Name:                  name,
 		Version:               version,
 	}
 }
 
+func (e *ComponentNotFoundError) Is(target error) bool {
+	return hasCode(target, CodeComponentNotFound)
+}
+
+func (e *ComponentNotFoundError) Unwrap() error {
+	return e.ResourceNotFoundError
+}
+
+func (e *ComponentNotFoundError) WithDetail(key string, value any) *ComponentNotFoundError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ComponentNotFoundError) WithCause(cause error) *ComponentNotFoundError {
+	e.Cause = cause
+	return e
+}
+
 // VersionNotFoundError is a specific type of ResourceNotFoundError.
 type VersionNotFoundError struct {
 	*ResourceNotFoundError
@@ -78,16 +173,36 @@ type VersionNotFoundError struct {
 }
 
 func NewVersionNotFoundError(componentName, version string) *VersionNotFoundError {
+	base := NewResourceNotFoundError(
+		"version",
+		fmt.Sprintf("%s:%s", componentName, version),
+	)
+	base.Code = CodeVersionNotFound
 	return &VersionNotFoundError{
-		ResourceNotFoundError: NewResourceNotFoundError(
-			"version",
-			fmt.Sprintf("%s:%s", componentName, version),
-		),
-		ComponentName: componentName,
-		Version:       version,
+		ResourceNotFoundError: base,
+		ComponentName:         componentName,
+		Version:               version,
 	}
 }
 
+func (e *VersionNotFoundError) Is(target error) bool {
+	return hasCode(target, CodeVersionNotFound)
+}
+
+func (e *VersionNotFoundError) Unwrap() error {
+	return e.ResourceNotFoundError
+}
+
+func (e *VersionNotFoundError) WithDetail(key string, value any) *VersionNotFoundError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *VersionNotFoundError) WithCause(cause error) *VersionNotFoundError {
+	e.Cause = cause
+	return e
+}
+
 // ResourceExistsError indicates a resource already exists.
 type ResourceExistsError struct {
 	*StorageError
@@ -98,7 +213,7 @@ type ResourceExistsError struct {
 func NewResourceExistsError(resourceType, resourceID string) *ResourceExistsError {
 	return &ResourceExistsError{
 		StorageError: NewStorageError(
-			"RESOURCE_EXISTS",
+			CodeResourceExists,
 			fmt.Sprintf("%s already exists: %s", resourceType, resourceID),
 		),
 		ResourceType: resourceType,
@@ -106,6 +221,69 @@ func NewResourceExistsError(resourceType, resourceID string) *ResourceExistsErro
 	}
 }
 
+func (e *ResourceExistsError) Is(target error) bool {
+	return hasCode(target, CodeResourceExists)
+}
+
+func (e *ResourceExistsError) Unwrap() error {
+	return e.StorageError
+}
+
+func (e *ResourceExistsError) WithDetail(key string, value any) *ResourceExistsError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ResourceExistsError) WithCause(cause error) *ResourceExistsError {
+	e.Cause = cause
+	return e
+}
+
+// ConflictError indicates a write that conflicts with the current state of a
+// resource, such as a stale revision or the removal of something in use.
+type ConflictError struct {
+	*StorageError
+	ResourceType string
+	Reason       string
+}
+
+func NewConflictError(resourceType, reason string) *ConflictError {
+	return &ConflictError{
+		StorageError: NewStorageError(
+			CodeConflict,
+			fmt.Sprintf("conflict on %s: %s", resourceType, reason),
+		),
+		ResourceType: resourceType,
+		Reason:       reason,
+	}
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return hasCode(target, CodeConflict)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.StorageError
+}
+
+func (e *ConflictError) WithDetail(key string, value any) *ConflictError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ConflictError) WithCause(cause error) *ConflictError {
+	e.Cause = cause
+	return e
+}
+
+// NewIntegrityError reports a stored component that no longer matches the
+// checksum it was published with.
+func NewIntegrityError(id string, cause error) *StorageError {
+	return NewStorageError(CodeIntegrity, fmt.Sprintf("component %s failed its integrity check", id)).
+		WithDetail("component", id).
+		WithCause(cause)
+}
+
 // ComponentExistsError is a specific type of ResourceExistsError.
 type ComponentExistsError struct {
 	*ResourceExistsError
@@ -114,16 +292,36 @@ type ComponentExistsError struct {
 }
 
 func NewComponentExistsError(name, version string) *ComponentExistsError {
+	base := NewResourceExistsError(
+		"component",
+		fmt.Sprintf("%s:%s", name, version),
+	)
+	base.Code = CodeComponentExists
 	return &ComponentExistsError{
-		ResourceExistsError: NewResourceExistsError(
-			"component",
-			fmt.Sprintf("%s:%s", name, version),
-		),
-		Name:    name,
-		Version: version,
+		ResourceExistsError: base,
+		Name:                name,
+		Version:             version,
 	}
 }
 
+func (e *ComponentExistsError) Is(target error) bool {
+	return hasCode(target, CodeComponentExists)
+}
+
+func (e *ComponentExistsError) Unwrap() error {
+	return e.ResourceExistsError
+}
+
+func (e *ComponentExistsError) WithDetail(key string, value any) *ComponentExistsError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ComponentExistsError) WithCause(cause error) *ComponentExistsError {
+	e.Cause = cause
+	return e
+}
+
 // ValidationError indicates invalid input.
 type ValidationError struct {
 	*StorageError
@@ -134,7 +332,7 @@ type ValidationError struct {
 func NewValidationError(field, reason string) *ValidationError {
 	return &ValidationError{
 		StorageError: NewStorageError(
-			"VALIDATION_ERROR",
+			CodeValidation,
 			fmt.Sprintf("validation error for %s: %s", field, reason),
 		),
 		Field:  field,
@@ -142,6 +340,38 @@ func NewValidationError(field, reason string) *ValidationError {
 	}
 }
 
+// Is also matches ErrInvalidInput, which predates the typed error.
+func (e *ValidationError) Is(target error) bool {
+	return hasCode(target, CodeValidation) || hasCode(target, CodeInvalidInput)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.StorageError
+}
+
+func (e *ValidationError) WithDetail(key string, value any) *ValidationError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ValidationError) WithCause(cause error) *ValidationError {
+	e.Cause = cause
+	return e
+}
+
+// NewComponentValidationError wraps the error returned by validating a
+// component. When err is a models.ValidationErrors, its issues are listed in
+// the "errors" detail so that clients can fix them all at once.
+func NewComponentValidationError(err error) *ValidationError {
+	var issues models.ValidationErrors
+	if !errors.As(err, &issues) {
+		return NewValidationError("component", err.Error()).WithCause(err)
+	}
+	return NewValidationError("component", issues.Summary()).
+		WithDetail("errors", issues).
+		WithCause(err)
+}
+
 // StorageUnavailableError indicates the storage backend is unavailable.
 type StorageUnavailableError struct {
 	*StorageError
@@ -151,13 +381,32 @@ type StorageUnavailableError struct {
 func NewStorageUnavailableError(reason string) *StorageUnavailableError {
 	return &StorageUnavailableError{
 		StorageError: NewStorageError(
-			"STORAGE_UNAVAILABLE",
+			CodeStorageUnavailable,
 			fmt.Sprintf("storage backend unavailable: %s", reason),
 		),
 		Reason: reason,
 	}
 }
 
+// Is also matches ErrStorageNotAvailable, which predates the typed error.
+func (e *StorageUnavailableError) Is(target error) bool {
+	return hasCode(target, CodeStorageUnavailable) || hasCode(target, CodeStorageNotAvailable)
+}
+
+func (e *StorageUnavailableError) Unwrap() error {
+	return e.StorageError
+}
+
+func (e *StorageUnavailableError) WithDetail(key string, value any) *StorageUnavailableError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *StorageUnavailableError) WithCause(cause error) *StorageUnavailableError {
+	e.Cause = cause
+	return e
+}
+
 // ThrottledError indicates the request was throttled.
 type ThrottledError struct {
 	*StorageUnavailableError
@@ -167,7 +416,7 @@ func NewThrottledError(reason string) *ThrottledError {
 	return &ThrottledError{
 		StorageUnavailableError: &StorageUnavailableError{
 			StorageError: NewStorageError(
-				"THROTTLED",
+				CodeThrottled,
 				fmt.Sprintf("request throttled: %s", reason),
 			),
 			Reason: reason,
@@ -175,6 +424,24 @@ func NewThrottledError(reason string) *ThrottledError {
 	}
 }
 
+func (e *ThrottledError) Is(target error) bool {
+	return hasCode(target, CodeThrottled)
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.StorageUnavailableError
+}
+
+func (e *ThrottledError) WithDetail(key string, value any) *ThrottledError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ThrottledError) WithCause(cause error) *ThrottledError {
+	e.Cause = cause
+	return e
+}
+
 // ConfigurationError indicates an invalid configuration.
 type ConfigurationError struct {
 	*StorageError
@@ -184,23 +451,133 @@ type ConfigurationError struct {
 func NewConfigurationError(configItem, reason string) *ConfigurationError {
 	return &ConfigurationError{
 		StorageError: NewStorageError(
-			"CONFIGURATION_ERROR",
+			CodeConfiguration,
 			fmt.Sprintf("invalid configuration for %s: %s", configItem, reason),
 		),
 		ConfigItem: configItem,
 	}
 }
 
-// Common error instances for convenience.
+// Is also matches ErrInvalidConfig, which predates the typed error.
+func (e *ConfigurationError) Is(target error) bool {
+	return hasCode(target, CodeConfiguration) || hasCode(target, CodeInvalidConfig)
+}
+
+func (e *ConfigurationError) Unwrap() error {
+	return e.StorageError
+}
+
+func (e *ConfigurationError) WithDetail(key string, value any) *ConfigurationError {
+	e.setDetail(key, value)
+	return e
+}
+
+func (e *ConfigurationError) WithCause(cause error) *ConfigurationError {
+	e.Cause = cause
+	return e
+}
+
+// Common error instances for convenience. They are meant to be matched with
+// errors.Is and must not be returned directly when a typed error exists.
 var (
-	ErrComponentNotFound      = NewStorageError("COMPONENT_NOT_FOUND", "component not found")
-	ErrVersionNotFound        = NewStorageError("VERSION_NOT_FOUND", "component version not found")
-	ErrComponentExists        = NewStorageError("COMPONENT_EXISTS", "component already exists")
-	ErrInvalidVersion         = NewStorageError("INVALID_VERSION", "invalid semantic version")
-	ErrInvalidDateRange       = NewStorageError("INVALID_DATE_RANGE", "start date must be before end date")
-	ErrEmptySearchQuery       = NewStorageError("EMPTY_SEARCH_QUERY", "search query cannot be empty")
-	ErrUnsupportedStorageType = NewStorageError("UNSUPPORTED_STORAGE_TYPE", "unsupported storage type")
-	ErrStorageNotAvailable    = NewStorageError("STORAGE_NOT_AVAILABLE", "storage backend not available")
-	ErrInvalidInput           = NewStorageError("INVALID_INPUT", "invalid input provided")
-	ErrInvalidConfig          = NewStorageError("INVALID_CONFIG", "invalid storage configuration")
+	ErrResourceNotFound       = NewStorageError(CodeResourceNotFound, "resource not found")
+	ErrComponentNotFound      = NewStorageError(CodeComponentNotFound, "component not found")
+	ErrVersionNotFound        = NewStorageError(CodeVersionNotFound, "component version not found")
+	ErrResourceExists         = NewStorageError(CodeResourceExists, "resource already exists")
+	ErrComponentExists        = NewStorageError(CodeComponentExists, "component already exists")
+	ErrValidation             = NewStorageError(CodeValidation, "validation failed")
+	ErrStorageUnavailable     = NewStorageError(CodeStorageUnavailable, "storage backend unavailable")
+	ErrThrottled              = NewStorageError(CodeThrottled, "request throttled")
+	ErrConfiguration          = NewStorageError(CodeConfiguration, "invalid configuration")
+	ErrInvalidVersion         = NewStorageError(CodeInvalidVersion, "invalid semantic version")
+	ErrInvalidDateRange       = NewStorageError(CodeInvalidDateRange, "start date must be before end date")
+	ErrEmptySearchQuery       = NewStorageError(CodeEmptySearchQuery, "search query cannot be empty")
+	ErrUnsupportedStorageType = NewStorageError(CodeUnsupportedStorageType, "unsupported storage type")
+	ErrStorageNotAvailable    = NewStorageError(CodeStorageNotAvailable, "storage backend not available")
+	ErrInvalidInput           = NewStorageError(CodeInvalidInput, "invalid input provided")
+	ErrInvalidConfig          = NewStorageError(CodeInvalidConfig, "invalid storage configuration")
+	ErrConflict               = NewStorageError(CodeConflict, "conflicting update")
+	ErrIntegrity              = NewStorageError(CodeIntegrity, "integrity check failed")
 )
+
+// GRPCCode mirrors the numeric values of google.golang.org/grpc/codes so the
+// storage package can be mapped to gRPC without depending on it.
+type GRPCCode uint32
+
+const (
+	GRPCOK                 GRPCCode = 0
+	GRPCCanceled           GRPCCode = 1
+	GRPCUnknown            GRPCCode = 2
+	GRPCInvalidArgument    GRPCCode = 3
+	GRPCDeadlineExceeded   GRPCCode = 4
+	GRPCNotFound           GRPCCode = 5
+	GRPCAlreadyExists      GRPCCode = 6
+	GRPCPermissionDenied   GRPCCode = 7
+	GRPCResourceExhausted  GRPCCode = 8
+	GRPCFailedPrecondition GRPCCode = 9
+	GRPCAborted            GRPCCode = 10
+	GRPCOutOfRange         GRPCCode = 11
+	GRPCUnimplemented      GRPCCode = 12
+	GRPCInternal           GRPCCode = 13
+	GRPCUnavailable        GRPCCode = 14
+	GRPCDataLoss           GRPCCode = 15
+	GRPCUnauthenticated    GRPCCode = 16
+)
+
+// statusClientClosedRequest is the de facto status for requests the client
+// abandoned; net/http has no constant for it.
+const statusClientClosedRequest = 499
+
+// errorStatus maps a sentinel to its transport status codes.
+type errorStatus struct {
+	target error
+	http   int
+	grpc   GRPCCode
+}
+
+// errorStatuses is ordered from most to least specific; the first match wins.
+var errorStatuses = []errorStatus{
+	{ErrResourceNotFound, http.StatusNotFound, GRPCNotFound},
+	{ErrResourceExists, http.StatusConflict, GRPCAlreadyExists},
+	{ErrConflict, http.StatusConflict, GRPCAborted},
+	{ErrValidation, http.StatusBadRequest, GRPCInvalidArgument},
+	{ErrInvalidInput, http.StatusBadRequest, GRPCInvalidArgument},
+	{ErrInvalidVersion, http.StatusBadRequest, GRPCInvalidArgument},
+	{ErrInvalidDateRange, http.StatusBadRequest, GRPCInvalidArgument},
+	{ErrEmptySearchQuery, http.StatusBadRequest, GRPCInvalidArgument},
+	{ErrThrottled, http.StatusTooManyRequests, GRPCResourceExhausted},
+	{ErrStorageUnavailable, http.StatusServiceUnavailable, GRPCUnavailable},
+	{ErrStorageNotAvailable, http.StatusServiceUnavailable, GRPCUnavailable},
+	{ErrConfiguration, http.StatusInternalServerError, GRPCInternal},
+	{ErrInvalidConfig, http.StatusInternalServerError, GRPCInternal},
+	{ErrUnsupportedStorageType, http.StatusInternalServerError, GRPCInternal},
+	{ErrIntegrity, http.StatusInternalServerError, GRPCDataLoss},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, GRPCDeadlineExceeded},
+	{context.Canceled, statusClientClosedRequest, GRPCCanceled},
+}
+
+// StatusOf maps any error returned by the storage layer to an HTTP status and
+// a gRPC code. Unknown errors map to 500 / Internal.
+func StatusOf(err error) (int, GRPCCode) {
+	if err == nil {
+		return http.StatusOK, GRPCOK
+	}
+
+	for _, s := range errorStatuses {
+		if errors.Is(err, s.target) {
+			return s.http, s.grpc
+		}
+	}
+
+	return http.StatusInternalServerError, GRPCInternal
+}
+
+// ErrorCode returns the stable code of the outermost StorageError in err's
+// chain, or an empty string if there is none.
+func ErrorCode(err error) string {
+	var storageErr *StorageError
+	if errors.As(err, &storageErr) {
+		return storageErr.Code
+	}
+	return ""
+}
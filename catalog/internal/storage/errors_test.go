@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors_IsMatchesSentinels(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		matches []error
+		misses  []error
+	}{
+		{
+			name:    "component not found",
+			err:     NewComponentNotFoundError("vpc", "1.0.0").WithDetail("operation", "GetComponent"),
+			matches: []error{ErrComponentNotFound, ErrResourceNotFound, models.ErrNotFound},
+			misses:  []error{ErrVersionNotFound, ErrComponentExists, ErrValidation},
+		},
+		{
+			name:    "version not found",
+			err:     NewVersionNotFoundError("vpc", "1.0.0"),
+			matches: []error{ErrVersionNotFound, ErrResourceNotFound, models.ErrNotFound},
+			misses:  []error{ErrComponentNotFound},
+		},
+		{
+			name:    "component exists",
+			err:     NewComponentExistsError("vpc", "1.0.0"),
+			matches: []error{ErrComponentExists, ErrResourceExists},
+			misses:  []error{ErrResourceNotFound, models.ErrNotFound},
+		},
+		{
+			name:    "validation",
+			err:     NewValidationError("name", "required"),
+			matches: []error{ErrValidation, ErrInvalidInput},
+			misses:  []error{ErrInvalidConfig},
+		},
+		{
+			name:    "throttled",
+			err:     NewThrottledError("slow down"),
+			matches: []error{ErrThrottled, ErrStorageUnavailable, ErrStorageNotAvailable},
+			misses:  []error{ErrValidation},
+		},
+		{
+			name:    "configuration",
+			err:     NewConfigurationError("region", "required"),
+			matches: []error{ErrConfiguration, ErrInvalidConfig},
+			misses:  []error{ErrStorageUnavailable},
+		},
+		{
+			name:    "wrapped with fmt.Errorf",
+			err:     fmt.Errorf("listing: %w", NewComponentNotFoundError("vpc", "")),
+			matches: []error{ErrComponentNotFound, ErrResourceNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, target := range tt.matches {
+				assert.ErrorIs(t, tt.err, target)
+			}
+			for _, target := range tt.misses {
+				assert.NotErrorIs(t, tt.err, target)
+			}
+		})
+	}
+}
+
+func TestErrors_AsWalksHierarchy(t *testing.T) {
+	var err error = NewComponentNotFoundError("vpc", "1.0.0").WithDetail("operation", "GetComponent")
+
+	var notFound *ComponentNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "vpc", notFound.Name)
+	assert.Equal(t, "GetComponent", notFound.Details["operation"])
+
+	var resource *ResourceNotFoundError
+	require.ErrorAs(t, err, &resource)
+	assert.Equal(t, "component", resource.ResourceType)
+
+	var base *StorageError
+	require.ErrorAs(t, err, &base)
+	assert.Equal(t, CodeComponentNotFound, base.Code)
+}
+
+func TestErrors_CausePreserved(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := NewStorageUnavailableError(cause.Error()).WithCause(cause)
+
+	assert.ErrorIs(t, err, cause)
+	assert.ErrorIs(t, err, ErrStorageUnavailable)
+	assert.Equal(t, "storage backend unavailable: connection reset", err.Error())
+}
+
+func TestStatusOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantHTTP int
+		wantGRPC GRPCCode
+	}{
+		{"nil", nil, http.StatusOK, GRPCOK},
+		{"component not found", NewComponentNotFoundError("vpc", "1.0.0"), http.StatusNotFound, GRPCNotFound},
+		{"version not found", NewVersionNotFoundError("vpc", "1.0.0"), http.StatusNotFound, GRPCNotFound},
+		{"exists", NewComponentExistsError("vpc", "1.0.0"), http.StatusConflict, GRPCAlreadyExists},
+		{"validation", NewValidationError("name", "required"), http.StatusBadRequest, GRPCInvalidArgument},
+		{"conflict", NewConflictError("taxonomy", "stale revision"), http.StatusConflict, GRPCAborted},
+		{"date range sentinel", fmt.Errorf("invalid filters: %w", ErrInvalidDateRange), http.StatusBadRequest, GRPCInvalidArgument},
+		{"throttled", NewThrottledError("slow down"), http.StatusTooManyRequests, GRPCResourceExhausted},
+		{"unavailable", NewStorageUnavailableError("down"), http.StatusServiceUnavailable, GRPCUnavailable},
+		{"configuration", NewConfigurationError("region", "required"), http.StatusInternalServerError, GRPCInternal},
+		{"integrity", NewIntegrityError("vpc:1.0.0", errors.New("mismatch")), http.StatusInternalServerError, GRPCDataLoss},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, GRPCDeadlineExceeded},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, GRPCInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := StatusOf(tt.err)
+			assert.Equal(t, tt.wantHTTP, status)
+			assert.Equal(t, tt.wantGRPC, code)
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, CodeThrottled, ErrorCode(fmt.Errorf("scan: %w", NewThrottledError("slow down"))))
+	assert.Equal(t, "", ErrorCode(errors.New("boom")))
+}
+
+func TestNewComponentValidationError(t *testing.T) {
+	issues := models.ValidationErrors{
+		{Path: "inputs[0].type", Rule: models.RuleRequired, Message: "input 'a' is missing type", Severity: models.SeverityError},
+		{Path: "deployment.engine", Rule: models.RuleRequired, Message: "deployment engine is required", Severity: models.SeverityError},
+	}
+
+	err := NewComponentValidationError(fmt.Errorf("storing: %w", issues))
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, issues, err.Details["errors"])
+
+	var unwrapped models.ValidationErrors
+	require.ErrorAs(t, err, &unwrapped)
+	assert.Equal(t, issues, unwrapped)
+
+	plain := NewComponentValidationError(errors.New("component cannot be nil"))
+	assert.NotContains(t, plain.Details, "errors")
+}
@@ -13,6 +13,25 @@ import (
 type StorageConfig struct {
 	Type     string                 `yaml:"type" validate:"required,oneof=dynamodb memory postgres"`
 	DynamoDB *DynamoDBStorageConfig `yaml:"dynamodb,omitempty"`
+	// DraftRetention is how long drafts are kept without being promoted or
+	// edited, such as "720h". DefaultDraftRetention applies when empty.
+	DraftRetention string `yaml:"draft_retention,omitempty"`
+	// Policies lists the policy files and directories evaluated before
+	// versions are stored as drafts and before they are promoted.
+	Policies []string `yaml:"policies,omitempty"`
+	// Signing verifies the signatures of the versions published, when set.
+	Signing *SigningStorageConfig `yaml:"signing,omitempty"`
+	// Checks are run after the policies and signatures. They can only be set
+	// in code.
+	Checks []PublishCheck `yaml:"-"`
+}
+
+// SigningStorageConfig configures the verification of component signatures.
+type SigningStorageConfig struct {
+	// Keys is the path of the file listing the trusted keys.
+	Keys string `yaml:"keys"`
+	// Required rejects unsigned versions.
+	Required bool `yaml:"required"`
 }
 
 // DynamoDBStorageConfig contains DynamoDB-specific configuration.
@@ -38,6 +57,20 @@ func (c *StorageConfig) Validate() error {
 		return NewConfigurationError("type", "storage type is required")
 	}
 
+	if c.DraftRetention != "" {
+		retention, err := time.ParseDuration(c.DraftRetention)
+		if err != nil {
+			return NewConfigurationError("draft_retention", fmt.Sprintf("invalid duration format: %v", err))
+		}
+		if retention <= 0 {
+			return NewConfigurationError("draft_retention", "draft_retention must be positive")
+		}
+	}
+
+	if c.Signing != nil && c.Signing.Keys == "" {
+		return NewConfigurationError("signing.keys", "signing keys are required when signing is configured")
+	}
+
 	switch c.Type {
 	case "dynamodb":
 		if c.DynamoDB == nil {
@@ -53,6 +86,16 @@ func (c *StorageConfig) Validate() error {
 	}
 }
 
+// DraftRetentionPeriod returns DraftRetention as a duration. It assumes the
+// configuration was validated.
+func (c *StorageConfig) DraftRetentionPeriod() time.Duration {
+	retention, err := time.ParseDuration(c.DraftRetention)
+	if err != nil || retention <= 0 {
+		return DefaultDraftRetention
+	}
+	return retention
+}
+
 // Validates the DynamoDB configuration.
 func (c *DynamoDBStorageConfig) Validate() error {
 	if c == nil {
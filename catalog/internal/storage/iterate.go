@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// ForEachPage lists every component in store matching filters, starting at
+// token, and calls fn once per page. It stops at the first error.
+func ForEachPage(ctx context.Context, store ComponentStore, filters ComponentFilters, pageSize int32, token string, fn func(*ComponentList) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := store.ListComponents(ctx, filters, Pagination{
+			Limit:     pageSize,
+			NextToken: token,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list components: %w", err)
+		}
+
+		if err := fn(page); err != nil {
+			return err
+		}
+
+		if !page.HasMore || page.NextToken == "" {
+			return nil
+		}
+		token = page.NextToken
+	}
+}
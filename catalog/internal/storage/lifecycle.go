@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// DefaultDraftRetention is how long drafts are kept without being promoted
+// or edited when StorageConfig.DraftRetention is not set.
+const DefaultDraftRetention = 30 * 24 * time.Hour
+
+// DraftStore is implemented by stores that publish new versions as drafts.
+// StoreComponent stores every version as a draft, which can be stored again
+// to edit it in place until it is promoted. Versions that were promoted
+// cannot be overwritten.
+type DraftStore interface {
+	// ApproveVersion records the approval of a draft by one of its
+	// approvers, with the change recording it, and returns the updated
+	// draft. See ApproveDraft.
+	ApproveVersion(ctx context.Context, name, version, by string) (*models.Component, error)
+	// PromoteVersion makes a draft active after validating it again,
+	// checking its version bump against the version it succeeds and checking
+	// it has the approvals its category requires. by is recorded as the
+	// author of the returned change.
+	PromoteVersion(ctx context.Context, name, version, by string) (*models.ComponentChange, error)
+	// GetChanges returns the changes recorded for a component, oldest first.
+	GetChanges(ctx context.Context, name string) ([]models.ComponentChange, error)
+	// PurgeDrafts deletes the drafts last updated before olderThan and
+	// returns their IDs.
+	PurgeDrafts(ctx context.Context, olderThan time.Time) ([]string, error)
+}
+
+// PrepareDraft marks component as a draft before it is stored over
+// existing, the stored version with the same name and version if any. Only
+// drafts can be overwritten. An edited draft keeps its creation time and the
+// approvals of its definition; approvals of a definition that changed expire.
+func PrepareDraft(component, existing *models.Component) error {
+	component.Metadata.Status = models.VersionStatusDraft
+	component.Metadata.Approvals = nil
+	if existing == nil {
+		return nil
+	}
+	if !existing.IsDraft() {
+		return NewComponentExistsError(component.Name, component.Version).
+			WithDetail("status", existing.Status())
+	}
+	component.CreatedAt = existing.CreatedAt
+
+	checksum, err := models.ComputeChecksum(component)
+	if err != nil {
+		return err
+	}
+	for _, approval := range existing.Metadata.Approvals {
+		if approval.Checksum == checksum {
+			component.Metadata.Approvals = append(component.Metadata.Approvals, approval)
+		}
+	}
+	return nil
+}
+
+// Approvers returns the users who may approve draft, which succeeds
+// previous if any. New versions are approved by the maintainers of the
+// version they succeed, so a draft cannot make its own approvers by changing
+// its maintainers. The first version of a component is approved by the
+// approvers of its category or, when the category lists none, by its
+// maintainers.
+func Approvers(draft, previous *models.Component, taxonomy *models.Taxonomy) []string {
+	if previous != nil {
+		return previous.Maintainers
+	}
+	if taxonomy != nil {
+		if approvers := taxonomy.Approvers(draft.Category); len(approvers) > 0 {
+			return approvers
+		}
+	}
+	return draft.Maintainers
+}
+
+// ApproveDraft records the approval of draft by by, who must be one of its
+// Approvers. The publisher of a draft cannot approve it. Approving again
+// renews the approval, and approvals of earlier definitions of the draft are
+// dropped. The draft counts as updated, so it is not purged while it is
+// reviewed.
+func ApproveDraft(draft, previous *models.Component, taxonomy *models.Taxonomy, by string) error {
+	if !draft.IsDraft() {
+		return NewConflictError("component version", fmt.Sprintf("%s is %s, not a draft", draft.GetID(), draft.Status())).
+			WithDetail("status", draft.Status())
+	}
+	if by == "" || !slices.Contains(Approvers(draft, previous, taxonomy), by) {
+		if previous != nil {
+			return NewValidationError("approver", fmt.Sprintf("'%s' is not a maintainer of %s", by, previous.GetID()))
+		}
+		return NewValidationError("approver", fmt.Sprintf("'%s' cannot approve the first version of %s", by, draft.Name))
+	}
+	if by == draft.Metadata.PublishedBy {
+		return NewValidationError("approver", fmt.Sprintf("'%s' published %s and cannot approve it", by, draft.GetID()))
+	}
+
+	now := time.Now().UTC()
+	draft.Metadata.Approvals = slices.DeleteFunc(draft.Metadata.Approvals, func(approval models.Approval) bool {
+		return approval.By == by || approval.Checksum != draft.Metadata.Checksum
+	})
+	draft.Metadata.Approvals = append(draft.Metadata.Approvals, models.Approval{
+		By:       by,
+		At:       now,
+		Checksum: draft.Metadata.Checksum,
+	})
+	draft.UpdatedAt = now
+	return nil
+}
+
+// ApprovalChange returns the change recording the approval of draft by by,
+// once ApproveDraft recorded it.
+func ApprovalChange(draft *models.Component, by string) *models.ComponentChange {
+	return &models.ComponentChange{
+		ID:            fmt.Sprintf("%s:%s:%s", models.ChangeTypeApprove, draft.GetID(), by),
+		ComponentName: draft.Name,
+		Version:       draft.Version,
+		ChangeType:    models.ChangeTypeApprove,
+		ChangedBy:     by,
+		ChangedAt:     draft.UpdatedAt,
+		GitCommit:     draft.Metadata.GitCommit,
+		Summary:       fmt.Sprintf("approved %s", draft.GetID()),
+	}
+}
+
+// PreviousVersion returns the highest version in history below version that
+// is not a draft, or an empty string when there is none. It is the version
+// a promoted draft is compared with.
+func PreviousVersion(history []models.ComponentVersion, version string) string {
+	target, err := models.ParseSemanticVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	var previous *models.SemanticVersionInfo
+	for _, entry := range history {
+		if entry.Status == models.VersionStatusDraft {
+			continue
+		}
+		info, err := models.ParseSemanticVersion(entry.Version)
+		if err != nil || info.Compare(target) >= 0 {
+			continue
+		}
+		if previous == nil || info.Compare(previous) > 0 {
+			previous = info
+		}
+	}
+	if previous == nil {
+		return ""
+	}
+	return previous.Raw
+}
+
+// PromoteDraft validates draft against taxonomy and checks its version bump
+// against previous, the version it succeeds if any, then marks it active and
+// returns the change to record. Every issue is reported in a single
+// ValidationError; warnings are kept in the change and its summary. Drafts in a category that requires approvals are only promoted
+// once enough of their Approvers approved them and when their publisher is
+// known, and the change records the approvers.
+func PromoteDraft(draft, previous *models.Component, taxonomy *models.Taxonomy, by string) (*models.ComponentChange, error) {
+	if !draft.IsDraft() {
+		return nil, NewConflictError("component version", fmt.Sprintf("%s is %s, not a draft", draft.GetID(), draft.Status())).
+			WithDetail("status", draft.Status())
+	}
+	// Approvals are checked against the draft as stored, before the
+	// taxonomy is applied again.
+	approvers := draft.Approvers(Approvers(draft, previous, taxonomy))
+
+	var issues models.ValidationErrors
+	if taxonomy != nil {
+		issues = taxonomy.Normalize(draft)
+	}
+	issues = append(models.NewComponentValidator().Issues(draft), issues...)
+
+	now := time.Now().UTC()
+	change := &models.ComponentChange{
+		ID:            fmt.Sprintf("%s:%s", models.ChangeTypePromote, draft.GetID()),
+		ComponentName: draft.Name,
+		Version:       draft.Version,
+		ChangeType:    models.ChangeTypePromote,
+		ChangedBy:     by,
+		ChangedAt:     now,
+		GitCommit:     draft.Metadata.GitCommit,
+		Summary:       fmt.Sprintf("promoted %s", draft.GetID()),
+		Approvers:     approvers,
+	}
+
+	if previous != nil {
+		diff, bumpIssues := models.CheckVersionBump(previous, draft)
+		issues = append(issues, bumpIssues...)
+
+		change.Changes = diff.Changes
+		change.SemanticVersionInfo = &models.SemanticVersionChange{
+			PreviousVersion: previous.Version,
+			NewVersion:      draft.Version,
+			VersionType:     diff.Summary.RecommendedVersionBump,
+			BreakingChanges: !diff.Summary.IsBackwardCompatible,
+			Reason:          fmt.Sprintf("%d interface changes since %s, %d breaking", diff.Summary.TotalChanges, previous.Version, diff.Summary.BreakingChanges),
+		}
+		from, errFrom := models.ParseSemanticVersion(previous.Version)
+		to, errTo := models.ParseSemanticVersion(draft.Version)
+		if errFrom == nil && errTo == nil {
+			change.SemanticVersionInfo.VersionType = models.VersionBump(from, to)
+		}
+		change.Summary += fmt.Sprintf(" succeeding %s", previous.Version)
+	}
+
+	if issues.HasErrors() {
+		return nil, NewComponentValidationError(issues)
+	}
+	if taxonomy != nil {
+		required := taxonomy.RequiredApprovals(draft.Category)
+		// Without a publisher, the publisher could approve their own draft.
+		if required > 0 && draft.Metadata.PublishedBy == "" {
+			return nil, NewConflictError("component version", fmt.Sprintf("%s has no recorded publisher and cannot be approved; publish it again with a user", draft.GetID())).
+				WithDetail("required_approvals", required)
+		}
+		if len(approvers) < required {
+			return nil, NewConflictError("component version", fmt.Sprintf("%s needs %d approvals from approvers other than its publisher, it has %d",
+				draft.GetID(), required, len(approvers))).
+				WithDetail("required_approvals", required).
+				WithDetail("approvers", approvers)
+		}
+	}
+	if change.Warnings = issues.Warnings(); len(change.Warnings) > 0 {
+		messages := make([]string, 0, len(change.Warnings))
+		for _, warning := range change.Warnings {
+			messages = append(messages, warning.Message)
+		}
+		change.Summary += "; " + strings.Join(messages, "; ")
+	}
+
+	draft.Metadata.Status = models.VersionStatusActive
+	draft.UpdatedAt = now
+	if err := draft.SetChecksum(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// RunDraftCleanup purges the drafts of store that were not updated for
+// retention, then again every interval until ctx is done.
+func RunDraftCleanup(ctx context.Context, store DraftStore, retention, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := store.PurgeDrafts(ctx, time.Now().Add(-retention))
+		switch {
+		case err != nil:
+			logger.WarnContext(ctx, "failed to purge stale drafts", "error", err)
+		case len(purged) > 0:
+			logger.InfoContext(ctx, "purged stale drafts", "count", len(purged), "drafts", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+func draftComponent(version string) *models.Component {
+	return &models.Component{
+		Name:        "postgres",
+		Version:     version,
+		Provider:    "amazon",
+		Category:    "database",
+		Maintainers: []string{"platform@example.com"},
+		Inputs:      []models.InputSpec{{Name: "db_name", Type: "string", Description: "Database name"}},
+		Outputs:     []models.OutputSpec{{Name: "endpoint", Type: "string", Description: "Endpoint"}},
+		Deployment:  models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+		Metadata:    models.ComponentMetadata{Status: models.VersionStatusDraft, GitCommit: "abc123"},
+	}
+}
+
+func TestPrepareDraft(t *testing.T) {
+	component := draftComponent("1.0.0")
+	component.Metadata.Status = ""
+	require.NoError(t, PrepareDraft(component, nil))
+	assert.True(t, component.IsDraft())
+
+	created := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	existing := draftComponent("1.0.0")
+	existing.CreatedAt = created
+	edited := draftComponent("1.0.0")
+	edited.Metadata.Status = models.VersionStatusActive
+	require.NoError(t, PrepareDraft(edited, existing))
+	assert.True(t, edited.IsDraft(), "drafts cannot be stored as active")
+	assert.Equal(t, created, edited.CreatedAt)
+
+	existing.Metadata.Status = models.VersionStatusActive
+	err := PrepareDraft(draftComponent("1.0.0"), existing)
+	assert.ErrorIs(t, err, ErrComponentExists)
+}
+
+func TestPrepareDraft_Approvals(t *testing.T) {
+	existing := draftComponent("1.0.0")
+	require.NoError(t, existing.SetChecksum())
+	existing.Metadata.Approvals = []models.Approval{{By: "security@example.com", Checksum: existing.Metadata.Checksum}}
+
+	same := draftComponent("1.0.0")
+	same.Metadata.Approvals = []models.Approval{{By: "forged@example.com", Checksum: existing.Metadata.Checksum}}
+	require.NoError(t, PrepareDraft(same, existing))
+	assert.Equal(t, existing.Metadata.Approvals, same.Metadata.Approvals, "approvals are kept by the store, not taken from the publisher")
+
+	edited := draftComponent("1.0.0")
+	edited.Description = "Managed PostgreSQL"
+	require.NoError(t, PrepareDraft(edited, existing))
+	assert.Empty(t, edited.Metadata.Approvals, "approvals expire when the draft changes")
+}
+
+func TestApproveDraft(t *testing.T) {
+	draft := draftComponent("1.0.0")
+	draft.Maintainers = append(draft.Maintainers, "security@example.com")
+	draft.Metadata.PublishedBy = "platform@example.com"
+	require.NoError(t, draft.SetChecksum())
+	draft.Metadata.Approvals = []models.Approval{{By: "stale@example.com", Checksum: "sha256:0000"}}
+
+	assert.ErrorIs(t, ApproveDraft(draft, nil, nil, "platform@example.com"), ErrValidation, "publishers cannot approve their own drafts")
+	assert.ErrorIs(t, ApproveDraft(draft, nil, nil, "data@example.com"), ErrValidation, "only maintainers approve")
+	assert.ErrorIs(t, ApproveDraft(draft, nil, nil, ""), ErrValidation)
+
+	require.NoError(t, ApproveDraft(draft, nil, nil, "security@example.com"))
+	require.NoError(t, ApproveDraft(draft, nil, nil, "security@example.com"))
+	require.Len(t, draft.Metadata.Approvals, 1, "approving again renews the approval and stale ones are dropped")
+	assert.Equal(t, "security@example.com", draft.Metadata.Approvals[0].By)
+	assert.Equal(t, draft.Metadata.Checksum, draft.Metadata.Approvals[0].Checksum)
+	assert.Equal(t, draft.Metadata.Approvals[0].At, draft.UpdatedAt)
+	assert.Equal(t, []string{"security@example.com"}, draft.Approvers(Approvers(draft, nil, nil)))
+
+	change := ApprovalChange(draft, "security@example.com")
+	assert.Equal(t, models.ChangeTypeApprove, change.ChangeType)
+	assert.Equal(t, "security@example.com", change.ChangedBy)
+	assert.Equal(t, draft.UpdatedAt, change.ChangedAt)
+
+	draft.Metadata.Status = models.VersionStatusActive
+	assert.ErrorIs(t, ApproveDraft(draft, nil, nil, "security@example.com"), ErrConflict)
+}
+
+func TestApproveDraft_DraftCannotChooseItsApprovers(t *testing.T) {
+	previous := draftComponent("1.0.0")
+	previous.Metadata.Status = models.VersionStatusActive
+	previous.Maintainers = []string{"platform@example.com", "security@example.com"}
+
+	// The publisher replaces the maintainers with an account they control.
+	draft := draftComponent("1.1.0")
+	draft.Maintainers = []string{"platform@example.com", "sockpuppet@example.com"}
+	draft.Metadata.PublishedBy = "platform@example.com"
+	require.NoError(t, draft.SetChecksum())
+
+	err := ApproveDraft(draft, previous, nil, "sockpuppet@example.com")
+	assert.ErrorIs(t, err, ErrValidation, "only the maintainers of the previous version approve")
+	require.NoError(t, ApproveDraft(draft, previous, nil, "security@example.com"))
+
+	// Approvals recorded before the maintainers changed do not count either.
+	draft.Metadata.Approvals = append(draft.Metadata.Approvals, models.Approval{By: "sockpuppet@example.com", Checksum: draft.Metadata.Checksum})
+	assert.Equal(t, []string{"security@example.com"}, draft.Approvers(Approvers(draft, previous, nil)))
+
+	taxonomy := testTaxonomy()
+	taxonomy.Categories[0].Approvers = []string{"network@example.com"}
+	first := draftComponent("1.0.0")
+	first.Maintainers = append(first.Maintainers, "sockpuppet@example.com")
+	assert.Equal(t, []string{"network@example.com"}, Approvers(first, nil, taxonomy),
+		"the approvers of the category approve first versions")
+	assert.ErrorIs(t, ApproveDraft(first, nil, taxonomy, "sockpuppet@example.com"), ErrValidation)
+}
+
+func TestPreviousVersion(t *testing.T) {
+	history := []models.ComponentVersion{
+		{Version: "2.0.0", Status: models.VersionStatusActive},
+		{Version: "1.4.0", Status: models.VersionStatusDraft},
+		{Version: "1.3.0", Status: models.VersionStatusYanked},
+		{Version: "1.2.0", Status: models.VersionStatusActive},
+		{Version: "latest", Status: models.VersionStatusActive},
+	}
+
+	assert.Equal(t, "1.3.0", PreviousVersion(history, "1.5.0"))
+	assert.Equal(t, "1.2.0", PreviousVersion(history, "1.3.0"))
+	assert.Equal(t, "2.0.0", PreviousVersion(history, "3.0.0"))
+	assert.Empty(t, PreviousVersion(history, "1.0.0"))
+	assert.Empty(t, PreviousVersion(history, "invalid"))
+}
+
+func TestPromoteDraft(t *testing.T) {
+	t.Run("first version", func(t *testing.T) {
+		draft := draftComponent("1.0.0")
+		change, err := PromoteDraft(draft, nil, testTaxonomy(), "platform@example.com")
+		require.NoError(t, err)
+
+		assert.Equal(t, models.VersionStatusActive, draft.Metadata.Status)
+		assert.Equal(t, "aws", draft.Provider, "the taxonomy is applied again")
+		assert.NoError(t, draft.VerifyChecksum())
+		assert.NotEmpty(t, draft.Metadata.Checksum)
+
+		assert.Equal(t, "promote:postgres:1.0.0", change.ID)
+		assert.Equal(t, models.ChangeTypePromote, change.ChangeType)
+		assert.Equal(t, "platform@example.com", change.ChangedBy)
+		assert.Equal(t, "abc123", change.GitCommit)
+		assert.Nil(t, change.SemanticVersionInfo)
+		assert.Equal(t, "promoted postgres:1.0.0", change.Summary)
+	})
+
+	t.Run("minor version", func(t *testing.T) {
+		previous := draftComponent("1.0.0")
+		previous.Metadata.Status = models.VersionStatusActive
+		draft := draftComponent("1.1.0")
+		draft.Outputs = append(draft.Outputs, models.OutputSpec{Name: "port", Type: "number", Description: "Port"})
+
+		change, err := PromoteDraft(draft, previous, nil, "platform@example.com")
+		require.NoError(t, err)
+		require.Len(t, change.Changes, 1)
+		assert.Equal(t, "outputs.port", change.Changes[0].Field)
+		assert.Equal(t, &models.SemanticVersionChange{
+			PreviousVersion: "1.0.0",
+			NewVersion:      "1.1.0",
+			VersionType:     models.BumpMinor,
+			Reason:          "1 interface changes since 1.0.0, 0 breaking",
+		}, change.SemanticVersionInfo)
+	})
+
+	t.Run("patch warning", func(t *testing.T) {
+		previous := draftComponent("1.0.0")
+		draft := draftComponent("1.0.1")
+		draft.Outputs = append(draft.Outputs, models.OutputSpec{Name: "port", Type: "number", Description: "Port"})
+
+		change, err := PromoteDraft(draft, previous, nil, "platform@example.com")
+		require.NoError(t, err)
+		assert.Contains(t, change.Summary, "succeeding 1.0.0; version 1.0.1 adds to the interface of 1.0.0")
+	})
+
+	t.Run("insufficient bump", func(t *testing.T) {
+		previous := draftComponent("1.0.0")
+		draft := draftComponent("1.1.0")
+		draft.Outputs = nil
+
+		_, err := PromoteDraft(draft, previous, nil, "platform@example.com")
+		require.ErrorIs(t, err, ErrValidation)
+		var issues models.ValidationErrors
+		require.ErrorAs(t, err, &issues)
+		rules := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			rules = append(rules, issue.Rule)
+		}
+		assert.Equal(t, []string{models.RuleRequired, models.RuleVersionBump}, rules, "validation and the bump check are reported together")
+		assert.True(t, draft.IsDraft())
+	})
+
+	t.Run("approvals required", func(t *testing.T) {
+		taxonomy := testTaxonomy()
+		taxonomy.Categories[0].RequiredApprovals = 2
+
+		draft := draftComponent("1.0.0")
+		draft.Maintainers = []string{"platform@example.com", "security@example.com", "network@example.com"}
+		draft.Metadata.PublishedBy = "platform@example.com"
+		require.NoError(t, draft.SetChecksum())
+		require.NoError(t, ApproveDraft(draft, nil, taxonomy, "security@example.com"))
+
+		_, err := PromoteDraft(draft, nil, taxonomy, "platform@example.com")
+		require.ErrorIs(t, err, ErrConflict)
+		assert.Contains(t, err.Error(), "needs 2 approvals from approvers other than its publisher, it has 1")
+		assert.True(t, draft.IsDraft())
+
+		require.NoError(t, ApproveDraft(draft, nil, taxonomy, "network@example.com"))
+		change, err := PromoteDraft(draft, nil, taxonomy, "platform@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"security@example.com", "network@example.com"}, change.Approvers)
+		assert.Equal(t, "aws", draft.Provider, "approvals hold for the draft as stored")
+	})
+
+	t.Run("unknown publisher", func(t *testing.T) {
+		taxonomy := testTaxonomy()
+		taxonomy.Categories[0].RequiredApprovals = 1
+
+		draft := draftComponent("1.0.0")
+		draft.Maintainers = []string{"platform@example.com", "security@example.com"}
+		require.NoError(t, draft.SetChecksum())
+		require.NoError(t, ApproveDraft(draft, nil, taxonomy, "platform@example.com"))
+
+		_, err := PromoteDraft(draft, nil, taxonomy, "platform@example.com")
+		require.ErrorIs(t, err, ErrConflict, "anonymous drafts could be approved by their publisher")
+		assert.Contains(t, err.Error(), "has no recorded publisher")
+	})
+
+	t.Run("not a draft", func(t *testing.T) {
+		active := draftComponent("1.0.0")
+		active.Metadata.Status = models.VersionStatusActive
+		_, err := PromoteDraft(active, nil, nil, "platform@example.com")
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+}
+
+func TestComponentFilters_MatchesDrafts(t *testing.T) {
+	draft := draftComponent("1.0.0")
+
+	assert.False(t, (&ComponentFilters{}).Matches(draft))
+	assert.False(t, (&ComponentFilters{Viewer: "data@example.com"}).Matches(draft))
+	assert.True(t, (&ComponentFilters{Viewer: "platform@example.com"}).Matches(draft))
+	assert.True(t, (&ComponentFilters{IncludeDrafts: true}).Matches(draft))
+
+	draft.Metadata.Status = models.VersionStatusActive
+	assert.True(t, (&ComponentFilters{}).Matches(draft))
+}
+
+func TestStorageConfig_DraftRetention(t *testing.T) {
+	config := &StorageConfig{Type: "memory"}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, DefaultDraftRetention, config.DraftRetentionPeriod())
+
+	config.DraftRetention = "72h"
+	require.NoError(t, config.Validate())
+	assert.Equal(t, 72*time.Hour, config.DraftRetentionPeriod())
+
+	config.DraftRetention = "3 days"
+	assert.ErrorIs(t, config.Validate(), ErrConfiguration)
+	config.DraftRetention = "-1h"
+	assert.ErrorIs(t, config.Validate(), ErrConfiguration)
+}
+
+// purgeRecorder records the calls made to PurgeDrafts.
+type purgeRecorder struct {
+	DraftStore
+	mu     sync.Mutex
+	cutoff []time.Time
+}
+
+func (p *purgeRecorder) PurgeDrafts(ctx context.Context, olderThan time.Time) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cutoff = append(p.cutoff, olderThan)
+	return []string{"postgres:1.0.0"}, nil
+}
+
+func (p *purgeRecorder) calls() []time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cutoff
+}
+
+func TestRunDraftCleanup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := &purgeRecorder{}
+	done := make(chan struct{})
+	go func() {
+		RunDraftCleanup(ctx, store, time.Hour, time.Millisecond, logging.NewNoop())
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(store.calls()) >= 2 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	cutoff := store.calls()[0]
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), cutoff, time.Minute)
+}
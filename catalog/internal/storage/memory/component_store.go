@@ -0,0 +1,479 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// componentStore implements the ComponentStore interface in memory. It is
+// meant for tests and local development; nothing survives a restart.
+type componentStore struct {
+	mu         sync.RWMutex
+	components map[string]map[string]*models.Component
+	changes    map[string][]models.ComponentChange
+	taxonomy   *models.Taxonomy
+	checks     []storage.PublishCheck
+	logger     logging.Logger
+}
+
+// NewComponentStore creates a new in-memory ComponentStore running checks
+// before versions are stored as drafts and before they are promoted.
+func NewComponentStore(logger logging.Logger, checks ...storage.PublishCheck) storage.ComponentStore {
+	return &componentStore{
+		components: make(map[string]map[string]*models.Component),
+		changes:    make(map[string][]models.ComponentChange),
+		taxonomy:   &models.Taxonomy{},
+		checks:     checks,
+		logger:     logger.With("component", "memory_component_store"),
+	}
+}
+
+// GetComponent retrieves a specific component by name and version.
+func (s *componentStore) GetComponent(ctx context.Context, name, version string) (*models.Component, error) {
+	if name == "" {
+		return nil, storage.NewValidationError("name", "component name is required")
+	}
+	if version == "" {
+		return nil, storage.NewValidationError("version", "component version is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	component, ok := s.components[name][version]
+	if !ok {
+		return nil, storage.NewComponentNotFoundError(name, version).
+			WithDetail("operation", "GetComponent")
+	}
+	if err := component.VerifyChecksum(); err != nil {
+		return nil, storage.NewIntegrityError(component.GetID(), err)
+	}
+
+	return cloneComponent(component), nil
+}
+
+// ListComponents retrieves components with filtering and pagination.
+func (s *componentStore) ListComponents(ctx context.Context, filters storage.ComponentFilters, pagination storage.Pagination) (*storage.ComponentList, error) {
+	if err := filters.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filters: %w", err)
+	}
+	if err := pagination.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pagination: %w", err)
+	}
+
+	filters = filters.Normalized(s.currentTaxonomy())
+
+	s.mu.RLock()
+	var matched []*models.Component
+	for _, versions := range s.components {
+		for _, component := range versions {
+			if filters.Matches(component) {
+				matched = append(matched, component)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	if filters.HighestOnly {
+		matched = storage.HighestVersions(matched)
+	}
+	storage.SortComponents(matched, pagination.SortBy, pagination.SortOrder)
+
+	list, err := storage.Paginate(matched, pagination)
+	if err != nil {
+		return nil, err
+	}
+	for i, component := range list.Components {
+		list.Components[i] = cloneComponent(component)
+	}
+	return list, nil
+}
+
+// StoreComponent stores a component definition as a draft, replacing the
+// draft of the same version if any, and returns the warnings of its
+// validation and publish checks.
+func (s *componentStore) StoreComponent(ctx context.Context, component *models.Component) (models.ValidationErrors, error) {
+	if component == nil {
+		return nil, storage.NewValidationError("component", "component is required")
+	}
+
+	warnings, err := storage.ValidateComponent(component, s.currentTaxonomy())
+	if err != nil {
+		return nil, err
+	}
+	checkWarnings, err := storage.RunChecks(ctx, s.checks, component)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, checkWarnings...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := storage.PrepareDraft(component, s.components[component.Name][component.Version]); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if component.CreatedAt.IsZero() {
+		component.CreatedAt = now
+	}
+	component.UpdatedAt = now
+	if err := component.SetChecksum(); err != nil {
+		return nil, err
+	}
+
+	s.put(component)
+
+	s.logger.InfoContext(ctx, "draft stored successfully",
+		"name", component.Name, "version", component.Version)
+	return warnings, nil
+}
+
+// PutComponents writes components verbatim, keeping their timestamps.
+func (s *componentStore) PutComponents(ctx context.Context, components []*models.Component) error {
+	for i, component := range components {
+		if component == nil {
+			return storage.NewValidationError(fmt.Sprintf("components[%d]", i), "component is required")
+		}
+	}
+
+	s.mu.Lock()
+	for _, component := range components {
+		s.put(component)
+	}
+	s.mu.Unlock()
+
+	s.logger.DebugContext(ctx, "components written", "count", len(components))
+	return nil
+}
+
+// PutChanges writes changes verbatim, replacing the change recorded at the
+// same time for the same version, and keeps the changes of each component
+// oldest first.
+func (s *componentStore) PutChanges(ctx context.Context, changes []models.ComponentChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, change := range changes {
+		recorded := s.changes[change.ComponentName]
+		i := slices.IndexFunc(recorded, func(c models.ComponentChange) bool {
+			return c.Version == change.Version && c.ChangedAt.Equal(change.ChangedAt)
+		})
+		if i >= 0 {
+			recorded[i] = change
+			continue
+		}
+		recorded = append(recorded, change)
+		slices.SortStableFunc(recorded, func(a, b models.ComponentChange) int {
+			return a.ChangedAt.Compare(b.ChangedAt)
+		})
+		s.changes[change.ComponentName] = recorded
+	}
+
+	s.logger.DebugContext(ctx, "changes written", "count", len(changes))
+	return nil
+}
+
+// GetVersionHistory gets all versions of a component, latest first.
+func (s *componentStore) GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error) {
+	if name == "" {
+		return nil, storage.NewValidationError("name", "component name is required")
+	}
+
+	s.mu.RLock()
+	components := slices.Collect(maps.Values(s.components[name]))
+	s.mu.RUnlock()
+
+	slices.SortFunc(components, func(a, b *models.Component) int {
+		return models.CompareVersions(b.Version, a.Version)
+	})
+
+	versions := make([]models.ComponentVersion, 0, len(components))
+	for _, component := range components {
+		versions = append(versions, models.NewComponentVersion(component))
+	}
+
+	return versions, nil
+}
+
+// ApproveVersion records the approval of a draft.
+func (s *componentStore) ApproveVersion(ctx context.Context, name, version, by string) (*models.Component, error) {
+	previous, err := s.previousVersion(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.components[name][version]
+	if !ok {
+		return nil, storage.NewComponentNotFoundError(name, version).
+			WithDetail("operation", "ApproveVersion")
+	}
+	if err := current.VerifyChecksum(); err != nil {
+		return nil, storage.NewIntegrityError(current.GetID(), err)
+	}
+
+	draft := cloneComponent(current)
+	if err := storage.ApproveDraft(draft, previous, s.taxonomy, by); err != nil {
+		return nil, err
+	}
+	s.put(draft)
+	s.changes[name] = append(s.changes[name], *storage.ApprovalChange(draft, by))
+
+	s.logger.InfoContext(ctx, "draft approved", "name", name, "version", version, "by", by)
+	return draft, nil
+}
+
+// PromoteVersion makes a draft version active.
+func (s *componentStore) PromoteVersion(ctx context.Context, name, version, by string) (*models.ComponentChange, error) {
+	draft, err := s.GetComponent(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.previousVersion(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := draft.Metadata.Checksum
+	change, err := storage.PromoteDraft(draft, previous, s.currentTaxonomy(), by)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := storage.RunChecks(ctx, s.checks, draft)
+	if err != nil {
+		return nil, err
+	}
+	change.Warnings = append(change.Warnings, warnings...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The draft may have been edited or promoted in the meantime.
+	current, ok := s.components[name][version]
+	if !ok {
+		return nil, storage.NewComponentNotFoundError(name, version).
+			WithDetail("operation", "PromoteVersion")
+	}
+	if !current.IsDraft() || current.Metadata.Checksum != checksum {
+		return nil, storage.NewConflictError("component version", fmt.Sprintf("%s changed while it was promoted", draft.GetID()))
+	}
+
+	s.put(draft)
+	s.changes[name] = append(s.changes[name], *change)
+
+	s.logger.InfoContext(ctx, "draft promoted", "name", name, "version", version, "by", by)
+	return change, nil
+}
+
+// previousVersion returns the version a draft succeeds, or nil for the
+// first version of a component.
+func (s *componentStore) previousVersion(ctx context.Context, name, version string) (*models.Component, error) {
+	history, err := s.GetVersionHistory(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	previousVersion := storage.PreviousVersion(history, version)
+	if previousVersion == "" {
+		return nil, nil
+	}
+	return s.GetComponent(ctx, name, previousVersion)
+}
+
+// GetChanges returns the changes recorded for a component, oldest first.
+func (s *componentStore) GetChanges(ctx context.Context, name string) ([]models.ComponentChange, error) {
+	if name == "" {
+		return nil, storage.NewValidationError("name", "component name is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.changes[name]), nil
+}
+
+// PurgeDrafts deletes the drafts last updated before olderThan.
+func (s *componentStore) PurgeDrafts(ctx context.Context, olderThan time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged []string
+	for name, versions := range s.components {
+		for version, component := range versions {
+			if component.IsDraft() && component.UpdatedAt.Before(olderThan) {
+				delete(versions, version)
+				purged = append(purged, component.GetID())
+			}
+		}
+		if len(versions) == 0 {
+			delete(s.components, name)
+		}
+	}
+	slices.Sort(purged)
+
+	s.logger.DebugContext(ctx, "drafts purged", "count", len(purged))
+	return purged, nil
+}
+
+// DeprecateVersion deprecates a published version.
+func (s *componentStore) DeprecateVersion(ctx context.Context, name, version, by string, deprecation models.Deprecation) (*models.Component, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.components[name][version]
+	if !ok {
+		return nil, storage.NewComponentNotFoundError(name, version).
+			WithDetail("operation", "DeprecateVersion")
+	}
+
+	deprecated, err := s.deprecate(current, by, deprecation, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "version deprecated", "name", name, "version", version, "by", by)
+	return deprecated, nil
+}
+
+// DeprecateComponent deprecates every published version of a component.
+// Either every version is deprecated or none is.
+func (s *componentStore) DeprecateComponent(ctx context.Context, name, by string, deprecation models.Deprecation) ([]*models.Component, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var published []*models.Component
+	for _, component := range s.components[name] {
+		if status := component.Status(); status != models.VersionStatusDraft && status != models.VersionStatusYanked {
+			published = append(published, component)
+		}
+	}
+	if len(published) == 0 {
+		return nil, storage.NewComponentNotFoundError(name, "").
+			WithDetail("operation", "DeprecateComponent")
+	}
+	slices.SortFunc(published, func(a, b *models.Component) int {
+		return models.CompareVersions(b.Version, a.Version)
+	})
+
+	// Check every version before deprecating any.
+	now := time.Now().UTC()
+	for _, component := range published {
+		if _, err := storage.Deprecate(cloneComponent(component), deprecation, by, now); err != nil {
+			return nil, err
+		}
+	}
+
+	deprecated := make([]*models.Component, 0, len(published))
+	for _, component := range published {
+		component, err := s.deprecate(component, by, deprecation, now)
+		if err != nil {
+			return nil, err
+		}
+		deprecated = append(deprecated, component)
+	}
+
+	s.logger.InfoContext(ctx, "component deprecated", "name", name, "versions", len(deprecated), "by", by)
+	return deprecated, nil
+}
+
+// deprecate deprecates a copy of current, stores it and records the change.
+// s.mu must be held.
+func (s *componentStore) deprecate(current *models.Component, by string, deprecation models.Deprecation, now time.Time) (*models.Component, error) {
+	if err := current.VerifyChecksum(); err != nil {
+		return nil, storage.NewIntegrityError(current.GetID(), err)
+	}
+
+	component := cloneComponent(current)
+	change, err := storage.Deprecate(component, deprecation, by, now)
+	if err != nil {
+		return nil, err
+	}
+	s.put(component)
+	s.changes[component.Name] = append(s.changes[component.Name], *change)
+	return cloneComponent(component), nil
+}
+
+// HealthCheck verifies the store is healthy.
+func (s *componentStore) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// GetTaxonomy returns the current taxonomy.
+func (s *componentStore) GetTaxonomy(ctx context.Context) (*models.Taxonomy, error) {
+	return s.currentTaxonomy(), nil
+}
+
+// PutTaxonomy replaces the taxonomy if its revision is current.
+func (s *componentStore) PutTaxonomy(ctx context.Context, taxonomy *models.Taxonomy) error {
+	if err := storage.ValidateTaxonomy(taxonomy); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if taxonomy.Revision != s.taxonomy.Revision {
+		return storage.NewRevisionConflictError(taxonomy.Revision, s.taxonomy.Revision)
+	}
+	taxonomy.Revision++
+	taxonomy.UpdatedAt = time.Now()
+	s.taxonomy = taxonomy.Clone()
+
+	s.logger.InfoContext(ctx, "taxonomy stored successfully", "revision", taxonomy.Revision)
+	return nil
+}
+
+func (s *componentStore) currentTaxonomy() *models.Taxonomy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.taxonomy.Clone()
+}
+
+// put stores a copy of component. s.mu must be held.
+func (s *componentStore) put(component *models.Component) {
+	versions, ok := s.components[component.Name]
+	if !ok {
+		versions = make(map[string]*models.Component)
+		s.components[component.Name] = versions
+	}
+	versions[component.Version] = cloneComponent(component)
+}
+
+// cloneComponent copies a component deep enough that callers cannot mutate
+// what the store holds through the slices and maps they get back.
+func cloneComponent(component *models.Component) *models.Component {
+	clone := *component
+	clone.Maintainers = slices.Clone(component.Maintainers)
+	clone.Documentation = slices.Clone(component.Documentation)
+	clone.Labels = maps.Clone(component.Labels)
+	clone.Annotations = maps.Clone(component.Annotations)
+	clone.Inputs = slices.Clone(component.Inputs)
+	clone.Outputs = slices.Clone(component.Outputs)
+	clone.Deployment.Config = maps.Clone(component.Deployment.Config)
+	if component.AdditionalDeployments != nil {
+		clone.AdditionalDeployments = make([]models.DeploymentSpec, len(component.AdditionalDeployments))
+		for i, spec := range component.AdditionalDeployments {
+			spec.Config = maps.Clone(spec.Config)
+			clone.AdditionalDeployments[i] = spec
+		}
+	}
+	clone.Dependencies = slices.Clone(component.Dependencies)
+	clone.Provides = slices.Clone(component.Provides)
+	clone.ConflictsWith = slices.Clone(component.ConflictsWith)
+	clone.Metadata.Signatures = slices.Clone(component.Metadata.Signatures)
+	clone.Metadata.Approvals = slices.Clone(component.Metadata.Approvals)
+	if component.Metadata.Deprecation != nil {
+		clone.Metadata.Deprecation = component.Metadata.Deprecation.Clone()
+	}
+	return &clone
+}
@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/pkg/cache"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// RegisterWith registers the in-memory component store factory with the provided registry.
+func RegisterWith(registry *storage.Registry) {
+	registry.Register("memory", func(config *storage.StorageConfig, cache cache.Cache, logger logging.Logger) (storage.ComponentStore, error) {
+		checks, err := config.PublishChecks(logger)
+		if err != nil {
+			return nil, err
+		}
+		return NewComponentStore(logger, checks...), nil
+	})
+}
+
+func init() {
+	// Register with the default registry for backward compatibility
+	RegisterWith(storage.DefaultRegistry)
+}
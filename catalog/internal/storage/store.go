@@ -2,7 +2,12 @@
 package storage
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/HatiCode/nestor/catalog/pkg/models"
@@ -12,11 +17,25 @@ import (
 type ComponentStore interface {
 	GetComponent(ctx context.Context, name, version string) (*models.Component, error)
 	ListComponents(ctx context.Context, filters ComponentFilters, pagination Pagination) (*ComponentList, error)
-	StoreComponent(ctx context.Context, component *models.Component) error
+	// StoreComponent validates and stores a component, returning the
+	// warnings its validation and publish checks raised.
+	StoreComponent(ctx context.Context, component *models.Component) (models.ValidationErrors, error)
 	GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error)
 	HealthCheck(ctx context.Context) error
 }
 
+// BulkWriter is implemented by stores that can write many components in a
+// single round trip. Components are written as given: timestamps are kept and
+// no validation is performed, so it is meant for migrations and restores of
+// data that was already validated when it was first published.
+type BulkWriter interface {
+	PutComponents(ctx context.Context, components []*models.Component) error
+	// PutChanges writes changes as given. A change recorded at the same
+	// time for the same component version is replaced, so writing changes
+	// again is harmless.
+	PutChanges(ctx context.Context, changes []models.ComponentChange) error
+}
+
 type ComponentFilters struct {
 	Providers     []string          `json:"providers" validate:"dive,required"`
 	Categories    []string          `json:"categories" validate:"dive,required"`
@@ -27,14 +46,28 @@ type ComponentFilters struct {
 	UpdatedAfter  *time.Time        `json:"updated_after"`
 	UpdatedBefore *time.Time        `json:"updated_before"`
 	// Maturity filtering removed for MVP - can be added back later if needed
-	ActiveOnly         bool     `json:"active_only"`
-	VersionConstraint  string   `json:"version_constraint" validate:"omitempty,semver_constraint"`
-	MajorVersion       *int     `json:"major_version" validate:"omitempty,min=0"`
+	ActiveOnly bool `json:"active_only"`
+	// VersionConstraint and MajorVersion select the versions listed for each
+	// component, such as "^2.0.0" or 2.
+	VersionConstraint string `json:"version_constraint" validate:"omitempty,semver_constraint"`
+	MajorVersion      *int   `json:"major_version" validate:"omitempty,min=0"`
+	// HighestOnly lists only the highest matching version of each component.
+	HighestOnly        bool     `json:"highest_only"`
 	DeploymentEngines  []string `json:"deployment_engines" validate:"dive,required"`
 	HasDependency      string   `json:"has_dependency" validate:"omitempty,dns1123"`
 	ProvidesDependency string   `json:"provides_dependency" validate:"omitempty,dns1123"`
+	// Drafts are only listed to their maintainers, the Viewer being the
+	// user listing components, unless IncludeDrafts is set.
+	Viewer        string `json:"viewer"`
+	IncludeDrafts bool   `json:"include_drafts"`
+
+	// constraint is VersionConstraint parsed by Validate.
+	constraint *models.VersionConstraint
 }
 
+// filterValidator checks the validate tags of ComponentFilters.
+var filterValidator = models.NewComponentValidator()
+
 type Pagination struct {
 	Limit     int32     `json:"limit" validate:"min=1,max=100"`
 	NextToken string    `json:"next_token"`
@@ -70,12 +103,102 @@ const (
 	SortDesc SortOrder = "desc"
 )
 
+// Matches reports whether a component satisfies the filters that can be
+// evaluated without the backend's help.
+func (f *ComponentFilters) Matches(component *models.Component) bool {
+	if f == nil {
+		return true
+	}
+
+	if component.IsDraft() && !f.IncludeDrafts && !component.IsMaintainer(f.Viewer) {
+		return false
+	}
+
+	if len(f.Providers) > 0 && !slices.Contains(f.Providers, component.Provider) {
+		return false
+	}
+	if len(f.Categories) > 0 && !slices.Contains(f.Categories, component.Category) {
+		return false
+	}
+	if len(f.SubCategories) > 0 && !slices.Contains(f.SubCategories, component.SubCategory) {
+		return false
+	}
+
+	for key, value := range f.Labels {
+		if !component.HasLabel(key, value) {
+			return false
+		}
+	}
+
+	if f.HasDependency != "" && !component.HasDependency(f.HasDependency) {
+		return false
+	}
+	if f.ProvidesDependency != "" && !component.ProvidesDependency(f.ProvidesDependency) {
+		return false
+	}
+
+	if len(f.DeploymentEngines) > 0 && !slices.ContainsFunc(f.DeploymentEngines, component.SupportsEngine) {
+		return false
+	}
+
+	if f.ActiveOnly && component.IsDeprecated() {
+		return false
+	}
+
+	if f.VersionConstraint != "" || f.MajorVersion != nil {
+		version, err := models.ParseSemanticVersion(component.Version)
+		if err != nil {
+			return false
+		}
+		if f.MajorVersion != nil && version.Major != *f.MajorVersion {
+			return false
+		}
+		if f.VersionConstraint != "" {
+			constraint := f.constraint
+			if constraint == nil {
+				if constraint, err = models.NewConstraintParser().Parse(f.VersionConstraint); err != nil {
+					return false
+				}
+			}
+			if !constraint.Satisfies(version) {
+				return false
+			}
+		}
+	}
+
+	if f.CreatedAfter != nil && component.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && component.CreatedAt.After(*f.CreatedBefore) {
+		return false
+	}
+	if f.UpdatedAfter != nil && component.UpdatedAt.Before(*f.UpdatedAfter) {
+		return false
+	}
+	if f.UpdatedBefore != nil && component.UpdatedAt.After(*f.UpdatedBefore) {
+		return false
+	}
+
+	return true
+}
+
 // Validation methods.
 func (f *ComponentFilters) Validate() error {
 	if f == nil {
 		return nil
 	}
 
+	if issues := filterValidator.ValidateStruct(f); len(issues) > 0 {
+		return NewValidationError("filters", issues.Error()).WithDetail("errors", issues)
+	}
+	if f.VersionConstraint != "" {
+		constraint, err := models.NewConstraintParser().Parse(f.VersionConstraint)
+		if err != nil {
+			return NewValidationError("version_constraint", err.Error()).WithCause(err)
+		}
+		f.constraint = constraint
+	}
+
 	if f.CreatedAfter != nil && f.CreatedBefore != nil && f.CreatedAfter.After(*f.CreatedBefore) {
 		return ErrInvalidDateRange
 	}
@@ -87,6 +210,25 @@ func (f *ComponentFilters) Validate() error {
 	return nil
 }
 
+// HighestVersions keeps the highest version of each component, in the order
+// components are first seen.
+func HighestVersions(components []*models.Component) []*models.Component {
+	highest := make(map[string]int, len(components))
+	var kept []*models.Component
+	for _, component := range components {
+		i, seen := highest[component.Name]
+		if !seen {
+			highest[component.Name] = len(kept)
+			kept = append(kept, component)
+			continue
+		}
+		if models.CompareVersions(component.Version, kept[i].Version) > 0 {
+			kept[i] = component
+		}
+	}
+	return kept
+}
+
 func (p *Pagination) Validate() error {
 	if p.Limit <= 0 {
 		p.Limit = 20
@@ -96,3 +238,71 @@ func (p *Pagination) Validate() error {
 	}
 	return nil
 }
+
+// SortComponents sorts components by sortBy, then by name and version.
+func SortComponents(components []*models.Component, sortBy SortField, sortOrder SortOrder) {
+	slices.SortStableFunc(components, func(a, b *models.Component) int {
+		var c int
+		switch sortBy {
+		case SortByCreated:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		case SortByUpdated:
+			c = a.UpdatedAt.Compare(b.UpdatedAt)
+		case SortByProvider:
+			c = cmp.Compare(a.Provider, b.Provider)
+		case SortByCategory:
+			c = cmp.Compare(a.Category, b.Category)
+		case SortByVersion:
+			c = models.CompareVersions(a.Version, b.Version)
+		}
+		if c == 0 {
+			c = cmp.Or(cmp.Compare(a.Name, b.Name), models.CompareVersions(a.Version, b.Version))
+		}
+		if sortOrder == SortDesc {
+			return -c
+		}
+		return c
+	})
+}
+
+// Paginate returns the page of components selected by pagination, for stores
+// listing from a complete set of components. Its tokens hold the offset of
+// the next page.
+func Paginate(components []*models.Component, pagination Pagination) (*ComponentList, error) {
+	offset, err := decodeOffset(pagination.NextToken)
+	if err != nil {
+		return nil, NewValidationError("next_token", err.Error()).WithCause(err)
+	}
+
+	total := len(components)
+	offset = min(offset, total)
+	end := min(offset+int(pagination.Limit), total)
+
+	list := &ComponentList{
+		Components: slices.Clone(components[offset:end]),
+		Total:      int64(total),
+		HasMore:    end < total,
+	}
+	if list.HasMore {
+		list.NextToken = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	return list, nil
+}
+
+func decodeOffset(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pagination token: %w", err)
+	}
+
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid pagination token format")
+	}
+
+	return offset, nil
+}
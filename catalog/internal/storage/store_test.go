@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestComponentFilters_Matches(t *testing.T) {
+	created := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	before := created.Add(-time.Hour)
+	after := created.Add(time.Hour)
+
+	component := &models.Component{
+		Name:         "postgres",
+		Version:      "2.3.1",
+		Provider:     "aws",
+		Category:     "database",
+		Labels:       map[string]string{"team": "platform", "tier": "gold"},
+		Dependencies: []models.Dependency{{Name: "vpc", Version: "^1.0.0"}},
+		Provides:     []string{"postgres-database"},
+		Deployment:   models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+		AdditionalDeployments: []models.DeploymentSpec{
+			{Engine: "crossplane", Version: "1.14.0", Config: map[string]any{"apiVersion": "database.example.org/v1alpha1", "kind": "XPostgres", "compositionRef": map[string]any{"name": "xpostgres"}}},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+
+	tests := []struct {
+		name    string
+		filters *ComponentFilters
+		want    bool
+	}{
+		{"nil filters", nil, true},
+		{"empty filters", &ComponentFilters{}, true},
+		{"provider match", &ComponentFilters{Providers: []string{"gcp", "aws"}}, true},
+		{"provider miss", &ComponentFilters{Providers: []string{"gcp"}}, false},
+		{"category miss", &ComponentFilters{Categories: []string{"networking"}}, false},
+		{"labels match", &ComponentFilters{Labels: map[string]string{"team": "platform", "tier": "gold"}}, true},
+		{"labels partial miss", &ComponentFilters{Labels: map[string]string{"team": "platform", "tier": "silver"}}, false},
+		{"has dependency", &ComponentFilters{HasDependency: "vpc"}, true},
+		{"missing dependency", &ComponentFilters{HasDependency: "subnet"}, false},
+		{"provides", &ComponentFilters{ProvidesDependency: "postgres-database"}, true},
+		{"does not provide", &ComponentFilters{ProvidesDependency: "mysql-database"}, false},
+		{"default engine", &ComponentFilters{DeploymentEngines: []string{"terraform"}}, true},
+		{"additional engine", &ComponentFilters{DeploymentEngines: []string{"pulumi", "crossplane"}}, true},
+		{"unsupported engine", &ComponentFilters{DeploymentEngines: []string{"pulumi"}}, false},
+		{"created window", &ComponentFilters{CreatedAfter: &before, CreatedBefore: &after}, true},
+		{"created too early", &ComponentFilters{CreatedAfter: &after}, false},
+		{"updated too late", &ComponentFilters{UpdatedBefore: &before}, false},
+		{"version constraint", &ComponentFilters{VersionConstraint: "^2"}, true},
+		{"compound constraint", &ComponentFilters{VersionConstraint: ">=2.0.0, <2.3.0 || ^3.0.0"}, false},
+		{"major version", &ComponentFilters{MajorVersion: intPtr(2)}, true},
+		{"other major version", &ComponentFilters{MajorVersion: intPtr(1)}, false},
+		{"invalid constraint", &ComponentFilters{VersionConstraint: "^x.y"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filters.Matches(component))
+		})
+	}
+}
+
+func TestComponentFilters_Validate(t *testing.T) {
+	valid := &ComponentFilters{VersionConstraint: "^2.0 || 3.x", MajorVersion: intPtr(2)}
+	require.NoError(t, valid.Validate())
+	assert.NotNil(t, valid.constraint, "the constraint is parsed once")
+
+	tests := []struct {
+		name    string
+		filters ComponentFilters
+		field   string
+	}{
+		{"invalid constraint", ComponentFilters{VersionConstraint: ">=banana"}, "version_constraint"},
+		{"negative major", ComponentFilters{MajorVersion: intPtr(-1)}, "major_version"},
+		{"invalid dependency name", ComponentFilters{HasDependency: "Not_DNS"}, "has_dependency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filters.Validate()
+			assert.ErrorIs(t, err, ErrValidation)
+			assert.ErrorContains(t, err, tt.field)
+		})
+	}
+}
+
+func TestHighestVersions(t *testing.T) {
+	components := []*models.Component{
+		{Name: "vpc", Version: "1.2.0"},
+		{Name: "postgres", Version: "2.0.0"},
+		{Name: "vpc", Version: "1.10.0"},
+		{Name: "postgres", Version: "2.0.0-rc.1"},
+		{Name: "vpc", Version: "1.9.0"},
+	}
+
+	highest := HighestVersions(components)
+	require.Len(t, highest, 2)
+	assert.Equal(t, "vpc:1.10.0", highest[0].GetID())
+	assert.Equal(t, "postgres:2.0.0", highest[1].GetID())
+}
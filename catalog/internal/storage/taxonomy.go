@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// TaxonomyStore is implemented by stores that keep the managed taxonomy of
+// providers, categories and subcategories.
+type TaxonomyStore interface {
+	// GetTaxonomy returns the current taxonomy, or an empty one at revision
+	// 0 when none was ever stored.
+	GetTaxonomy(ctx context.Context) (*models.Taxonomy, error)
+	// PutTaxonomy replaces the taxonomy. Its Revision must be the revision
+	// it was read at, otherwise a ConflictError is returned. On success
+	// Revision and UpdatedAt are set to the stored values.
+	PutTaxonomy(ctx context.Context, taxonomy *models.Taxonomy) error
+}
+
+// ValidateComponent normalizes the provider, category and subcategory of
+// component against taxonomy, then validates it. Signed components must
+// already use canonical names, since normalizing them would invalidate their
+// signatures. Every issue is reported in a single ValidationError; when there
+// is none, the warnings are returned.
+func ValidateComponent(component *models.Component, taxonomy *models.Taxonomy) (models.ValidationErrors, error) {
+	var issues models.ValidationErrors
+	if taxonomy != nil {
+		if len(component.Metadata.Signatures) > 0 {
+			issues = taxonomy.CheckCanonical(component)
+		}
+		issues = append(issues, taxonomy.Normalize(component)...)
+	}
+	issues = append(models.NewComponentValidator().Issues(component), issues...)
+	if issues.HasErrors() {
+		return nil, NewComponentValidationError(issues)
+	}
+	return issues.Warnings(), nil
+}
+
+// ValidateTaxonomy checks a taxonomy before it is stored.
+func ValidateTaxonomy(taxonomy *models.Taxonomy) error {
+	if taxonomy == nil {
+		return NewValidationError("taxonomy", "taxonomy is required")
+	}
+	err := taxonomy.Validate()
+	if err == nil {
+		return nil
+	}
+	verr := NewValidationError("taxonomy", fmt.Sprintf("invalid taxonomy: %v", err)).WithCause(err)
+	var issues models.ValidationErrors
+	if errors.As(err, &issues) {
+		verr.WithDetail("errors", issues)
+	}
+	return verr
+}
+
+// NewRevisionConflictError reports a taxonomy update based on a stale
+// revision.
+func NewRevisionConflictError(expected, actual int64) *ConflictError {
+	return NewConflictError("taxonomy", fmt.Sprintf("revision %d is stale, current revision is %d", expected, actual)).
+		WithDetail("current_revision", actual)
+}
+
+// Normalized returns a copy of the filters where providers, categories and
+// subcategories are replaced by their canonical names, so that aliases match
+// the components they stand for. Subcategories are looked up in every
+// category. Unknown values are kept as given.
+func (f ComponentFilters) Normalized(taxonomy *models.Taxonomy) ComponentFilters {
+	if taxonomy == nil {
+		return f
+	}
+
+	normalize := func(values []string, resolve func(string) (string, bool)) []string {
+		if values == nil {
+			return nil
+		}
+		normalized := make([]string, len(values))
+		for i, value := range values {
+			normalized[i], _ = resolve(value)
+		}
+		return normalized
+	}
+
+	f.Providers = normalize(f.Providers, taxonomy.ResolveProvider)
+	f.Categories = normalize(f.Categories, taxonomy.ResolveCategory)
+	f.SubCategories = normalize(f.SubCategories, func(value string) (string, bool) {
+		for _, category := range taxonomy.Categories {
+			if name, ok := taxonomy.ResolveSubCategory(category.Name, value); ok && name != value {
+				return name, true
+			}
+		}
+		return value, false
+	})
+	return f
+}
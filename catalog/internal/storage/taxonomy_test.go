@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+func testTaxonomy() *models.Taxonomy {
+	return &models.Taxonomy{
+		Providers: []models.TaxonomyTerm{
+			{Name: "aws", DisplayName: "Amazon Web Services", Aliases: []string{"amazon"}},
+		},
+		Categories: []models.TaxonomyCategory{{
+			TaxonomyTerm:  models.TaxonomyTerm{Name: "database", DisplayName: "Databases", Aliases: []string{"db"}},
+			SubCategories: []models.TaxonomyTerm{{Name: "relational", DisplayName: "Relational", Aliases: []string{"sql"}}},
+		}},
+	}
+}
+
+func TestComponentFilters_Normalized(t *testing.T) {
+	filters := ComponentFilters{
+		Providers:     []string{"Amazon", "gcp"},
+		Categories:    []string{"DB"},
+		SubCategories: []string{"sql"},
+	}
+
+	normalized := filters.Normalized(testTaxonomy())
+	assert.Equal(t, []string{"aws", "gcp"}, normalized.Providers)
+	assert.Equal(t, []string{"database"}, normalized.Categories)
+	assert.Equal(t, []string{"relational"}, normalized.SubCategories)
+	assert.Equal(t, []string{"Amazon", "gcp"}, filters.Providers)
+
+	assert.Equal(t, filters, filters.Normalized(nil))
+	assert.Nil(t, ComponentFilters{}.Normalized(testTaxonomy()).Providers)
+}
+
+func TestValidateComponent(t *testing.T) {
+	component := &models.Component{
+		Name:        "postgres",
+		Version:     "1.0.0",
+		Provider:    "amazon",
+		Category:    "db",
+		SubCategory: "nosql",
+		Inputs:      []models.InputSpec{{Name: "db_name", Type: "string", Description: "Database name"}},
+		Outputs:     []models.OutputSpec{{Name: "endpoint", Type: "string", Description: "Endpoint"}},
+		Deployment:  models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+	}
+
+	_, err := ValidateComponent(component, testTaxonomy())
+	require.ErrorIs(t, err, ErrValidation)
+	var issues models.ValidationErrors
+	require.ErrorAs(t, err, &issues)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "sub_category", issues[0].Path)
+	assert.Equal(t, "aws", component.Provider)
+
+	component.SubCategory = "SQL"
+	warnings, err := ValidateComponent(component, testTaxonomy())
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "relational", component.SubCategory)
+
+	component.Inputs = append(component.Inputs, models.InputSpec{Name: "password", Type: "string", Description: "Password", Sensitive: true, Default: "hunter2"})
+	warnings, err = ValidateComponent(component, testTaxonomy())
+	require.NoError(t, err)
+	require.Len(t, warnings, 1, "warnings are returned instead of rejecting the component")
+	assert.Equal(t, "inputs[1].default", warnings[0].Path)
+}
+
+func TestValidateTaxonomy(t *testing.T) {
+	require.NoError(t, ValidateTaxonomy(testTaxonomy()))
+
+	taxonomy := testTaxonomy()
+	taxonomy.Providers[0].Aliases = append(taxonomy.Providers[0].Aliases, "AWS")
+	err := ValidateTaxonomy(taxonomy)
+	require.ErrorIs(t, err, ErrValidation)
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Details["errors"], 1)
+}
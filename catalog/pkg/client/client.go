@@ -0,0 +1,302 @@
+// Package client is a Go client for the catalog HTTP API.
+//
+// Deprecated component versions are served with Deprecation, Sunset and
+// Warning headers, and lists of versions with a warnings field. The client
+// turns them into models.Warning values and hands them to the
+// WarningHandler set with WithWarningHandler, so callers notice
+// deprecations and sunset dates without inspecting every response.
+//
+// Client implements resolver.Reader, so dependencies can be resolved against
+// a remote catalog:
+//
+//	c := client.New("https://catalog.example.com", client.WithWarningHandler(func(w models.Warning) {
+//		log.Printf("warning: %s", w.Message)
+//	}))
+//	resolution, err := resolver.New(c, resolver.Options{}).Resolve(ctx, "postgres", "^1.2.0")
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+// Headers read and set by the client. They match those of the catalog API.
+const (
+	userHeader        = "X-Nestor-User"
+	deprecationHeader = "Deprecation"
+	sunsetHeader      = "Sunset"
+	warningHeader     = "Warning"
+)
+
+// codeIntegrity is the API error code of components failing their integrity
+// check.
+const codeIntegrity = "INTEGRITY_ERROR"
+
+// WarningHandler is called with every warning returned by the catalog.
+type WarningHandler func(warning models.Warning)
+
+// Client calls the catalog API. It is safe for concurrent use.
+type Client struct {
+	baseURL   string
+	http      *http.Client
+	user      string
+	onWarning WarningHandler
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. The default is
+// http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithUser makes requests on behalf of user, for catalogs reached without
+// an authenticating proxy setting the caller.
+func WithUser(user string) Option {
+	return func(c *Client) {
+		c.user = user
+	}
+}
+
+// WithWarningHandler sets the handler called with the warnings of every
+// response. Warnings are dropped when no handler is set.
+func WithWarningHandler(handler WarningHandler) Option {
+	return func(c *Client) {
+		c.onWarning = handler
+	}
+}
+
+// New creates a Client for the catalog served at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is an error returned by the catalog API.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    map[string]any
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("catalog returned %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is matches models.ErrNotFound for every 404, and
+// models.ErrChecksumMismatch for components failing their integrity check,
+// as resolver.Reader requires.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case models.ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case models.ErrChecksumMismatch:
+		return e.Code == codeIntegrity
+	}
+	return false
+}
+
+// GetComponent returns a component version.
+func (c *Client) GetComponent(ctx context.Context, name, version string) (*models.Component, error) {
+	var component models.Component
+	if err := c.do(ctx, http.MethodGet, versionPath(name, version), nil, &component, name, version); err != nil {
+		return nil, err
+	}
+	return &component, nil
+}
+
+// GetVersionHistory returns the versions of a component, latest first.
+func (c *Client) GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error) {
+	var history struct {
+		Versions []models.ComponentVersion `json:"versions"`
+	}
+	if err := c.do(ctx, http.MethodGet, componentPath(name)+"/versions", nil, &history, name, ""); err != nil {
+		return nil, err
+	}
+	return history.Versions, nil
+}
+
+// DeprecateVersion deprecates a component version and returns it.
+func (c *Client) DeprecateVersion(ctx context.Context, name, version string, deprecation models.Deprecation) (*models.Component, error) {
+	var component models.Component
+	if err := c.do(ctx, http.MethodPost, versionPath(name, version)+"/deprecate", &deprecation, &component, name, version); err != nil {
+		return nil, err
+	}
+	return &component, nil
+}
+
+// DeprecateComponent deprecates every published version of a component and
+// returns them, latest first.
+func (c *Client) DeprecateComponent(ctx context.Context, name string, deprecation models.Deprecation) ([]*models.Component, error) {
+	var deprecated struct {
+		Components []*models.Component `json:"components"`
+	}
+	if err := c.do(ctx, http.MethodPost, componentPath(name)+"/deprecate", &deprecation, &deprecated, name, ""); err != nil {
+		return nil, err
+	}
+	return deprecated.Components, nil
+}
+
+// do sends a request with body encoded as JSON and decodes the response
+// into out. name and version identify the component the warnings of the
+// response are about.
+func (c *Client) do(ctx context.Context, method, path string, body, out any, name, version string) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.ToJSON(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.user != "" {
+		req.Header.Set(userHeader, c.user)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response of %s %s: %w", method, path, err)
+	}
+
+	if c.onWarning != nil {
+		for _, warning := range responseWarnings(resp.Header, data, name, version) {
+			c.onWarning(warning)
+		}
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var body struct {
+			Error struct {
+				Code    string         `json:"code"`
+				Message string         `json:"message"`
+				Details map[string]any `json:"details"`
+			} `json:"error"`
+		}
+		if json.FromJSON(data, &body) == nil && body.Error.Code != "" {
+			apiErr.Code = body.Error.Code
+			apiErr.Message = body.Error.Message
+			apiErr.Details = body.Error.Details
+		}
+		return apiErr
+	}
+
+	if err := json.FromJSON(data, out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// ParseWarnings reads the warnings carried by the headers of a response
+// about the component version name@version, as of now. The Warning header
+// provides their messages and the Deprecation and Sunset headers their
+// dates. A version past its sunset date is reported with the code
+// models.WarningSunset.
+func ParseWarnings(header http.Header, name, version string, now time.Time) []models.Warning {
+	messages := header.Values(warningHeader)
+	deprecation := header.Get(deprecationHeader)
+	if len(messages) == 0 && deprecation == "" {
+		return nil
+	}
+
+	template := models.Warning{
+		Code:      models.WarningDeprecated,
+		Component: name,
+		Version:   version,
+	}
+	if seconds, ok := strings.CutPrefix(deprecation, "@"); ok {
+		if unix, err := strconv.ParseInt(seconds, 10, 64); err == nil {
+			deprecatedAt := time.Unix(unix, 0).UTC()
+			template.DeprecatedAt = &deprecatedAt
+		}
+	}
+	if sunset, err := http.ParseTime(header.Get(sunsetHeader)); err == nil {
+		template.SunsetAt = &sunset
+		if !now.Before(sunset) {
+			template.Code = models.WarningSunset
+		}
+	}
+
+	if len(messages) == 0 {
+		template.Message = fmt.Sprintf("%s:%s is deprecated", name, version)
+		return []models.Warning{template}
+	}
+	warnings := make([]models.Warning, 0, len(messages))
+	for _, message := range messages {
+		warning := template
+		warning.Message = warningText(message)
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// responseWarnings returns the warnings of a response. Responses about
+// several versions, such as lists, carry them in their warnings field;
+// the headers are parsed for the others.
+func responseWarnings(header http.Header, data []byte, name, version string) []models.Warning {
+	var body struct {
+		Warnings []models.Warning `json:"warnings"`
+	}
+	if json.FromJSON(data, &body) == nil && len(body.Warnings) > 0 {
+		return body.Warnings
+	}
+	return ParseWarnings(header, name, version, time.Now())
+}
+
+// warningText extracts the quoted text of a Warning header value formatted
+// as `299 - "text"`. Values in another format are returned as is.
+func warningText(value string) string {
+	parts := strings.SplitN(value, " ", 3)
+	if len(parts) < 3 {
+		return value
+	}
+	text, err := strconv.Unquote(parts[2])
+	if err != nil {
+		return value
+	}
+	return text
+}
+
+func componentPath(name string) string {
+	return "/api/v1/components/" + url.PathEscape(name)
+}
+
+func versionPath(name, version string) string {
+	return componentPath(name) + "/versions/" + url.PathEscape(version)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/api/handlers"
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/catalog/pkg/resolver"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+const maintainer = "platform@example.com"
+
+func component(name, version string) *models.Component {
+	return &models.Component{
+		Name:        name,
+		Version:     version,
+		Provider:    "aws",
+		Category:    "networking",
+		Maintainers: []string{maintainer},
+		Deployment:  models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+	}
+}
+
+func newCatalog(t *testing.T, components ...*models.Component) string {
+	t.Helper()
+	store := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), components))
+
+	mux := http.NewServeMux()
+	handlers.NewComponentHandler(store, logging.NewNoop()).Register(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// recorder collects the warnings passed to its handler.
+type recorder struct {
+	mu       sync.Mutex
+	warnings []models.Warning
+}
+
+func (r *recorder) handle(warning models.Warning) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, warning)
+}
+
+func (r *recorder) take() []models.Warning {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	warnings := r.warnings
+	r.warnings = nil
+	return warnings
+}
+
+func TestClient_DeprecationWarnings(t *testing.T) {
+	ctx := context.Background()
+	warnings := &recorder{}
+	c := New(newCatalog(t, component("vpc", "1.0.0"), component("vpc", "1.1.0")),
+		WithUser(maintainer), WithWarningHandler(warnings.handle))
+
+	fetched, err := c.GetComponent(ctx, "vpc", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "vpc", fetched.Name)
+	assert.Empty(t, warnings.take())
+
+	sunset := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+	deprecated, err := c.DeprecateVersion(ctx, "vpc", "1.0.0", models.Deprecation{
+		Reason:      "Use the shared VPC",
+		Replacement: &models.Replacement{Name: "shared-vpc"},
+		SunsetAt:    &sunset,
+	})
+	require.NoError(t, err)
+	assert.True(t, deprecated.IsDeprecated())
+	require.Len(t, warnings.take(), 1, "the deprecated version is returned with its warning")
+
+	_, err = c.GetComponent(ctx, "vpc", "1.0.0")
+	require.NoError(t, err)
+	received := warnings.take()
+	require.Len(t, received, 1)
+	assert.Equal(t, models.WarningDeprecated, received[0].Code)
+	assert.Equal(t, "vpc", received[0].Component)
+	assert.Equal(t, "1.0.0", received[0].Version)
+	assert.Equal(t, "vpc:1.0.0 is deprecated: Use the shared VPC; use shared-vpc instead; it will be sunset on "+sunset.Format(time.DateOnly), received[0].Message)
+	require.NotNil(t, received[0].DeprecatedAt)
+	assert.WithinDuration(t, time.Now(), *received[0].DeprecatedAt, time.Minute)
+	assert.Equal(t, sunset, *received[0].SunsetAt)
+
+	history, err := c.GetVersionHistory(ctx, "vpc")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.VersionStatusDeprecated, history[1].Status)
+	assert.Equal(t, sunset, history[1].SunsetAt.UTC())
+	received = warnings.take()
+	require.Len(t, received, 1, "histories carry the warnings of their versions")
+	assert.Equal(t, "1.0.0", received[0].Version)
+	assert.Equal(t, sunset, received[0].SunsetAt.UTC())
+
+	all, err := c.DeprecateComponent(ctx, "vpc", models.Deprecation{Reason: "Use the shared VPC"})
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestClient_Errors(t *testing.T) {
+	ctx := context.Background()
+	c := New(newCatalog(t, component("vpc", "1.0.0")))
+
+	_, err := c.GetComponent(ctx, "vpc", "9.9.9")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, storage.CodeComponentNotFound, apiErr.Code)
+
+	_, err = c.DeprecateVersion(ctx, "vpc", "1.0.0", models.Deprecation{})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, storage.CodeValidation, apiErr.Code, "anonymous callers maintain nothing")
+	assert.NotErrorIs(t, err, models.ErrNotFound)
+
+	integrity := &APIError{StatusCode: http.StatusInternalServerError, Code: storage.CodeIntegrity}
+	assert.ErrorIs(t, integrity, models.ErrChecksumMismatch)
+}
+
+func TestClient_ResolvesAgainstTheCatalog(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	sunset := component("vpc", "1.1.0")
+	sunset.Metadata.Deprecated = true
+	sunset.Metadata.Deprecation = &models.Deprecation{SunsetAt: &past}
+	app := component("app", "1.0.0")
+	app.Dependencies = []models.Dependency{{Name: "vpc", Type: "component", Version: "^1.0.0"}}
+	c := New(newCatalog(t, app, component("vpc", "1.0.0"), sunset))
+
+	resolution, err := resolver.New(c, resolver.Options{}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", resolution.Versions()["vpc"], "versions past their sunset date are refused")
+
+	resolution, err = resolver.New(c, resolver.Options{AllowSunset: true}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", resolution.Versions()["vpc"])
+	require.Len(t, resolution.Warnings, 1)
+	assert.Equal(t, models.WarningSunset, resolution.Warnings[0].Code)
+}
+
+func TestParseWarnings(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Empty(t, ParseWarnings(http.Header{}, "vpc", "1.0.0", now))
+
+	header := http.Header{}
+	header.Set("Deprecation", "@1735689600")
+	header.Set("Sunset", "Sat, 01 Feb 2025 00:00:00 GMT")
+	header.Add("Warning", `299 - "vpc:1.0.0 is deprecated"`)
+	warnings := ParseWarnings(header, "vpc", "1.0.0", now)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, models.WarningSunset, warnings[0].Code)
+	assert.Equal(t, "vpc:1.0.0 is deprecated", warnings[0].Message)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *warnings[0].DeprecatedAt)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), *warnings[0].SunsetAt)
+
+	header = http.Header{}
+	header.Set("Deprecation", "@1735689600")
+	warnings = ParseWarnings(header, "vpc", "1.0.0", now)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, models.WarningDeprecated, warnings[0].Code)
+	assert.Equal(t, "vpc:1.0.0 is deprecated", warnings[0].Message)
+
+	header = http.Header{}
+	header.Add("Warning", "199 misc")
+	assert.Equal(t, "199 misc", ParseWarnings(header, "vpc", "1.0.0", now)[0].Message)
+}
@@ -0,0 +1,327 @@
+// Package lockfile pins the components a consumer deploys, the same way
+// go.sum pins modules.
+//
+// A lockfile records root constraints, such as postgres@^1.2.0, and the
+// exact versions and checksums they resolved to, dependencies included:
+//
+//	{
+//	  "lockfile_version": 1,
+//	  "roots": [{"name": "postgres", "constraint": "^1.2.0"}],
+//	  "components": [
+//	    {"name": "kms-key", "version": "2.1.0", "checksum": "sha256:…"},
+//	    {"name": "postgres", "version": "1.2.3", "checksum": "sha256:…", "dependencies": ["kms-key"]}
+//	  ]
+//	}
+//
+// Deploys read the pinned versions, Verify reports pins that were yanked,
+// deprecated or changed in the catalog, and Update is the explicit step that
+// moves pins forward.
+package lockfile
+
+import (
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/catalog/pkg/resolver"
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+// Version is the lockfile format written by this package.
+const Version = 1
+
+// Lock is the content of a lockfile.
+type Lock struct {
+	LockfileVersion int         `json:"lockfile_version"`
+	Roots           []Root      `json:"roots"`
+	Components      []Component `json:"components"`
+}
+
+// Root is a component requested by the consumer. An empty Constraint means
+// the latest version.
+type Root struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// Component is a component pinned to a version, with the checksum it had
+// when it was locked.
+type Component struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Checksum     string   `json:"checksum"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// Get returns the locked component called name.
+func (l *Lock) Get(name string) (*Component, bool) {
+	for i := range l.Components {
+		if l.Components[i].Name == name {
+			return &l.Components[i], true
+		}
+	}
+	return nil, false
+}
+
+// Versions maps every locked component name to its version.
+func (l *Lock) Versions() map[string]string {
+	versions := make(map[string]string, len(l.Components))
+	for _, component := range l.Components {
+		versions[component.Name] = component.Version
+	}
+	return versions
+}
+
+// Resolve resolves roots together against store and locks the result.
+func Resolve(ctx context.Context, store resolver.Reader, roots []Root, opts resolver.Options) (*Lock, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w: at least one root is required", resolver.ErrInvalidRequest)
+	}
+	constraints := make(map[string]string, len(roots))
+	for _, root := range roots {
+		if _, ok := constraints[root.Name]; ok {
+			return nil, fmt.Errorf("%w: component '%s' is requested twice", resolver.ErrInvalidRequest, root.Name)
+		}
+		constraints[root.Name] = root.Constraint
+	}
+
+	resolution, err := resolver.New(store, opts).ResolveAll(ctx, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{
+		LockfileVersion: Version,
+		Roots:           slices.Clone(roots),
+		Components:      make([]Component, 0, len(resolution.Components)),
+	}
+	slices.SortFunc(lock.Roots, func(a, b Root) int { return strings.Compare(a.Name, b.Name) })
+	for _, resolved := range resolution.Components {
+		checksum, err := checksumOf(resolved.Component)
+		if err != nil {
+			return nil, err
+		}
+		lock.Components = append(lock.Components, Component{
+			Name:         resolved.Name,
+			Version:      resolved.Version,
+			Checksum:     checksum,
+			Dependencies: resolved.Dependencies,
+		})
+	}
+	slices.SortFunc(lock.Components, func(a, b Component) int { return strings.Compare(a.Name, b.Name) })
+	return lock, nil
+}
+
+// Update resolves the roots of lock again, keeping the locked version of
+// every component except the named ones, which move to the highest version
+// the constraints allow. Locked versions are only given up when they
+// conflict with an updated component. Without names every component is
+// updated.
+func Update(ctx context.Context, lock *Lock, store resolver.Reader, opts resolver.Options, names ...string) (*Lock, error) {
+	for _, name := range names {
+		if _, ok := lock.Get(name); !ok {
+			return nil, fmt.Errorf("%w: %s is not locked", models.ErrNotFound, name)
+		}
+	}
+
+	if len(names) > 0 {
+		opts.Prefer = lock.Versions()
+		for _, name := range names {
+			delete(opts.Prefer, name)
+		}
+	}
+	return Resolve(ctx, store, lock.Roots, opts)
+}
+
+// IssueKind classifies the problems found by Verify.
+type IssueKind string
+
+const (
+	// IssueMissing means the locked version is not published anymore.
+	IssueMissing IssueKind = "missing"
+	// IssueYanked means the locked version was yanked.
+	IssueYanked IssueKind = "yanked"
+	// IssueDeprecated means the locked version was deprecated.
+	IssueDeprecated IssueKind = "deprecated"
+	// IssueSunset means the locked version is past its sunset date and is
+	// no longer resolved.
+	IssueSunset IssueKind = "sunset"
+	// IssueChanged means the locked version no longer has the locked
+	// checksum.
+	IssueChanged IssueKind = "changed"
+	// IssueUnlocked means a root is not locked at a version satisfying its
+	// constraint, because the lockfile was edited by hand.
+	IssueUnlocked IssueKind = "unlocked"
+)
+
+// Issue is a problem with a locked component.
+type Issue struct {
+	Name    string    `json:"name"`
+	Version string    `json:"version,omitempty"`
+	Kind    IssueKind `json:"kind"`
+	Message string    `json:"message"`
+}
+
+// Issues is the outcome of Verify.
+type Issues []Issue
+
+// Blocking reports whether any issue must stop a deploy, that is any issue
+// but a deprecation.
+func (i Issues) Blocking() bool {
+	return slices.ContainsFunc(i, func(issue Issue) bool { return issue.Kind != IssueDeprecated })
+}
+
+// Verify checks every locked component against store. The returned error
+// is only set when store cannot be read.
+func Verify(ctx context.Context, lock *Lock, store resolver.Reader) (Issues, error) {
+	var issues Issues
+
+	parser := models.NewConstraintParser()
+	for _, root := range lock.Roots {
+		locked, ok := lock.Get(root.Name)
+		if !ok {
+			issues = append(issues, Issue{Name: root.Name, Kind: IssueUnlocked, Message: fmt.Sprintf("root %s is not locked", root.Name)})
+			continue
+		}
+		if root.Constraint == "" {
+			continue
+		}
+		constraint, err := parser.Parse(root.Constraint)
+		if err != nil {
+			issues = append(issues, Issue{Name: root.Name, Kind: IssueUnlocked, Message: fmt.Sprintf("root %s has an invalid constraint: %v", root.Name, err)})
+			continue
+		}
+		info, err := models.ParseSemanticVersion(locked.Version)
+		if err != nil || !constraint.Satisfies(info) {
+			issues = append(issues, Issue{Name: root.Name, Version: locked.Version, Kind: IssueUnlocked,
+				Message: fmt.Sprintf("%s@%s does not satisfy %s", root.Name, locked.Version, root.Constraint)})
+		}
+	}
+
+	for _, locked := range lock.Components {
+		issue, err := verifyComponent(ctx, locked, store)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues, nil
+}
+
+func verifyComponent(ctx context.Context, locked Component, store resolver.Reader) (*Issue, error) {
+	id := locked.Name + "@" + locked.Version
+	issue := func(kind IssueKind, format string, args ...any) *Issue {
+		return &Issue{Name: locked.Name, Version: locked.Version, Kind: kind, Message: fmt.Sprintf(format, args...)}
+	}
+
+	component, err := store.GetComponent(ctx, locked.Name, locked.Version)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return issue(IssueMissing, "%s is not published", id), nil
+	case errors.Is(err, models.ErrChecksumMismatch):
+		return issue(IssueChanged, "%s failed its integrity check in the catalog", id), nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", id, err)
+	}
+
+	checksum, err := checksumOf(component)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != locked.Checksum {
+		return issue(IssueChanged, "%s was locked as %s but is now %s", id, locked.Checksum, checksum), nil
+	}
+
+	if component.IsSunset(time.Now()) {
+		return issue(IssueSunset, "%s is past its sunset date of %s", id, component.SunsetAt().UTC().Format(time.DateOnly)), nil
+	}
+
+	history, err := store.GetVersionHistory(ctx, locked.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions of %s: %w", locked.Name, err)
+	}
+	for _, version := range history {
+		if version.Version != locked.Version {
+			continue
+		}
+		switch version.Status {
+		case models.VersionStatusYanked:
+			return issue(IssueYanked, "%s was yanked", id), nil
+		case models.VersionStatusDeprecated:
+			return issue(IssueDeprecated, "%s is deprecated", id), nil
+		}
+	}
+	if component.IsDeprecated() {
+		return issue(IssueDeprecated, "%s is deprecated", id), nil
+	}
+	return nil, nil
+}
+
+// checksumOf returns the recorded checksum of component, or computes it for
+// components published before checksums were recorded.
+func checksumOf(component *models.Component) (string, error) {
+	if component.Metadata.Checksum != "" {
+		return component.Metadata.Checksum, nil
+	}
+	return models.ComputeChecksum(component)
+}
+
+// Parse decodes a lockfile.
+func Parse(data []byte) (*Lock, error) {
+	var lock Lock
+	if err := json.FromJSON(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid lockfile: %w", err)
+	}
+	if lock.LockfileVersion != Version {
+		return nil, fmt.Errorf("unsupported lockfile version %d", lock.LockfileVersion)
+	}
+	return &lock, nil
+}
+
+// Marshal encodes the lockfile, indented so that changes diff well.
+func (l *Lock) Marshal() ([]byte, error) {
+	data, err := json.ToJSON(l)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := stdjson.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// Load reads a lockfile from path.
+func Load(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	lock, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return lock, nil
+}
+
+// Save writes the lockfile to path.
+func (l *Lock) Save(path string) error {
+	data, err := l.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package lockfile
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/catalog/pkg/resolver"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// component builds a component from "name@version" followed by its
+// dependencies as "name@constraint".
+func component(id string, deps ...string) *models.Component {
+	name, version, _ := strings.Cut(id, "@")
+	c := &models.Component{
+		Name:       name,
+		Version:    version,
+		Provider:   "aws",
+		Category:   "networking",
+		Deployment: models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+	}
+	for _, dep := range deps {
+		depName, constraint, _ := strings.Cut(dep, "@")
+		c.Dependencies = append(c.Dependencies, models.Dependency{Name: depName, Type: "component", Version: constraint})
+	}
+	return c
+}
+
+// statusStore reports the versions listed in statuses with that status.
+type statusStore struct {
+	storage.ComponentStore
+	statuses map[string]models.VersionStatus
+}
+
+func (s *statusStore) GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error) {
+	history, err := s.ComponentStore.GetVersionHistory(ctx, name)
+	for i, version := range history {
+		if status, ok := s.statuses[version.GetID()]; ok {
+			history[i].Status = status
+		}
+	}
+	return history, err
+}
+
+func newStore(t *testing.T, components ...*models.Component) *statusStore {
+	t.Helper()
+	store := memory.NewComponentStore(logging.NewNoop())
+	for _, c := range components {
+		require.NoError(t, c.SetChecksum())
+	}
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), components))
+	return &statusStore{ComponentStore: store, statuses: map[string]models.VersionStatus{}}
+}
+
+func put(t *testing.T, store *statusStore, c *models.Component) {
+	t.Helper()
+	require.NoError(t, c.SetChecksum())
+	require.NoError(t, store.ComponentStore.(storage.BulkWriter).PutComponents(context.Background(), []*models.Component{c}))
+}
+
+func TestResolve(t *testing.T) {
+	store := newStore(t,
+		component("api@1.0.0", "db@^2.0.0", "vpc@^1.0.0"),
+		component("worker@1.0.0", "db@<2.2.0"),
+		component("db@2.1.0", "vpc@^1.0.0"),
+		component("db@2.2.0", "vpc@^1.0.0"),
+		component("vpc@1.0.0"),
+	)
+
+	lock, err := Resolve(context.Background(), store, []Root{{Name: "worker"}, {Name: "api", Constraint: "^1.0.0"}}, resolver.Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, Version, lock.LockfileVersion)
+	assert.Equal(t, []Root{{Name: "api", Constraint: "^1.0.0"}, {Name: "worker"}}, lock.Roots)
+	assert.Equal(t, map[string]string{"api": "1.0.0", "worker": "1.0.0", "db": "2.1.0", "vpc": "1.0.0"}, lock.Versions())
+
+	db, ok := lock.Get("db")
+	require.True(t, ok)
+	assert.Equal(t, []string{"vpc"}, db.Dependencies)
+	assert.True(t, strings.HasPrefix(db.Checksum, models.ChecksumPrefix))
+
+	issues, err := Verify(context.Background(), lock, store)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	_, err = Resolve(context.Background(), store, []Root{{Name: "api"}, {Name: "api"}}, resolver.Options{})
+	assert.ErrorIs(t, err, resolver.ErrInvalidRequest)
+}
+
+func TestVerify(t *testing.T) {
+	store := newStore(t,
+		component("app@1.0.0", "db@^2.0.0", "vpc@^1.0.0", "dns@^1.0.0", "cache@^1.0.0", "queue@^1.0.0"),
+		component("db@2.0.0"),
+		component("vpc@1.0.0"),
+		component("dns@1.0.0"),
+		component("cache@1.0.0"),
+		component("queue@1.0.0"),
+	)
+	lock, err := Resolve(context.Background(), store, []Root{{Name: "app", Constraint: "^1.0.0"}}, resolver.Options{})
+	require.NoError(t, err)
+
+	store.statuses["db:2.0.0"] = models.VersionStatusYanked
+	store.statuses["vpc:1.0.0"] = models.VersionStatusDeprecated
+	changed := component("dns@1.0.0")
+	changed.Description = "Republished with another definition"
+	put(t, store, changed)
+	sunset := component("queue@1.0.0")
+	sunsetAt := time.Now().Add(-time.Hour)
+	sunset.Metadata.Deprecated = true
+	sunset.Metadata.Deprecation = &models.Deprecation{SunsetAt: &sunsetAt}
+	put(t, store, sunset)
+	lock.Components = append(lock.Components, Component{Name: "gone", Version: "1.0.0", Checksum: "sha256:00"})
+	lock.Roots = append(lock.Roots, Root{Name: "cache", Constraint: "^2.0.0"})
+
+	issues, err := Verify(context.Background(), lock, store)
+	require.NoError(t, err)
+
+	kinds := make(map[string]IssueKind, len(issues))
+	for _, issue := range issues {
+		kinds[issue.Name] = issue.Kind
+	}
+	assert.Equal(t, map[string]IssueKind{
+		"cache": IssueUnlocked,
+		"db":    IssueYanked,
+		"dns":   IssueChanged,
+		"gone":  IssueMissing,
+		"queue": IssueSunset,
+		"vpc":   IssueDeprecated,
+	}, kinds)
+	assert.True(t, issues.Blocking())
+	assert.False(t, Issues{{Kind: IssueDeprecated}}.Blocking())
+}
+
+func TestUpdate(t *testing.T) {
+	store := newStore(t,
+		component("app@1.0.0", "db@^2.0.0", "vpc@^1.0.0"),
+		component("db@2.0.0"),
+		component("vpc@1.0.0"),
+	)
+	lock, err := Resolve(context.Background(), store, []Root{{Name: "app"}}, resolver.Options{})
+	require.NoError(t, err)
+
+	put(t, store, component("db@2.1.0", "vpc@^1.1.0"))
+	put(t, store, component("vpc@1.1.0"))
+	put(t, store, component("vpc@1.2.0"))
+
+	updated, err := Update(context.Background(), lock, store, resolver.Options{}, "app")
+	require.NoError(t, err)
+	assert.Equal(t, lock.Versions(), updated.Versions(), "unrelated pins are kept")
+
+	updated, err = Update(context.Background(), lock, store, resolver.Options{}, "db")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "1.0.0", "db": "2.1.0", "vpc": "1.2.0"}, updated.Versions(),
+		"vpc moves because the updated db rules out its pin")
+
+	updated, err = Update(context.Background(), lock, store, resolver.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "1.0.0", "db": "2.1.0", "vpc": "1.2.0"}, updated.Versions())
+
+	put(t, store, component("db@2.2.0"))
+	pinned, err := Update(context.Background(), updated, store, resolver.Options{}, "vpc")
+	require.NoError(t, err)
+	assert.Equal(t, updated.Versions(), pinned.Versions(), "db stays pinned")
+	pinned, err = Update(context.Background(), updated, store, resolver.Options{}, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "2.2.0", pinned.Versions()["db"])
+
+	_, err = Update(context.Background(), lock, store, resolver.Options{}, "cache")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	store := newStore(t, component("app@1.0.0", "vpc@^1.0.0"), component("vpc@1.0.0"))
+	lock, err := Resolve(context.Background(), store, []Root{{Name: "app", Constraint: "^1.0.0"}}, resolver.Options{})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "nestor.lock")
+	require.NoError(t, lock.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, lock, loaded)
+
+	data, err := lock.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  \"roots\": [\n")
+
+	_, err = Parse([]byte(`{"lockfile_version": 2}`))
+	assert.ErrorContains(t, err, "unsupported lockfile version 2")
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+// ChecksumPrefix names the digest algorithm of component checksums
+const ChecksumPrefix = "sha256:"
+
+// ErrChecksumMismatch is returned when a component no longer matches the
+// checksum it was published with
+var ErrChecksumMismatch = errors.New("component checksum mismatch")
+
+// ComputeChecksum returns the SHA-256 digest of the canonical JSON encoding
+// of the component definition, as "sha256:<hex>".
+//
+// Only the definition is covered: timestamps, the deprecation state and the
+// checksum itself are left out, so they can change without invalidating it.
+// Nulls and empty collections are dropped, so backends that store nil and
+// empty collections differently agree.
+func ComputeChecksum(component *Component) (string, error) {
+	definition := *component
+	definition.Metadata = ComponentMetadata{GitCommit: component.Metadata.GitCommit}
+
+	digest, err := CanonicalDigest(&definition, "created_at", "updated_at")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode component %s: %w", component.GetID(), err)
+	}
+	return ChecksumPrefix + digest, nil
+}
+
+// CanonicalDigest returns the hex SHA-256 digest of the canonical JSON
+// encoding of v, without its nulls, its empty collections and the top-level
+// fields in omit. Numbers are decoded exactly, so integers beyond 2^53 keep
+// their precision. Values differing only by nil and empty collections or by
+// the order of their keys have the same digest
+func CanonicalDigest(v any, omit ...string) (string, error) {
+	data, err := json.ToJSON(v)
+	if err != nil {
+		return "", err
+	}
+	var tree any
+	if err := json.FromCanonicalJSON(data, &tree); err != nil {
+		return "", err
+	}
+	if object, ok := tree.(map[string]any); ok {
+		for _, field := range omit {
+			delete(object, field)
+		}
+	}
+
+	canonical, err := json.ToCanonicalJSON(pruneEmpty(tree))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SetChecksum computes the checksum of the component and records it in
+// Metadata.Checksum
+func (c *Component) SetChecksum() error {
+	checksum, err := ComputeChecksum(c)
+	if err != nil {
+		return err
+	}
+	c.Metadata.Checksum = checksum
+	return nil
+}
+
+// VerifyChecksum checks the component against Metadata.Checksum. Components
+// published before checksums were recorded have none and always pass.
+func (c *Component) VerifyChecksum() error {
+	if c.Metadata.Checksum == "" {
+		return nil
+	}
+	checksum, err := ComputeChecksum(c)
+	if err != nil {
+		return err
+	}
+	if checksum != c.Metadata.Checksum {
+		return fmt.Errorf("%w: %s was published as %s but is now %s", ErrChecksumMismatch, c.GetID(), c.Metadata.Checksum, checksum)
+	}
+	return nil
+}
+
+// pruneEmpty drops nulls, empty arrays and empty objects from a decoded JSON
+// tree. Empty strings are values and are kept
+func pruneEmpty(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			if child = pruneEmpty(child); !isEmptyJSON(child) {
+				out[key] = child
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, child := range v {
+			out = append(out, pruneEmpty(child))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func isEmptyJSON(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
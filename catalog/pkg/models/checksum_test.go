@@ -0,0 +1,81 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeChecksum(t *testing.T) {
+	component := validRelationsComponent()
+	component.Deployment.Config["retries"] = 3
+
+	checksum, err := ComputeChecksum(component)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(checksum, ChecksumPrefix))
+	assert.Len(t, checksum, len(ChecksumPrefix)+64)
+
+	same := validRelationsComponent()
+	same.Deployment.Config["retries"] = 3.0
+	same.AdditionalDeployments = []DeploymentSpec{}
+	same.Annotations = map[string]string{"nestor.io/owner-slack": "#platform"}
+	same.CreatedAt = time.Now()
+	same.UpdatedAt = time.Now()
+	same.Metadata.Deprecated = true
+	same.Metadata.Checksum = "sha256:previous"
+	sameChecksum, err := ComputeChecksum(same)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, sameChecksum, "representation, timestamps and lifecycle state are not covered")
+
+	changed := validRelationsComponent()
+	changed.Deployment.Config["retries"] = 4
+	changedChecksum, err := ComputeChecksum(changed)
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, changedChecksum)
+
+	changed = validRelationsComponent()
+	changed.Deployment.Config["retries"] = 3
+	changed.Inputs[0].Description = "Database name."
+	changedChecksum, err = ComputeChecksum(changed)
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, changedChecksum)
+}
+
+func TestComponent_VerifyChecksum(t *testing.T) {
+	component := validRelationsComponent()
+	assert.NoError(t, component.VerifyChecksum(), "components without a checksum pass")
+
+	require.NoError(t, component.SetChecksum())
+	assert.NoError(t, component.VerifyChecksum())
+
+	component.Metadata.Deprecated = true
+	assert.NoError(t, component.VerifyChecksum())
+
+	component.Deployment.Version = "1.6.0"
+	err := component.VerifyChecksum()
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.Contains(t, err.Error(), "postgres:1.0.0 was published as sha256:")
+}
+
+func TestComputeChecksum_PreservesValues(t *testing.T) {
+	checksum := func(mutate func(*Component)) string {
+		component := validRelationsComponent()
+		mutate(component)
+		sum, err := ComputeChecksum(component)
+		require.NoError(t, err)
+		return sum
+	}
+
+	assert.NotEqual(t,
+		checksum(func(c *Component) { c.Deployment.Config["max_id"] = int64(9007199254740993) }),
+		checksum(func(c *Component) { c.Deployment.Config["max_id"] = int64(9007199254740992) }),
+		"integers beyond 2^53 are not rounded")
+
+	assert.NotEqual(t,
+		checksum(func(c *Component) { c.Inputs[0].Default = "" }),
+		checksum(func(c *Component) { c.Inputs[0].Default = nil }),
+		"an explicit empty string differs from an absent value")
+}
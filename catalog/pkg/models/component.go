@@ -1,33 +1,87 @@
 package models
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/HatiCode/nestor/shared/pkg/json"
 	"github.com/go-playground/validator/v10"
 )
 
-// Component represents a simplified infrastructure component definition for the MVP
+// Component represents a simplified infrastructure component definition for the MVP.
+// Deployment is the default engine; AdditionalDeployments declares the same
+// component for other engines.
 type Component struct {
-	Name        string            `json:"name" validate:"required,dns1123"`
-	Version     string            `json:"version" validate:"required,semver"`
-	Provider    string            `json:"provider" validate:"required"`
-	Category    string            `json:"category" validate:"required"`
-	Description string            `json:"description"`
-	Inputs      []InputSpec       `json:"inputs" validate:"required,min=1"`
-	Outputs     []OutputSpec      `json:"outputs" validate:"required,min=1"`
-	Deployment  DeploymentSpec    `json:"deployment" validate:"required"`
-	Metadata    ComponentMetadata `json:"metadata"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	Name                  string            `json:"name" validate:"required,dns1123"`
+	Version               string            `json:"version" validate:"required,semver"`
+	Provider              string            `json:"provider" validate:"required"`
+	Category              string            `json:"category" validate:"required"`
+	SubCategory           string            `json:"sub_category,omitempty"`
+	Description           string            `json:"description"`
+	Maintainers           []string          `json:"maintainers,omitempty" validate:"dive,required"`
+	Documentation         []DocLink         `json:"documentation,omitempty" validate:"dive"`
+	Labels                map[string]string `json:"labels,omitempty"`
+	Annotations           map[string]string `json:"annotations,omitempty"`
+	Inputs                []InputSpec       `json:"inputs" validate:"required,min=1"`
+	Outputs               []OutputSpec      `json:"outputs" validate:"required,min=1"`
+	Deployment            DeploymentSpec    `json:"deployment" validate:"required"`
+	AdditionalDeployments []DeploymentSpec  `json:"additional_deployments,omitempty" validate:"dive"`
+	Dependencies          []Dependency      `json:"dependencies,omitempty" validate:"dive"`
+	Provides              []string          `json:"provides,omitempty" validate:"dive,dns1123"`
+	ConflictsWith         []string          `json:"conflicts_with,omitempty" validate:"dive,dns1123"`
+	Metadata              ComponentMetadata `json:"metadata"`
+	CreatedAt             time.Time         `json:"created_at"`
+	UpdatedAt             time.Time         `json:"updated_at"`
 }
 
+// maxLabelValueLength bounds label values so they stay usable as filter keys
+const maxLabelValueLength = 63
+
 // ComponentMetadata contains additional metadata for the component
 type ComponentMetadata struct {
 	GitCommit    string     `json:"git_commit,omitempty"`
 	Deprecated   bool       `json:"deprecated"`
 	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+	// Deprecation details the deprecation, set by the catalog when the
+	// version is deprecated
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// Checksum is set when the component is published, see ComputeChecksum
+	Checksum string `json:"checksum,omitempty"`
+	// Signatures sign Checksum, see package signing
+	Signatures []Signature `json:"signatures,omitempty"`
+	// Status is the lifecycle state of the version, set by the catalog. New
+	// versions are drafts until promoted; empty means active
+	Status VersionStatus `json:"status,omitempty"`
+	// PublishedBy is the user who stored the version, set by the catalog
+	PublishedBy string `json:"published_by,omitempty"`
+	// Approvals are recorded by the catalog while the version is a draft
+	Approvals []Approval `json:"approvals,omitempty"`
+}
+
+// Approval records a maintainer approving a draft. It only holds for the
+// definition approved: it expires when the draft changes
+type Approval struct {
+	By string    `json:"by"`
+	At time.Time `json:"at"`
+	// Checksum is the checksum of the draft when it was approved
+	Checksum string `json:"checksum"`
+}
+
+// Signature is an ed25519 signature of the checksum of a component
+type Signature struct {
+	KeyID string `json:"key_id"`
+	// Publisher owns the key; it is recorded by the catalog from its trusted
+	// key set rather than taken from the publisher
+	Publisher string `json:"publisher,omitempty"`
+	// Value is the base64 encoded signature
+	Value string `json:"value"`
 }
 
 // InputSpec defines an input parameter specification
@@ -38,6 +92,9 @@ type InputSpec struct {
 	Default     any        `json:"default,omitempty"`
 	Validation  Validation `json:"validation"`
 	Sensitive   bool       `json:"sensitive"`
+	// RequiredIf is an Expression making the input required when it holds,
+	// such as "multi_az == true"
+	RequiredIf string `json:"required_if,omitempty"`
 }
 
 // OutputSpec defines an output parameter specification
@@ -92,7 +149,7 @@ type DocLink struct {
 }
 
 type Dependency struct {
-	Name        string `json:"name" validate:"required"`
+	Name        string `json:"name" validate:"required,dns1123"`
 	Type        string `json:"type" validate:"required"`
 	Version     string `json:"version" validate:"required"`
 	Optional    bool   `json:"optional"`
@@ -103,21 +160,40 @@ type Dependency struct {
 // ComponentValidator provides validation functionality for components
 type ComponentValidator struct {
 	validator *validator.Validate
+	engines   *EngineRegistry
 }
 
 // NewComponentValidator creates a new component validator with custom validation rules
 func NewComponentValidator() *ComponentValidator {
 	v := validator.New()
 
+	// Report fields by their JSON names
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
 	// Register custom validation functions
 	v.RegisterValidation("semver", validateSemanticVersion)
+	v.RegisterValidation("semver_constraint", validateVersionConstraint)
 	v.RegisterValidation("dns1123", validateDNS1123)
 
 	return &ComponentValidator{
 		validator: v,
+		engines:   DefaultEngineRegistry,
 	}
 }
 
+// WithEngineRegistry makes the validator check deployment configs against
+// the schemas of registry instead of DefaultEngineRegistry
+func (cv *ComponentValidator) WithEngineRegistry(registry *EngineRegistry) *ComponentValidator {
+	cv.engines = registry
+	return cv
+}
+
 // GetID returns a unique identifier for the component
 func (c *Component) GetID() string {
 	return c.Name + ":" + c.Version
@@ -128,6 +204,105 @@ func (c *Component) IsDeprecated() bool {
 	return c.Metadata.Deprecated || c.Metadata.DeprecatedAt != nil
 }
 
+// Status returns the lifecycle state of the version
+func (c *Component) Status() VersionStatus {
+	if c.Metadata.Status == "" {
+		return VersionStatusActive
+	}
+	return c.Metadata.Status
+}
+
+// IsDraft returns true if the version has not been promoted yet
+func (c *Component) IsDraft() bool {
+	return c.Metadata.Status == VersionStatusDraft
+}
+
+// IsMaintainer returns true if user is listed in Maintainers
+func (c *Component) IsMaintainer(user string) bool {
+	return user != "" && slices.Contains(c.Maintainers, user)
+}
+
+// Approvers returns the users of eligible whose approval is still valid:
+// they approved the current definition and did not publish it
+func (c *Component) Approvers(eligible []string) []string {
+	var approvers []string
+	for _, approval := range c.Metadata.Approvals {
+		if approval.Checksum == c.Metadata.Checksum && approval.By != c.Metadata.PublishedBy &&
+			slices.Contains(eligible, approval.By) && !slices.Contains(approvers, approval.By) {
+			approvers = append(approvers, approval.By)
+		}
+	}
+	return approvers
+}
+
+// HasLabel returns true if the component carries the label with the given value
+func (c *Component) HasLabel(key, value string) bool {
+	v, ok := c.Labels[key]
+	return ok && v == value
+}
+
+// HasDependency returns true if the component declares a dependency on name
+func (c *Component) HasDependency(name string) bool {
+	for _, dep := range c.Dependencies {
+		if dep.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ProvidesDependency returns true if the component provides the named capability
+func (c *Component) ProvidesDependency(name string) bool {
+	return slices.Contains(c.Provides, name)
+}
+
+// DeploymentSpecs returns the deployment specs for every supported engine,
+// default engine first
+func (c *Component) DeploymentSpecs() []DeploymentSpec {
+	specs := make([]DeploymentSpec, 0, 1+len(c.AdditionalDeployments))
+	specs = append(specs, c.Deployment)
+	return append(specs, c.AdditionalDeployments...)
+}
+
+// Engines returns the names of every supported engine, default engine first
+func (c *Component) Engines() []string {
+	engines := make([]string, 0, 1+len(c.AdditionalDeployments))
+	for _, spec := range c.DeploymentSpecs() {
+		engines = append(engines, spec.Engine)
+	}
+	return engines
+}
+
+// SupportsEngine returns true if the component can be deployed with engine
+func (c *Component) SupportsEngine(engine string) bool {
+	_, ok := c.DeploymentFor(engine)
+	return ok
+}
+
+// DeploymentFor returns the deployment spec for engine
+func (c *Component) DeploymentFor(engine string) (*DeploymentSpec, bool) {
+	for _, spec := range c.DeploymentSpecs() {
+		if spec.Engine == engine {
+			return &spec, true
+		}
+	}
+	return nil, false
+}
+
+// ForEngine returns a copy of the component as rendered for a single engine:
+// Deployment holds that engine's spec and no other engine is listed
+func (c *Component) ForEngine(engine string) (*Component, error) {
+	spec, ok := c.DeploymentFor(engine)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not support %s", ErrUnsupportedEngine, c.GetID(), engine)
+	}
+
+	rendered := *c
+	rendered.Deployment = *spec
+	rendered.AdditionalDeployments = nil
+	return &rendered, nil
+}
+
 // MarshalJSON adds the ID field to the JSON output
 func (c *Component) MarshalJSON() ([]byte, error) {
 	type Alias Component
@@ -140,69 +315,375 @@ func (c *Component) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// Validate validates a component according to the business rules
+// Validate validates a component according to the business rules. It returns
+// ValidationErrors listing every issue when at least one is an error.
 func (cv *ComponentValidator) Validate(component *Component) error {
 	if component == nil {
 		return fmt.Errorf("component cannot be nil")
 	}
 
-	// Perform struct validation
-	if err := cv.validator.Struct(component); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+	if issues := cv.Issues(component); issues.HasErrors() {
+		return issues
+	}
+	return nil
+}
+
+// Issues returns every error and warning found in the component, nil if it
+// is valid and clean
+func (cv *ComponentValidator) Issues(component *Component) ValidationErrors {
+	var issues ValidationErrors
+
+	// Business logic validation reports friendlier messages than struct tags
+	cv.validateInputsAndOutputs(component, &issues)
+	cv.validateDeploymentSpec(component, &issues)
+	cv.validateTemplates(component, &issues)
+	cv.validateMetadata(component, &issues)
+	cv.validateRelations(component, &issues)
+
+	// Perform struct validation, skipping what business rules already reported
+	var structIssues ValidationErrors
+	var fieldErrs validator.ValidationErrors
+	if err := cv.validator.Struct(component); errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			path := structPath(fe.Namespace())
+			rule, message := describeTag(fe)
+			if !issues.has(path, rule) {
+				structIssues.errorf(path, rule, "%s %s", path, message)
+			}
+		}
 	}
 
-	// Additional business logic validation
-	if err := cv.validateInputsAndOutputs(component); err != nil {
-		return err
+	return append(structIssues, issues...)
+}
+
+// ValidateStruct checks the validate tags of s, such as storage filters,
+// with the rules registered for components
+func (cv *ComponentValidator) ValidateStruct(s any) ValidationErrors {
+	var issues ValidationErrors
+	var fieldErrs validator.ValidationErrors
+	if err := cv.validator.Struct(s); errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			path := structPath(fe.Namespace())
+			rule, message := describeTag(fe)
+			issues.errorf(path, rule, "%s %s", path, message)
+		}
 	}
+	return issues
+}
 
-	if err := cv.validateDeploymentSpec(component); err != nil {
-		return err
+// structPath converts a validator namespace such as "Component.inputs[0].name"
+// to a path relative to the component
+func structPath(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
 	}
+	return namespace
+}
 
-	return nil
+// describeTag returns the rule and message of a failed struct tag
+func describeTag(fe validator.FieldError) (string, string) {
+	switch fe.Tag() {
+	case "required":
+		return RuleRequired, "is required"
+	case "min":
+		switch fe.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return RuleMinLength, fmt.Sprintf("must have at least %s elements", fe.Param())
+		case reflect.String:
+			return RuleMinLength, fmt.Sprintf("must be at least %s characters", fe.Param())
+		default:
+			return RuleMin, fmt.Sprintf("must be at least %s", fe.Param())
+		}
+	case "semver":
+		return RuleFormat, "must be a semantic version"
+	case "semver_constraint":
+		return RuleFormat, "must be a version constraint"
+	case "dns1123":
+		return RuleFormat, "must be a DNS-1123 name"
+	default:
+		return fe.Tag(), fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
 }
 
 // validateInputsAndOutputs validates input and output specifications
-func (cv *ComponentValidator) validateInputsAndOutputs(component *Component) error {
+func (cv *ComponentValidator) validateInputsAndOutputs(component *Component, issues *ValidationErrors) {
 	// Validate inputs
+	inputs := make(map[string]bool, len(component.Inputs))
 	for i, input := range component.Inputs {
+		path := fmt.Sprintf("inputs[%d]", i)
+		label := fmt.Sprintf("input '%s'", input.Name)
 		if input.Name == "" {
-			return fmt.Errorf("input at index %d is missing name", i)
+			label = fmt.Sprintf("input at index %d", i)
+			issues.errorf(path+".name", RuleRequired, "%s is missing name", label)
+		} else if inputs[input.Name] {
+			issues.errorf(path+".name", RuleUnique, "%s is declared more than once", label)
+		}
+		inputs[input.Name] = true
+		if input.Description == "" {
+			issues.errorf(path+".description", RuleRequired, "%s is missing description", label)
 		}
 		if input.Type == "" {
-			return fmt.Errorf("input '%s' is missing type", input.Name)
+			issues.errorf(path+".type", RuleRequired, "%s is missing type", label)
+			continue
 		}
-		if input.Description == "" {
-			return fmt.Errorf("input '%s' is missing description", input.Name)
+		typ, err := ParseType(input.Type)
+		if err != nil {
+			issues.errorf(path+".type", RuleType, "%s has invalid type: %v", label, err)
+			continue
+		}
+		validateInputRules(input, path, label, typ, issues)
+	}
+
+	// Conditions can only be checked once every input is known
+	for i, input := range component.Inputs {
+		if input.RequiredIf == "" {
+			continue
+		}
+		path := fmt.Sprintf("inputs[%d].required_if", i)
+		if input.Validation.Required {
+			issues.errorf(path, RuleConflict, "input '%s' is always required, required_if has no effect", input.Name)
+		}
+		if err := checkCondition(input.RequiredIf, component.Inputs); err != nil {
+			issues.errorf(path, RuleCondition, "input '%s' has invalid required_if: %v", input.Name, err)
 		}
 	}
 
 	// Validate outputs
+	outputs := make(map[string]bool, len(component.Outputs))
 	for i, output := range component.Outputs {
+		path := fmt.Sprintf("outputs[%d]", i)
+		label := fmt.Sprintf("output '%s'", output.Name)
 		if output.Name == "" {
-			return fmt.Errorf("output at index %d is missing name", i)
+			label = fmt.Sprintf("output at index %d", i)
+			issues.errorf(path+".name", RuleRequired, "%s is missing name", label)
+		} else if outputs[output.Name] {
+			issues.errorf(path+".name", RuleUnique, "%s is declared more than once", label)
+		}
+		outputs[output.Name] = true
+		if output.Description == "" {
+			issues.errorf(path+".description", RuleRequired, "%s is missing description", label)
 		}
 		if output.Type == "" {
-			return fmt.Errorf("output '%s' is missing type", output.Name)
+			issues.errorf(path+".type", RuleRequired, "%s is missing type", label)
+		} else if _, err := ParseType(output.Type); err != nil {
+			issues.errorf(path+".type", RuleType, "%s has invalid type: %v", label, err)
 		}
-		if output.Description == "" {
-			return fmt.Errorf("output '%s' is missing description", output.Name)
+	}
+}
+
+// validateInputRules rejects validation rules that contradict each other or
+// the default of the input
+func validateInputRules(input InputSpec, path, label string, typ *Type, issues *ValidationErrors) {
+	rules := input.Validation
+	rulesPath := path + ".validation"
+
+	if rules.MinLength != nil && *rules.MinLength < 0 {
+		issues.errorf(rulesPath+".min_length", RuleMinLength, "%s has negative min_length %d", label, *rules.MinLength)
+	}
+	if rules.MaxLength != nil && *rules.MaxLength < 0 {
+		issues.errorf(rulesPath+".max_length", RuleMaxLength, "%s has negative max_length %d", label, *rules.MaxLength)
+	}
+	if rules.MinLength != nil && rules.MaxLength != nil && *rules.MinLength > *rules.MaxLength {
+		issues.errorf(rulesPath+".min_length", RuleConflict, "%s has min_length %d greater than max_length %d", label, *rules.MinLength, *rules.MaxLength)
+	}
+	if rules.Min != nil && rules.Max != nil && *rules.Min > *rules.Max {
+		issues.errorf(rulesPath+".min", RuleConflict, "%s has min %v greater than max %v", label, *rules.Min, *rules.Max)
+	}
+
+	if rules.Pattern != "" {
+		pattern, err := regexp.Compile(rules.Pattern)
+		if err != nil {
+			issues.errorf(rulesPath+".pattern", RulePattern, "%s has invalid pattern: %v", label, err)
+		} else {
+			for j, value := range rules.Enum {
+				if !pattern.MatchString(value) {
+					issues.errorf(fmt.Sprintf("%s.enum[%d]", rulesPath, j), RulePattern, "%s enum value '%s' does not match pattern '%s'", label, value, rules.Pattern)
+				}
+			}
 		}
 	}
 
-	return nil
+	if input.Default == nil {
+		return
+	}
+	if rules.Required {
+		issues.errorf(path+".default", RuleConflict, "%s is required but has a default", label)
+	}
+	if input.Sensitive {
+		issues.warnf(path+".default", RuleConflict, "%s is sensitive but has a default, which is stored in plain text", label)
+	}
+
+	if errs := typ.Check("default", input.Default); len(errs) > 0 {
+		for _, fieldErr := range errs {
+			issues.errorf(path+"."+fieldErr.Field, fieldErr.Rule, "%s default does not match type %s: %s", label, typ, fieldErr)
+		}
+		return
+	}
+	for _, fieldErr := range validateInput(input, input.Default) {
+		issues.errorf(path+".default", fieldErr.Rule, "%s default is invalid: %s", label, fieldErr.Message)
+	}
 }
 
 // validateDeploymentSpec validates deployment engine specifications
-func (cv *ComponentValidator) validateDeploymentSpec(component *Component) error {
+func (cv *ComponentValidator) validateDeploymentSpec(component *Component, issues *ValidationErrors) {
 	if component.Deployment.Engine == "" {
-		return fmt.Errorf("deployment engine is required")
+		issues.errorf("deployment.engine", RuleRequired, "deployment engine is required")
 	}
 	if component.Deployment.Version == "" {
-		return fmt.Errorf("deployment engine version is required")
+		issues.errorf("deployment.version", RuleRequired, "deployment engine version is required")
+	}
+	cv.validateEngineConfig(component.Deployment, "deployment", issues)
+
+	engines := map[string]bool{component.Deployment.Engine: true}
+	for i, spec := range component.AdditionalDeployments {
+		path := fmt.Sprintf("additional_deployments[%d]", i)
+		if spec.Engine == "" {
+			issues.errorf(path+".engine", RuleRequired, "additional deployment at index %d is missing engine", i)
+			continue
+		}
+		if spec.Version == "" {
+			issues.errorf(path+".version", RuleRequired, "deployment for engine '%s' is missing version", spec.Engine)
+		}
+		if engines[spec.Engine] {
+			issues.errorf(path+".engine", RuleUnique, "deployment engine '%s' is declared more than once", spec.Engine)
+		}
+		engines[spec.Engine] = true
+		cv.validateEngineConfig(spec, path, issues)
+	}
+}
+
+// validateEngineConfig checks the version and config of a deployment against
+// the schema registered for its engine
+func (cv *ComponentValidator) validateEngineConfig(spec DeploymentSpec, path string, issues *ValidationErrors) {
+	schema, ok := cv.engines.Lookup(spec.Engine)
+	if !ok {
+		return
+	}
+
+	if spec.Version != "" && schema.ValidateVersion != nil {
+		if err := schema.ValidateVersion(spec.Version); err != nil {
+			issues.errorf(path+".version", RuleFormat, "deployment for engine '%s' has invalid version '%s': %v", spec.Engine, spec.Version, err)
+		}
+	}
+
+	for _, fieldErr := range schema.Check(path+".config", spec.Config) {
+		issues.errorf(fieldErr.Field, fieldErr.Rule, "deployment for engine '%s' has invalid config: %s", spec.Engine, fieldErr)
+	}
+}
+
+// validateMetadata validates maintainers, documentation links and labels
+func (cv *ComponentValidator) validateMetadata(component *Component, issues *ValidationErrors) {
+	seen := make(map[string]bool, len(component.Maintainers))
+	for i, maintainer := range component.Maintainers {
+		if seen[maintainer] {
+			issues.errorf(fmt.Sprintf("maintainers[%d]", i), RuleUnique, "maintainer '%s' is listed more than once", maintainer)
+		}
+		seen[maintainer] = true
+	}
+
+	for i, doc := range component.Documentation {
+		u, err := url.Parse(doc.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			issues.errorf(fmt.Sprintf("documentation[%d].url", i), RuleFormat, "documentation link at index %d has invalid URL '%s'", i, doc.URL)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(component.Labels)) {
+		path := fmt.Sprintf("labels[%q]", key)
+		if !isQualifiedKey(key) {
+			issues.errorf(path, RuleFormat, "label key '%s' is invalid", key)
+		}
+		if len(component.Labels[key]) > maxLabelValueLength {
+			issues.errorf(path, RuleMaxLength, "label '%s' value exceeds %d characters", key, maxLabelValueLength)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(component.Annotations)) {
+		if !isQualifiedKey(key) {
+			issues.errorf(fmt.Sprintf("annotations[%q]", key), RuleFormat, "annotation key '%s' is invalid", key)
+		}
 	}
-	return nil
+}
+
+// validateRelations validates dependencies, provided capabilities and conflicts
+func (cv *ComponentValidator) validateRelations(component *Component, issues *ValidationErrors) {
+	parser := NewConstraintParser()
+	dependencies := make(map[string]bool, len(component.Dependencies))
+	for i, dep := range component.Dependencies {
+		path := fmt.Sprintf("dependencies[%d]", i)
+		if dep.Name == component.Name {
+			issues.errorf(path+".name", RuleConflict, "component cannot depend on itself")
+		} else if dependencies[dep.Name] {
+			issues.errorf(path+".name", RuleUnique, "dependency '%s' is declared more than once", dep.Name)
+		}
+		dependencies[dep.Name] = true
+
+		if _, err := parser.Parse(dep.Version); err != nil {
+			issues.errorf(path+".version", RuleFormat, "dependency '%s' has invalid version constraint: %v", dep.Name, err)
+		}
+		if dep.Condition != "" {
+			if err := checkCondition(dep.Condition, component.Inputs); err != nil {
+				issues.errorf(path+".condition", RuleCondition, "dependency '%s' has invalid condition: %v", dep.Name, err)
+			}
+		}
+	}
+
+	provides := make(map[string]bool, len(component.Provides))
+	for i, capability := range component.Provides {
+		if provides[capability] {
+			issues.errorf(fmt.Sprintf("provides[%d]", i), RuleUnique, "capability '%s' is provided more than once", capability)
+		}
+		provides[capability] = true
+	}
+
+	conflicts := make(map[string]bool, len(component.ConflictsWith))
+	for i, conflict := range component.ConflictsWith {
+		path := fmt.Sprintf("conflicts_with[%d]", i)
+		switch {
+		case conflict == component.Name:
+			issues.errorf(path, RuleConflict, "component cannot conflict with itself")
+		case conflicts[conflict]:
+			issues.errorf(path, RuleUnique, "conflict with '%s' is declared more than once", conflict)
+		case dependencies[conflict]:
+			issues.errorf(path, RuleConflict, "component both depends on and conflicts with '%s'", conflict)
+		}
+		conflicts[conflict] = true
+	}
+}
+
+// isQualifiedKey validates label and annotation keys: an optional DNS-style
+// prefix followed by a slash, then a name of at most 63 characters made of
+// alphanumerics, '-', '_' and '.', starting and ending with an alphanumeric.
+func isQualifiedKey(key string) bool {
+	name := key
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if prefix == "" || len(prefix) > 253 {
+			return false
+		}
+		for _, r := range prefix {
+			if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.') {
+				return false
+			}
+		}
+	}
+
+	if len(name) == 0 || len(name) > 63 {
+		return false
+	}
+	for i, r := range name {
+		alnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if i == 0 || i == len(name)-1 {
+			if !alnum {
+				return false
+			}
+		} else if !alnum && r != '-' && r != '_' && r != '.' {
+			return false
+		}
+	}
+
+	return true
 }
 
 // validateSemanticVersion validates semantic version format
@@ -212,9 +693,19 @@ func validateSemanticVersion(fl validator.FieldLevel) bool {
 	return err == nil
 }
 
+// validateVersionConstraint validates version constraints such as ^1.2.0
+func validateVersionConstraint(fl validator.FieldLevel) bool {
+	_, err := NewConstraintParser().Parse(fl.Field().String())
+	return err == nil
+}
+
 // validateDNS1123 validates DNS-1123 compliant names
 func validateDNS1123(fl validator.FieldLevel) bool {
-	name := fl.Field().String()
+	return isDNS1123(fl.Field().String())
+}
+
+// isDNS1123 reports whether name is a DNS-1123 label
+func isDNS1123(name string) bool {
 	if len(name) == 0 || len(name) > 63 {
 		return false
 	}
@@ -240,4 +731,8 @@ var (
 	ErrInvalidComponentName    = fmt.Errorf("component name is required")
 	ErrInvalidComponentVersion = fmt.Errorf("component version is required")
 	ErrNoDeploymentEngines     = fmt.Errorf("at least one deployment engine is required")
+	ErrUnsupportedEngine       = fmt.Errorf("deployment engine not supported")
+	// ErrNotFound is matched by the errors catalog readers return for a
+	// component or version that does not exist
+	ErrNotFound = fmt.Errorf("component not found")
 )
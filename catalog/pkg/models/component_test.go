@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -101,6 +102,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: false,
@@ -128,6 +130,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -157,6 +160,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -186,6 +190,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -209,6 +214,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -232,6 +238,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -260,6 +267,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -288,6 +296,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -316,6 +325,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -344,6 +354,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -372,6 +383,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -400,6 +412,7 @@ func TestComponentValidator_Validate(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			},
 			wantErr: true,
@@ -520,6 +533,7 @@ func TestValidateSemanticVersion(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			}
 
@@ -579,6 +593,7 @@ func TestValidateDNS1123(t *testing.T) {
 				Deployment: DeploymentSpec{
 					Engine:  "terraform",
 					Version: "1.0.0",
+					Config:  map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"},
 				},
 			}
 
@@ -592,3 +607,396 @@ func TestValidateDNS1123(t *testing.T) {
 		})
 	}
 }
+
+func validRelationsComponent() *Component {
+	return &Component{
+		Name:        "postgres",
+		Version:     "1.0.0",
+		Provider:    "aws",
+		Category:    "database",
+		SubCategory: "relational",
+		Maintainers: []string{"platform@example.com", "dba@example.com"},
+		Documentation: []DocLink{
+			{Title: "Runbook", URL: "https://docs.example.com/postgres", Type: "runbook"},
+		},
+		Labels:      map[string]string{"team": "platform", "nestor.io/tier": "gold"},
+		Annotations: map[string]string{"nestor.io/owner-slack": "#platform"},
+		Inputs: []InputSpec{
+			{Name: "db_name", Type: "string", Description: "Database name"},
+		},
+		Outputs: []OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Database endpoint"},
+		},
+		Deployment: DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+		Dependencies: []Dependency{
+			{Name: "vpc", Type: "component", Version: "^1.2.0"},
+			{Name: "kms-key", Type: "component", Version: ">=2.0.0", Optional: true},
+		},
+		Provides:      []string{"postgres-database"},
+		ConflictsWith: []string{"legacy-postgres"},
+	}
+}
+
+func TestComponentValidator_ValidateMetadataAndRelations(t *testing.T) {
+	validator := NewComponentValidator()
+
+	tests := []struct {
+		name   string
+		mutate func(c *Component)
+		errMsg string
+	}{
+		{
+			name:   "valid component",
+			mutate: func(c *Component) {},
+		},
+		{
+			name:   "empty maintainer",
+			mutate: func(c *Component) { c.Maintainers = []string{""} },
+			errMsg: "validation failed",
+		},
+		{
+			name:   "duplicate maintainer",
+			mutate: func(c *Component) { c.Maintainers = []string{"a@example.com", "a@example.com"} },
+			errMsg: "maintainer 'a@example.com' is listed more than once",
+		},
+		{
+			name:   "documentation missing title",
+			mutate: func(c *Component) { c.Documentation = []DocLink{{URL: "https://example.com"}} },
+			errMsg: "validation failed",
+		},
+		{
+			name:   "documentation with relative URL",
+			mutate: func(c *Component) { c.Documentation = []DocLink{{Title: "Docs", URL: "/docs"}} },
+			errMsg: "documentation link at index 0 has invalid URL '/docs'",
+		},
+		{
+			name:   "invalid label key",
+			mutate: func(c *Component) { c.Labels = map[string]string{"-team": "platform"} },
+			errMsg: "label key '-team' is invalid",
+		},
+		{
+			name:   "label value too long",
+			mutate: func(c *Component) { c.Labels = map[string]string{"team": strings.Repeat("x", 64)} },
+			errMsg: "label 'team' value exceeds 63 characters",
+		},
+		{
+			name:   "invalid annotation key",
+			mutate: func(c *Component) { c.Annotations = map[string]string{"Nestor.io/owner": "x"} },
+			errMsg: "annotation key 'Nestor.io/owner' is invalid",
+		},
+		{
+			name:   "dependency name not dns1123",
+			mutate: func(c *Component) { c.Dependencies[0].Name = "VPC" },
+			errMsg: "validation failed",
+		},
+		{
+			name:   "dependency with invalid constraint",
+			mutate: func(c *Component) { c.Dependencies[0].Version = "^one" },
+			errMsg: "dependency 'vpc' has invalid version constraint",
+		},
+		{
+			name:   "self dependency",
+			mutate: func(c *Component) { c.Dependencies[0].Name = "postgres" },
+			errMsg: "component cannot depend on itself",
+		},
+		{
+			name: "duplicate dependency",
+			mutate: func(c *Component) {
+				c.Dependencies = append(c.Dependencies, Dependency{Name: "vpc", Type: "component", Version: "*"})
+			},
+			errMsg: "dependency 'vpc' is declared more than once",
+		},
+		{
+			name:   "invalid capability",
+			mutate: func(c *Component) { c.Provides = []string{"Postgres"} },
+			errMsg: "validation failed",
+		},
+		{
+			name:   "duplicate capability",
+			mutate: func(c *Component) { c.Provides = []string{"sql", "sql"} },
+			errMsg: "capability 'sql' is provided more than once",
+		},
+		{
+			name:   "conflict with self",
+			mutate: func(c *Component) { c.ConflictsWith = []string{"postgres"} },
+			errMsg: "component cannot conflict with itself",
+		},
+		{
+			name:   "conflict with dependency",
+			mutate: func(c *Component) { c.ConflictsWith = []string{"vpc"} },
+			errMsg: "component both depends on and conflicts with 'vpc'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := validRelationsComponent()
+			tt.mutate(component)
+
+			err := validator.Validate(component)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestComponent_RelationHelpers(t *testing.T) {
+	component := validRelationsComponent()
+
+	assert.True(t, component.HasLabel("team", "platform"))
+	assert.False(t, component.HasLabel("team", "data"))
+	assert.False(t, component.HasLabel("missing", ""))
+
+	assert.True(t, component.HasDependency("vpc"))
+	assert.False(t, component.HasDependency("subnet"))
+
+	assert.True(t, component.ProvidesDependency("postgres-database"))
+	assert.False(t, component.ProvidesDependency("mysql-database"))
+}
+
+func multiEngineComponent() *Component {
+	component := validRelationsComponent()
+	component.Deployment.Config = map[string]any{"source": "git::https://example.com/postgres", "module_version": "1.0.0"}
+	component.AdditionalDeployments = []DeploymentSpec{
+		{Engine: "crossplane", Version: "1.14.0", Config: map[string]any{"apiVersion": "database.example.org/v1alpha1", "kind": "XPostgres", "compositionRef": map[string]any{"name": "xpostgres"}}},
+		{Engine: "pulumi", Version: "3.0.0"},
+	}
+	return component
+}
+
+func TestComponent_Approvers(t *testing.T) {
+	component := &Component{
+		Name:        "vpc",
+		Version:     "1.0.0",
+		Maintainers: []string{"alice@example.com", "bob@example.com", "carol@example.com"},
+		Metadata: ComponentMetadata{
+			Checksum:    "sha256:1111",
+			PublishedBy: "alice@example.com",
+			Approvals: []Approval{
+				{By: "alice@example.com", Checksum: "sha256:1111"},
+				{By: "bob@example.com", Checksum: "sha256:1111"},
+				{By: "bob@example.com", Checksum: "sha256:1111"},
+				{By: "carol@example.com", Checksum: "sha256:0000"},
+				{By: "mallory@example.com", Checksum: "sha256:1111"},
+			},
+		},
+	}
+	assert.Equal(t, []string{"bob@example.com"}, component.Approvers(component.Maintainers),
+		"self-approvals, stale approvals and approvals of ineligible users do not count")
+
+	assert.Empty(t, component.Approvers(component.Maintainers[:1]))
+}
+
+func TestComponentValidator_ValidateAdditionalDeployments(t *testing.T) {
+	validator := NewComponentValidator()
+
+	tests := []struct {
+		name   string
+		mutate func(c *Component)
+		errMsg string
+	}{
+		{
+			name:   "valid component",
+			mutate: func(c *Component) {},
+		},
+		{
+			name:   "missing engine",
+			mutate: func(c *Component) { c.AdditionalDeployments[0].Engine = "" },
+			errMsg: "validation failed",
+		},
+		{
+			name:   "missing version",
+			mutate: func(c *Component) { c.AdditionalDeployments[1].Version = "" },
+			errMsg: "validation failed",
+		},
+		{
+			name:   "duplicates default engine",
+			mutate: func(c *Component) { c.AdditionalDeployments[0].Engine = "terraform" },
+			errMsg: "deployment engine 'terraform' is declared more than once",
+		},
+		{
+			name:   "duplicates additional engine",
+			mutate: func(c *Component) { c.AdditionalDeployments[1].Engine = "crossplane" },
+			errMsg: "deployment engine 'crossplane' is declared more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := multiEngineComponent()
+			tt.mutate(component)
+
+			err := validator.Validate(component)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestComponent_ForEngine(t *testing.T) {
+	component := multiEngineComponent()
+
+	assert.Equal(t, []string{"terraform", "crossplane", "pulumi"}, component.Engines())
+	assert.True(t, component.SupportsEngine("pulumi"))
+	assert.False(t, component.SupportsEngine("helm"))
+
+	spec, ok := component.DeploymentFor("crossplane")
+	require.True(t, ok)
+	assert.Equal(t, "1.14.0", spec.Version)
+
+	rendered, err := component.ForEngine("crossplane")
+	require.NoError(t, err)
+	assert.Equal(t, "crossplane", rendered.Deployment.Engine)
+	assert.Equal(t, map[string]any{"apiVersion": "database.example.org/v1alpha1", "kind": "XPostgres", "compositionRef": map[string]any{"name": "xpostgres"}}, rendered.Deployment.Config)
+	assert.Nil(t, rendered.AdditionalDeployments)
+	assert.Equal(t, component.Inputs, rendered.Inputs)
+	assert.Equal(t, "terraform", component.Deployment.Engine, "rendering must not modify the component")
+
+	_, err = component.ForEngine("helm")
+	require.ErrorIs(t, err, ErrUnsupportedEngine)
+	assert.Contains(t, err.Error(), "postgres:1.0.0 does not support helm")
+}
+
+func TestComponentValidator_ValidateInputRules(t *testing.T) {
+	validator := NewComponentValidator()
+	intPtr := func(n int) *int { return &n }
+	floatPtr := func(n float64) *float64 { return &n }
+
+	tests := []struct {
+		name   string
+		input  InputSpec
+		errMsg string
+	}{
+		{
+			name: "consistent rules",
+			input: InputSpec{Name: "size", Type: "string", Description: "Size", Default: "small",
+				Validation: Validation{MinLength: intPtr(1), MaxLength: intPtr(10), Pattern: "^[a-z]+$", Enum: []string{"small", "large"}}},
+		},
+		{
+			name:   "invalid pattern",
+			input:  InputSpec{Name: "size", Type: "string", Description: "Size", Validation: Validation{Pattern: "[a-z"}},
+			errMsg: "input 'size' has invalid pattern",
+		},
+		{
+			name:   "min length above max length",
+			input:  InputSpec{Name: "size", Type: "string", Description: "Size", Validation: Validation{MinLength: intPtr(5), MaxLength: intPtr(2)}},
+			errMsg: "input 'size' has min_length 5 greater than max_length 2",
+		},
+		{
+			name:   "negative length",
+			input:  InputSpec{Name: "size", Type: "string", Description: "Size", Validation: Validation{MinLength: intPtr(-1)}},
+			errMsg: "input 'size' has negative min_length -1",
+		},
+		{
+			name:   "min above max",
+			input:  InputSpec{Name: "count", Type: "number", Description: "Count", Validation: Validation{Min: floatPtr(10), Max: floatPtr(1)}},
+			errMsg: "input 'count' has min 10 greater than max 1",
+		},
+		{
+			name:   "enum value violates pattern",
+			input:  InputSpec{Name: "size", Type: "string", Description: "Size", Validation: Validation{Pattern: "^[a-z]+$", Enum: []string{"small", "XL"}}},
+			errMsg: "input 'size' enum value 'XL' does not match pattern '^[a-z]+$'",
+		},
+		{
+			name:   "default outside enum",
+			input:  InputSpec{Name: "size", Type: "string", Description: "Size", Default: "medium", Validation: Validation{Enum: []string{"small", "large"}}},
+			errMsg: "input 'size' default is invalid: must be one of: small, large",
+		},
+		{
+			name:   "default outside range",
+			input:  InputSpec{Name: "count", Type: "number", Description: "Count", Default: 0.0, Validation: Validation{Min: floatPtr(1)}},
+			errMsg: "input 'count' default is invalid: must be at least 1",
+		},
+		{
+			name:   "required with default",
+			input:  InputSpec{Name: "count", Type: "number", Description: "Count", Default: 3.0, Validation: Validation{Required: true}},
+			errMsg: "input 'count' is required but has a default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := validRelationsComponent()
+			component.Inputs = append(component.Inputs, tt.input)
+
+			err := validator.Validate(component)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestComponentValidator_DuplicateInputsAndOutputs(t *testing.T) {
+	validator := NewComponentValidator()
+
+	component := validRelationsComponent()
+	component.Inputs = append(component.Inputs, component.Inputs[0])
+	assert.ErrorContains(t, validator.Validate(component), "input 'db_name' is declared more than once")
+
+	component = validRelationsComponent()
+	component.Outputs = append(component.Outputs, component.Outputs[0])
+	assert.ErrorContains(t, validator.Validate(component), "output 'endpoint' is declared more than once")
+}
+
+func TestComponentValidator_ReportsEveryIssue(t *testing.T) {
+	validator := NewComponentValidator()
+	minLength, maxLength := 5, 2
+
+	component := validRelationsComponent()
+	component.Version = "latest"
+	component.Inputs = append(component.Inputs,
+		InputSpec{Name: "size", Type: "string", Description: "Size", Validation: Validation{Pattern: "("}},
+		InputSpec{Name: "tier", Type: "string", Description: "Tier", Validation: Validation{MinLength: &minLength, MaxLength: &maxLength}},
+	)
+	component.Deployment.Version = ""
+	component.Labels["bad key"] = "x"
+
+	err := validator.Validate(component)
+	require.Error(t, err)
+
+	var issues ValidationErrors
+	require.ErrorAs(t, err, &issues)
+	assert.Equal(t, []ValidationIssue{
+		{Path: "version", Rule: RuleFormat, Message: "version must be a semantic version", Severity: SeverityError},
+		{Path: "inputs[1].validation.pattern", Rule: RulePattern, Message: "input 'size' has invalid pattern: error parsing regexp: missing closing ): `(`", Severity: SeverityError},
+		{Path: "inputs[2].validation.min_length", Rule: RuleConflict, Message: "input 'tier' has min_length 5 greater than max_length 2", Severity: SeverityError},
+		{Path: "deployment.version", Rule: RuleRequired, Message: "deployment engine version is required", Severity: SeverityError},
+		{Path: `labels["bad key"]`, Rule: RuleFormat, Message: "label key 'bad key' is invalid", Severity: SeverityError},
+	}, []ValidationIssue(issues))
+	assert.Contains(t, err.Error(), "validation failed: version must be a semantic version; input 'size' has invalid pattern")
+}
+
+func TestComponentValidator_WarningsDoNotFail(t *testing.T) {
+	validator := NewComponentValidator()
+
+	component := validRelationsComponent()
+	component.Inputs = append(component.Inputs, InputSpec{
+		Name: "password", Type: "string", Description: "Admin password", Default: "changeme", Sensitive: true,
+	})
+
+	require.NoError(t, validator.Validate(component))
+	issues := validator.Issues(component)
+	require.Len(t, issues, 1)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+	assert.Equal(t, "inputs[1].default", issues[0].Path)
+	assert.Empty(t, issues.Errors())
+
+	component.Deployment.Engine = ""
+	err := validator.Validate(component)
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs.Warnings(), 1)
+	assert.NotContains(t, err.Error(), "plain text")
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Warning codes reported in Warning.Code
+const (
+	WarningDeprecated = "deprecated"
+	WarningSunset     = "sunset"
+)
+
+// Deprecation explains why a version is deprecated, what replaces it and
+// when it stops being resolvable
+type Deprecation struct {
+	Reason string `json:"reason,omitempty"`
+	// Replacement is the component consumers should move to
+	Replacement *Replacement `json:"replacement,omitempty"`
+	// SunsetAt is when the version stops being resolved unless callers opt
+	// in. Nil means never
+	SunsetAt *time.Time `json:"sunset_at,omitempty"`
+}
+
+// Replacement names the component replacing a deprecated version, and
+// optionally the version constraint to move to
+type Replacement struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// String formats the replacement as name@version, or name alone
+func (r *Replacement) String() string {
+	if r.Version == "" {
+		return r.Name
+	}
+	return r.Name + "@" + r.Version
+}
+
+// Clone returns a deep copy of the deprecation
+func (d *Deprecation) Clone() *Deprecation {
+	clone := *d
+	if d.Replacement != nil {
+		replacement := *d.Replacement
+		clone.Replacement = &replacement
+	}
+	if d.SunsetAt != nil {
+		sunset := *d.SunsetAt
+		clone.SunsetAt = &sunset
+	}
+	return &clone
+}
+
+// Validate checks that the replacement names a valid component and version
+// constraint
+func (d *Deprecation) Validate() ValidationErrors {
+	var issues ValidationErrors
+	if d.Replacement == nil {
+		return issues
+	}
+	if !isDNS1123(d.Replacement.Name) {
+		issues.errorf("replacement.name", RuleFormat, "replacement '%s' must be a DNS-1123 name", d.Replacement.Name)
+	}
+	if d.Replacement.Version != "" {
+		if _, err := NewConstraintParser().Parse(d.Replacement.Version); err != nil {
+			issues.errorf("replacement.version", RuleFormat, "replacement version '%s' is not a valid constraint: %v", d.Replacement.Version, err)
+		}
+	}
+	return issues
+}
+
+// Warning is a machine-readable notice about a component version returned
+// along with it, such as its deprecation
+type Warning struct {
+	Code         string       `json:"code"`
+	Message      string       `json:"message"`
+	Component    string       `json:"component"`
+	Version      string       `json:"version,omitempty"`
+	DeprecatedAt *time.Time   `json:"deprecated_at,omitempty"`
+	SunsetAt     *time.Time   `json:"sunset_at,omitempty"`
+	Replacement  *Replacement `json:"replacement,omitempty"`
+}
+
+// SunsetAt returns the sunset date of the version, or nil when it has none
+func (c *Component) SunsetAt() *time.Time {
+	if !c.IsDeprecated() || c.Metadata.Deprecation == nil {
+		return nil
+	}
+	return c.Metadata.Deprecation.SunsetAt
+}
+
+// IsSunset returns true if the version is past its sunset date at now
+func (c *Component) IsSunset(now time.Time) bool {
+	sunset := c.SunsetAt()
+	return sunset != nil && !now.Before(*sunset)
+}
+
+// Warnings returns the warnings to report when the version is read at now
+func (c *Component) Warnings(now time.Time) []Warning {
+	if !c.IsDeprecated() {
+		return nil
+	}
+	return []Warning{deprecationWarning(c.Name, c.Version, c.Metadata.DeprecatedAt, c.Metadata.Deprecation, c.SunsetAt(), now)}
+}
+
+// deprecationWarning returns the warning reporting the deprecation of
+// name:version at now
+func deprecationWarning(name, version string, deprecatedAt *time.Time, deprecation *Deprecation, sunset *time.Time, now time.Time) Warning {
+	warning := Warning{
+		Code:         WarningDeprecated,
+		Component:    name,
+		Version:      version,
+		DeprecatedAt: deprecatedAt,
+	}
+	message := []string{fmt.Sprintf("%s:%s is deprecated", name, version)}
+	if deprecation != nil {
+		warning.SunsetAt = deprecation.SunsetAt
+		warning.Replacement = deprecation.Replacement
+		if deprecation.Reason != "" {
+			message[0] += ": " + deprecation.Reason
+		}
+		if deprecation.Replacement != nil {
+			message = append(message, fmt.Sprintf("use %s instead", deprecation.Replacement))
+		}
+	}
+	if sunset != nil {
+		if !now.Before(*sunset) {
+			warning.Code = WarningSunset
+			message = append(message, fmt.Sprintf("it was sunset on %s and is no longer resolved", sunset.UTC().Format(time.DateOnly)))
+		} else {
+			message = append(message, fmt.Sprintf("it will be sunset on %s", sunset.UTC().Format(time.DateOnly)))
+		}
+	}
+	warning.Message = strings.Join(message, "; ")
+	return warning
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent_Warnings(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	deprecatedAt := now.Add(-24 * time.Hour)
+	sunset := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	component := &Component{Name: "postgres", Version: "1.0.0"}
+	assert.Empty(t, component.Warnings(now))
+	assert.False(t, component.IsSunset(now))
+
+	component.Metadata.Deprecated = true
+	assert.Equal(t, []Warning{{
+		Code:      WarningDeprecated,
+		Message:   "postgres:1.0.0 is deprecated",
+		Component: "postgres",
+		Version:   "1.0.0",
+	}}, component.Warnings(now))
+
+	component.Metadata.DeprecatedAt = &deprecatedAt
+	component.Metadata.Deprecation = &Deprecation{
+		Reason:      "Replaced by Aurora",
+		Replacement: &Replacement{Name: "aurora", Version: "^1.0.0"},
+		SunsetAt:    &sunset,
+	}
+	warnings := component.Warnings(now)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, WarningDeprecated, warnings[0].Code)
+	assert.Equal(t, "postgres:1.0.0 is deprecated: Replaced by Aurora; use aurora@^1.0.0 instead; it will be sunset on 2025-06-30", warnings[0].Message)
+	assert.Equal(t, &deprecatedAt, warnings[0].DeprecatedAt)
+	assert.Equal(t, &sunset, warnings[0].SunsetAt)
+	assert.Equal(t, "aurora", warnings[0].Replacement.Name)
+
+	assert.False(t, component.IsSunset(now))
+	assert.True(t, component.IsSunset(sunset))
+	warnings = component.Warnings(sunset.Add(time.Hour))
+	assert.Equal(t, WarningSunset, warnings[0].Code)
+	assert.Contains(t, warnings[0].Message, "it was sunset on 2025-06-30 and is no longer resolved")
+
+	component.Metadata.Deprecated = false
+	component.Metadata.DeprecatedAt = nil
+	assert.Nil(t, component.SunsetAt(), "only deprecated versions are sunset")
+}
+
+func TestComponentVersion_Warnings(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	deprecatedAt := now.Add(-24 * time.Hour)
+	sunset := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	component := &Component{Name: "postgres", Version: "1.0.0", Maintainers: []string{"platform@example.com"}}
+	entry := NewComponentVersion(component)
+	assert.Empty(t, entry.Warnings(now))
+	assert.True(t, entry.IsMaintainer("platform@example.com"))
+	assert.False(t, entry.IsMaintainer(""))
+
+	component.Metadata.Status = VersionStatusDeprecated
+	component.Metadata.Deprecated = true
+	component.Metadata.DeprecatedAt = &deprecatedAt
+	component.Metadata.Deprecation = &Deprecation{
+		Reason:      "Replaced by Aurora",
+		Replacement: &Replacement{Name: "aurora", Version: "^1.0.0"},
+		SunsetAt:    &sunset,
+	}
+	entry = NewComponentVersion(component)
+	assert.Equal(t, &sunset, entry.SunsetAt)
+	assert.Equal(t, component.Warnings(now), entry.Warnings(now), "history entries report what their versions report")
+	assert.Equal(t, component.Warnings(sunset), entry.Warnings(sunset))
+
+	component.Metadata.Deprecation.Reason = "edited"
+	assert.Equal(t, "Replaced by Aurora", entry.Deprecation.Reason, "the entry holds a copy")
+}
+
+func TestDeprecation_Validate(t *testing.T) {
+	assert.Empty(t, (&Deprecation{Reason: "Unmaintained"}).Validate())
+	assert.Empty(t, (&Deprecation{Replacement: &Replacement{Name: "aurora"}}).Validate())
+
+	issues := (&Deprecation{Replacement: &Replacement{Name: "Aurora DB", Version: "not a version"}}).Validate()
+	require.Len(t, issues, 2)
+	assert.Equal(t, "replacement.name", issues[0].Path)
+	assert.Equal(t, "replacement.version", issues[1].Path)
+}
+
+func TestDeprecation_Clone(t *testing.T) {
+	sunset := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	deprecation := &Deprecation{Replacement: &Replacement{Name: "aurora"}, SunsetAt: &sunset}
+
+	clone := deprecation.Clone()
+	clone.Replacement.Name = "cockroach"
+	*clone.SunsetAt = sunset.Add(time.Hour)
+	assert.Equal(t, "aurora", deprecation.Replacement.Name)
+	assert.Equal(t, sunset, *deprecation.SunsetAt)
+}
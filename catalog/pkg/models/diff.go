@@ -0,0 +1,241 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Operations reported in FieldChange.Operation
+const (
+	OperationAdd    = "add"
+	OperationRemove = "remove"
+	OperationModify = "modify"
+)
+
+// Version bumps reported in DiffSummary.RecommendedVersionBump
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+	BumpNone  = "none"
+)
+
+// DiffComponents compares two versions of a component and classifies the
+// changes made to its interface: inputs, outputs and deployment engines.
+// Changes that break consumers of from, such as a removed input or a new
+// required one, are breaking; additions are backward compatible. Any other
+// change to the definition only calls for a patch
+func DiffComponents(from, to *Component) *VersionDiff {
+	d := &VersionDiff{
+		ComponentName: to.Name,
+		FromVersion:   from.Version,
+		ToVersion:     to.Version,
+		GeneratedAt:   time.Now().UTC(),
+	}
+	d.diffInputs(from.Inputs, to.Inputs)
+	d.diffOutputs(from.Outputs, to.Outputs)
+	d.diffEngines(from.Engines(), to.Engines())
+	d.summarize(definitionChanged(from, to))
+	return d
+}
+
+func (d *VersionDiff) diffInputs(from, to []InputSpec) {
+	previous := make(map[string]InputSpec, len(from))
+	for _, input := range from {
+		previous[input.Name] = input
+	}
+
+	for _, input := range to {
+		path := inputPath(input.Name)
+		old, ok := previous[input.Name]
+		if !ok {
+			d.record(path, nil, input.Type, OperationAdd, mandatory(input))
+			continue
+		}
+		delete(previous, input.Name)
+
+		if old.Type != input.Type {
+			// Values accepted so far must remain valid
+			d.record(path+".type", old.Type, input.Type, OperationModify, !assignable(old.Type, input.Type))
+		}
+		if !mandatory(old) && mandatory(input) {
+			d.record(path+".validation.required", false, true, OperationModify, true)
+		}
+	}
+
+	for _, input := range from {
+		if _, ok := previous[input.Name]; ok {
+			d.record(inputPath(input.Name), input.Type, nil, OperationRemove, true)
+		}
+	}
+}
+
+func (d *VersionDiff) diffOutputs(from, to []OutputSpec) {
+	previous := make(map[string]OutputSpec, len(from))
+	for _, output := range from {
+		previous[output.Name] = output
+	}
+
+	for _, output := range to {
+		path := "outputs." + output.Name
+		old, ok := previous[output.Name]
+		if !ok {
+			d.record(path, nil, output.Type, OperationAdd, false)
+			continue
+		}
+		delete(previous, output.Name)
+
+		if old.Type != output.Type {
+			// Consumers wired to the output must still accept its values
+			d.record(path+".type", old.Type, output.Type, OperationModify, !assignable(output.Type, old.Type))
+		}
+	}
+
+	for _, output := range from {
+		if _, ok := previous[output.Name]; ok {
+			d.record("outputs."+output.Name, output.Type, nil, OperationRemove, true)
+		}
+	}
+}
+
+func (d *VersionDiff) diffEngines(from, to []string) {
+	for _, engine := range to {
+		if !slices.Contains(from, engine) {
+			d.record("engines."+engine, nil, engine, OperationAdd, false)
+		}
+	}
+	for _, engine := range from {
+		if !slices.Contains(to, engine) {
+			d.record("engines."+engine, engine, nil, OperationRemove, true)
+		}
+	}
+}
+
+func (d *VersionDiff) record(field string, oldValue, newValue any, operation string, breaking bool) {
+	d.Changes = append(d.Changes, FieldChange{
+		Field:     field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		Operation: operation,
+		Breaking:  breaking,
+	})
+}
+
+func (d *VersionDiff) summarize(changed bool) {
+	summary := DiffSummary{TotalChanges: len(d.Changes), RecommendedVersionBump: BumpNone}
+	if changed {
+		summary.RecommendedVersionBump = BumpPatch
+	}
+	for _, change := range d.Changes {
+		switch change.Operation {
+		case OperationAdd:
+			summary.AddedFields = append(summary.AddedFields, change.Field)
+		case OperationRemove:
+			summary.RemovedFields = append(summary.RemovedFields, change.Field)
+		default:
+			summary.ChangedFields = append(summary.ChangedFields, change.Field)
+		}
+		if change.Breaking {
+			summary.BreakingChanges++
+		}
+	}
+
+	switch {
+	case summary.BreakingChanges > 0:
+		summary.RecommendedVersionBump = BumpMajor
+	case summary.TotalChanges > 0:
+		summary.RecommendedVersionBump = BumpMinor
+	}
+	summary.IsBackwardCompatible = summary.BreakingChanges == 0
+	d.Summary = summary
+}
+
+// BreakingFields returns the fields changed in a breaking way
+func (d *VersionDiff) BreakingFields() []string {
+	var fields []string
+	for _, change := range d.Changes {
+		if change.Breaking {
+			fields = append(fields, change.Field)
+		}
+	}
+	return fields
+}
+
+// CheckVersionBump diffs next against previous, the version it succeeds,
+// and reports an error when the version number of next is not bumped
+// enough for its changes: breaking changes need a major bump, or a minor
+// bump before 1.0.0. Backward compatible additions released as a patch are
+// reported as a warning. Pre-releases make no compatibility promise and are
+// not checked
+func CheckVersionBump(previous, next *Component) (*VersionDiff, ValidationErrors) {
+	diff := DiffComponents(previous, next)
+
+	from, err := ParseSemanticVersion(previous.Version)
+	if err != nil {
+		return diff, nil
+	}
+	to, err := ParseSemanticVersion(next.Version)
+	if err != nil || from.IsPreRelease() || to.IsPreRelease() {
+		return diff, nil
+	}
+
+	var issues ValidationErrors
+	bump := VersionBump(from, to)
+	switch {
+	case diff.Summary.BreakingChanges > 0 && bump == BumpPatch,
+		diff.Summary.BreakingChanges > 0 && bump == BumpMinor && from.Major > 0:
+		required := BumpMajor
+		if from.Major == 0 {
+			required = BumpMinor
+		}
+		issues.errorf("version", RuleVersionBump, "version %s makes breaking changes since %s (%s) and needs a %s version bump",
+			next.Version, previous.Version, strings.Join(diff.BreakingFields(), ", "), required)
+	case diff.Summary.RecommendedVersionBump == BumpMinor && bump == BumpPatch:
+		issues.warnf("version", RuleVersionBump, "version %s adds to the interface of %s (%s) and should be a minor version bump",
+			next.Version, previous.Version, strings.Join(slices.Concat(diff.Summary.AddedFields, diff.Summary.ChangedFields), ", "))
+	}
+	return diff, issues
+}
+
+// VersionBump names the part of the version that changed from from to to:
+// BumpMajor, BumpMinor or BumpPatch
+func VersionBump(from, to *SemanticVersionInfo) string {
+	switch {
+	case to.Major != from.Major:
+		return BumpMajor
+	case to.Minor != from.Minor:
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
+
+// mandatory reports whether callers have to set the input
+func mandatory(input InputSpec) bool {
+	return input.Validation.Required && input.Default == nil
+}
+
+// assignable reports whether values of type from are valid values of type
+// to. Types that do not parse are compared as written
+func assignable(from, to string) bool {
+	fromType, err := ParseType(from)
+	if err != nil {
+		return from == to
+	}
+	toType, err := ParseType(to)
+	if err != nil {
+		return false
+	}
+	return fromType.AssignableTo(toType)
+}
+
+// definitionChanged reports whether anything covered by the checksum
+// changed, the version number aside
+func definitionChanged(from, to *Component) bool {
+	renumbered := *to
+	renumbered.Version = from.Version
+	a, errA := ComputeChecksum(from)
+	b, errB := ComputeChecksum(&renumbered)
+	return errA != nil || errB != nil || a != b
+}
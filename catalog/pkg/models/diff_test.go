@@ -0,0 +1,166 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diffComponent(version string) *Component {
+	return &Component{
+		Name:     "postgres",
+		Version:  version,
+		Provider: "aws",
+		Category: "database",
+		Inputs: []InputSpec{
+			{Name: "db_name", Type: "string", Description: "Database name", Validation: Validation{Required: true}},
+			{Name: "storage_gb", Type: "number", Description: "Allocated storage", Default: 20},
+			{Name: "port", Type: "number", Description: "Port"},
+		},
+		Outputs: []OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Endpoint"},
+			{Name: "tags", Type: "map(string)", Description: "Tags"},
+		},
+		Deployment: DeploymentSpec{Engine: "terraform", Version: "1.5.0"},
+	}
+}
+
+func TestDiffComponents(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(c *Component)
+		want     []FieldChange
+		wantBump string
+	}{
+		{"renumbered only", func(c *Component) {}, nil, BumpNone},
+		{"description", func(c *Component) { c.Description = "Managed PostgreSQL" }, nil, BumpPatch},
+		{"optional input added", func(c *Component) {
+			c.Inputs = append(c.Inputs, InputSpec{Name: "multi_az", Type: "bool", Description: "Multi AZ"})
+		}, []FieldChange{{Field: "inputs.multi_az", NewValue: "bool", Operation: OperationAdd}}, BumpMinor},
+		{"required input added", func(c *Component) {
+			c.Inputs = append(c.Inputs, InputSpec{Name: "vpc_id", Type: "string", Description: "VPC", Validation: Validation{Required: true}})
+		}, []FieldChange{{Field: "inputs.vpc_id", NewValue: "string", Operation: OperationAdd, Breaking: true}}, BumpMajor},
+		{"required input with default added", func(c *Component) {
+			c.Inputs = append(c.Inputs, InputSpec{Name: "engine", Type: "string", Description: "Engine", Default: "postgres", Validation: Validation{Required: true}})
+		}, []FieldChange{{Field: "inputs.engine", NewValue: "string", Operation: OperationAdd}}, BumpMinor},
+		{"input removed", func(c *Component) { c.Inputs = c.Inputs[:2] }, []FieldChange{
+			{Field: "inputs.port", OldValue: "number", Operation: OperationRemove, Breaking: true},
+		}, BumpMajor},
+		{"input made required", func(c *Component) { c.Inputs[2].Validation.Required = true }, []FieldChange{
+			{Field: "inputs.port.validation.required", OldValue: false, NewValue: true, Operation: OperationModify, Breaking: true},
+		}, BumpMajor},
+		{"default dropped from required input", func(c *Component) {
+			c.Inputs[1].Validation.Required = true
+			c.Inputs[1].Default = nil
+		}, []FieldChange{
+			{Field: "inputs.storage_gb.validation.required", OldValue: false, NewValue: true, Operation: OperationModify, Breaking: true},
+		}, BumpMajor},
+		{"input type widened", func(c *Component) { c.Inputs[2].Type = "any" }, []FieldChange{
+			{Field: "inputs.port.type", OldValue: "number", NewValue: "any", Operation: OperationModify},
+		}, BumpMinor},
+		{"input type narrowed", func(c *Component) { c.Inputs[2].Type = "integer" }, []FieldChange{
+			{Field: "inputs.port.type", OldValue: "number", NewValue: "integer", Operation: OperationModify, Breaking: true},
+		}, BumpMajor},
+		{"output removed", func(c *Component) { c.Outputs = c.Outputs[:1] }, []FieldChange{
+			{Field: "outputs.tags", OldValue: "map(string)", Operation: OperationRemove, Breaking: true},
+		}, BumpMajor},
+		{"output type narrowed", func(c *Component) { c.Outputs[1].Type = "object({team = string})" }, []FieldChange{
+			{Field: "outputs.tags.type", OldValue: "map(string)", NewValue: "object({team = string})", Operation: OperationModify},
+		}, BumpMinor},
+		{"output type changed", func(c *Component) { c.Outputs[0].Type = "list(string)" }, []FieldChange{
+			{Field: "outputs.endpoint.type", OldValue: "string", NewValue: "list(string)", Operation: OperationModify, Breaking: true},
+		}, BumpMajor},
+		{"engine added", func(c *Component) {
+			c.AdditionalDeployments = []DeploymentSpec{{Engine: "pulumi", Version: "3.0.0"}}
+		}, []FieldChange{{Field: "engines.pulumi", NewValue: "pulumi", Operation: OperationAdd}}, BumpMinor},
+		{"engine replaced", func(c *Component) { c.Deployment.Engine = "pulumi" }, []FieldChange{
+			{Field: "engines.pulumi", NewValue: "pulumi", Operation: OperationAdd},
+			{Field: "engines.terraform", OldValue: "terraform", Operation: OperationRemove, Breaking: true},
+		}, BumpMajor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := diffComponent("1.1.0")
+			tt.change(next)
+
+			diff := DiffComponents(diffComponent("1.0.0"), next)
+			assert.Equal(t, tt.want, diff.Changes)
+			assert.Equal(t, tt.wantBump, diff.Summary.RecommendedVersionBump)
+			assert.Equal(t, len(tt.want), diff.Summary.TotalChanges)
+			assert.Equal(t, tt.wantBump != BumpMajor, diff.Summary.IsBackwardCompatible)
+			assert.Equal(t, "1.0.0", diff.FromVersion)
+			assert.Equal(t, "1.1.0", diff.ToVersion)
+		})
+	}
+}
+
+func TestCheckVersionBump(t *testing.T) {
+	removeInput := func(c *Component) { c.Inputs = c.Inputs[:2] }
+	addInput := func(c *Component) {
+		c.Inputs = append(c.Inputs, InputSpec{Name: "multi_az", Type: "bool", Description: "Multi AZ"})
+	}
+	describe := func(c *Component) { c.Description = "Managed PostgreSQL" }
+
+	tests := []struct {
+		name         string
+		previous     string
+		next         string
+		change       func(c *Component)
+		wantSeverity Severity
+		wantMessage  string
+	}{
+		{"breaking major", "1.2.0", "2.0.0", removeInput, "", ""},
+		{"breaking minor", "1.2.0", "1.3.0", removeInput, SeverityError, "version 1.3.0 makes breaking changes since 1.2.0 (inputs.port) and needs a major version bump"},
+		{"breaking patch", "1.2.0", "1.2.1", removeInput, SeverityError, "needs a major version bump"},
+		{"breaking minor before 1.0.0", "0.2.0", "0.3.0", removeInput, "", ""},
+		{"breaking patch before 1.0.0", "0.2.0", "0.2.1", removeInput, SeverityError, "needs a minor version bump"},
+		{"breaking pre-release", "1.2.0", "1.2.1-rc.1", removeInput, "", ""},
+		{"after a pre-release", "2.0.0-rc.1", "2.0.0", removeInput, "", ""},
+		{"addition minor", "1.2.0", "1.3.0", addInput, "", ""},
+		{"addition patch", "1.2.0", "1.2.1", addInput, SeverityWarning, "adds to the interface of 1.2.0 (inputs.multi_az) and should be a minor version bump"},
+		{"fix patch", "1.2.0", "1.2.1", describe, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := diffComponent(tt.next)
+			tt.change(next)
+
+			diff, issues := CheckVersionBump(diffComponent(tt.previous), next)
+			require.NotNil(t, diff)
+			if tt.wantSeverity == "" {
+				assert.Empty(t, issues)
+				return
+			}
+			require.Len(t, issues, 1)
+			assert.Equal(t, tt.wantSeverity, issues[0].Severity)
+			assert.Equal(t, RuleVersionBump, issues[0].Rule)
+			assert.Equal(t, "version", issues[0].Path)
+			assert.Contains(t, issues[0].Message, tt.wantMessage)
+		})
+	}
+}
+
+func TestComponent_Lifecycle(t *testing.T) {
+	component := diffComponent("1.0.0")
+	assert.Equal(t, VersionStatusActive, component.Status())
+	assert.False(t, component.IsDraft())
+
+	component.Metadata.Status = VersionStatusDraft
+	assert.Equal(t, VersionStatusDraft, component.Status())
+	assert.True(t, component.IsDraft())
+
+	component.Maintainers = []string{"platform@example.com"}
+	assert.True(t, component.IsMaintainer("platform@example.com"))
+	assert.False(t, component.IsMaintainer("data@example.com"))
+	assert.False(t, component.IsMaintainer(""))
+
+	checksum, err := ComputeChecksum(component)
+	require.NoError(t, err)
+	component.Metadata.Status = VersionStatusActive
+	assert.NoError(t, component.VerifyChecksum())
+	component.Metadata.Checksum = checksum
+	assert.NoError(t, component.VerifyChecksum(), "promotion does not change the checksum")
+}
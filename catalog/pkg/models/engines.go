@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EngineSchema describes the deployment config and versions an engine accepts
+type EngineSchema struct {
+	Engine string
+	// Config is the object type of DeploymentSpec.Config
+	Config *Type
+	// AdditionalConfig allows keys Config does not declare, for settings
+	// passed through to the engine as is
+	AdditionalConfig bool
+	// ValidateVersion checks DeploymentSpec.Version, nil accepts any version
+	ValidateVersion func(version string) error
+	// Outputs is the config key mapping each component output to what the
+	// engine produces, such as a Terraform module output. Without it outputs
+	// are expected under their own names.
+	Outputs string
+}
+
+// Check validates config against the schema, naming fields from path
+func (s *EngineSchema) Check(path string, config map[string]any) []FieldError {
+	if config == nil {
+		config = map[string]any{}
+	}
+	errs := s.Config.Check(path, config)
+	if s.AdditionalConfig {
+		errs = slices.DeleteFunc(errs, func(err FieldError) bool {
+			return err.Rule == RuleUnknown && !strings.Contains(strings.TrimPrefix(err.Field, path+"."), ".")
+		})
+	}
+	return errs
+}
+
+// EngineRegistry holds the config schemas of deployment engines, keyed by
+// DeploymentSpec.Engine. Engines without a schema are not checked.
+type EngineRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*EngineSchema
+}
+
+// NewEngineRegistry creates a registry with the given schemas
+func NewEngineRegistry(schemas ...EngineSchema) *EngineRegistry {
+	r := &EngineRegistry{schemas: make(map[string]*EngineSchema, len(schemas))}
+	for _, schema := range schemas {
+		r.Register(schema)
+	}
+	return r
+}
+
+// Register adds the schema of an engine, replacing any previous one
+func (r *EngineRegistry) Register(schema EngineSchema) {
+	if schema.Config == nil {
+		schema.Config = &Type{Kind: KindObject}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[schema.Engine] = &schema
+}
+
+// Lookup returns the schema of engine
+func (r *EngineRegistry) Lookup(engine string) (*EngineSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schema, ok := r.schemas[engine]
+	return schema, ok
+}
+
+// Engines returns the engines with a registered schema, sorted
+func (r *EngineRegistry) Engines() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	engines := make([]string, 0, len(r.schemas))
+	for engine := range r.schemas {
+		engines = append(engines, engine)
+	}
+	slices.Sort(engines)
+	return engines
+}
+
+// DefaultEngineRegistry holds the schemas of the built-in engines and is used
+// by NewComponentValidator
+var DefaultEngineRegistry = NewEngineRegistry(
+	EngineSchema{
+		Engine:           "terraform",
+		Config:           MustParseType("object({source = string, module_version = string, outputs = optional(map(string))})"),
+		AdditionalConfig: true,
+		ValidateVersion:  validateTerraformConstraint,
+		Outputs:          "outputs",
+	},
+	EngineSchema{
+		Engine:           "helm",
+		Config:           MustParseType("object({chart = string, repo = string, version = string, values = optional(map(any)), outputs = optional(map(string))})"),
+		AdditionalConfig: true,
+		ValidateVersion: func(version string) error {
+			_, err := NewConstraintParser().Parse(version)
+			return err
+		},
+		Outputs: "outputs",
+	},
+	EngineSchema{
+		Engine:           "crossplane",
+		Config:           MustParseType("object({apiVersion = string, kind = string, compositionRef = object({name = string}), connectionDetails = optional(map(string))})"),
+		AdditionalConfig: true,
+		ValidateVersion: func(version string) error {
+			if _, err := ParseSemanticVersion(version); err != nil {
+				return fmt.Errorf("crossplane versions must be exact: %w", err)
+			}
+			return nil
+		},
+		Outputs: "connectionDetails",
+	},
+)
+
+// RegisterEngineSchema registers a schema with the default registry
+func RegisterEngineSchema(schema EngineSchema) {
+	DefaultEngineRegistry.Register(schema)
+}
+
+// terraformConstraintPattern matches a single Terraform version constraint
+// such as "~> 1.5" or ">= 1.5.0"
+var terraformConstraintPattern = regexp.MustCompile(`^(=|!=|>|>=|<|<=|~>)?\s*v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?$`)
+
+// validateTerraformConstraint checks a Terraform required_version style
+// constraint: comma-separated operators and versions
+func validateTerraformConstraint(version string) error {
+	for _, part := range strings.Split(version, ",") {
+		if !terraformConstraintPattern.MatchString(strings.TrimSpace(part)) {
+			return fmt.Errorf("'%s' is not a valid terraform version constraint", strings.TrimSpace(part))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentValidator_ValidateEngineConfig(t *testing.T) {
+	validator := NewComponentValidator()
+
+	tests := []struct {
+		name   string
+		mutate func(c *Component)
+		paths  []string
+	}{
+		{
+			name:   "valid component",
+			mutate: func(c *Component) {},
+		},
+		{
+			name:   "terraform missing source",
+			mutate: func(c *Component) { delete(c.Deployment.Config, "source") },
+			paths:  []string{"deployment.config.source"},
+		},
+		{
+			name:   "terraform without config",
+			mutate: func(c *Component) { c.Deployment.Config = nil },
+			paths:  []string{"deployment.config.module_version", "deployment.config.source"},
+		},
+		{
+			name:   "terraform wrong type",
+			mutate: func(c *Component) { c.Deployment.Config["module_version"] = 1 },
+			paths:  []string{"deployment.config.module_version"},
+		},
+		{
+			name:   "terraform allows additional keys",
+			mutate: func(c *Component) { c.Deployment.Config["region"] = "eu-west-1" },
+		},
+		{
+			name:   "terraform pessimistic constraint",
+			mutate: func(c *Component) { c.Deployment.Version = "~> 1.5" },
+		},
+		{
+			name:   "terraform compound constraint",
+			mutate: func(c *Component) { c.Deployment.Version = ">= 1.5.0, < 2.0.0" },
+		},
+		{
+			name:   "terraform invalid constraint",
+			mutate: func(c *Component) { c.Deployment.Version = "latest" },
+			paths:  []string{"deployment.version"},
+		},
+		{
+			name:   "crossplane nested field",
+			mutate: func(c *Component) { c.AdditionalDeployments[0].Config["compositionRef"] = map[string]any{} },
+			paths:  []string{"additional_deployments[0].config.compositionRef.name"},
+		},
+		{
+			name:   "crossplane unknown nested key",
+			mutate: func(c *Component) { c.AdditionalDeployments[0].Config["compositionRef"].(map[string]any)["ref"] = "x" },
+			paths:  []string{"additional_deployments[0].config.compositionRef.ref"},
+		},
+		{
+			name:   "crossplane range version",
+			mutate: func(c *Component) { c.AdditionalDeployments[0].Version = "^1.14.0" },
+			paths:  []string{"additional_deployments[0].version"},
+		},
+		{
+			name: "helm",
+			mutate: func(c *Component) {
+				c.AdditionalDeployments = append(c.AdditionalDeployments, DeploymentSpec{
+					Engine:  "helm",
+					Version: "^3.12.0",
+					Config:  map[string]any{"chart": "postgresql", "repo": "https://charts.example.com", "version": "12.1.0"},
+				})
+			},
+		},
+		{
+			name: "helm values must be a map",
+			mutate: func(c *Component) {
+				c.AdditionalDeployments = append(c.AdditionalDeployments, DeploymentSpec{
+					Engine:  "helm",
+					Version: "3.12.0",
+					Config:  map[string]any{"chart": "postgresql", "repo": "https://charts.example.com", "version": "12.1.0", "values": "replicas=2"},
+				})
+			},
+			paths: []string{"additional_deployments[2].config.values"},
+		},
+		{
+			name:   "unregistered engine is not checked",
+			mutate: func(c *Component) { c.AdditionalDeployments[1].Config = map[string]any{"anything": true} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := multiEngineComponent()
+			tt.mutate(component)
+
+			assert.ElementsMatch(t, tt.paths, issuePaths(validator.Issues(component).Errors()))
+		})
+	}
+}
+
+func TestComponentValidator_EngineConfigMessage(t *testing.T) {
+	component := multiEngineComponent()
+	delete(component.Deployment.Config, "module_version")
+
+	err := NewComponentValidator().Validate(component)
+	require.Error(t, err)
+
+	var issues ValidationErrors
+	require.ErrorAs(t, err, &issues)
+	require.Len(t, issues, 1)
+	assert.Equal(t, ValidationIssue{
+		Path:     "deployment.config.module_version",
+		Rule:     RuleRequired,
+		Message:  "deployment for engine 'terraform' has invalid config: deployment.config.module_version: is required",
+		Severity: SeverityError,
+	}, issues[0])
+}
+
+func TestComponentValidator_WithEngineRegistry(t *testing.T) {
+	registry := NewEngineRegistry(EngineSchema{
+		Engine: "pulumi",
+		Config: MustParseType("object({project = string})"),
+		ValidateVersion: func(version string) error {
+			if version != "3.0.0" {
+				return errors.New("only 3.0.0 is supported")
+			}
+			return nil
+		},
+	})
+	validator := NewComponentValidator().WithEngineRegistry(registry)
+
+	component := multiEngineComponent()
+	component.Deployment.Config = nil
+	component.AdditionalDeployments[1].Version = "2.0.0"
+
+	issues := validator.Issues(component).Errors()
+	require.Len(t, issues, 2)
+	assert.Equal(t, "additional_deployments[1].version", issues[0].Path)
+	assert.Contains(t, issues[0].Message, "only 3.0.0 is supported")
+	assert.Equal(t, "additional_deployments[1].config.project", issues[1].Path)
+
+	assert.Equal(t, []string{"pulumi"}, registry.Engines())
+	assert.Equal(t, []string{"crossplane", "helm", "terraform"}, DefaultEngineRegistry.Engines())
+}
+
+func TestEngineSchema_Check(t *testing.T) {
+	schema := EngineSchema{Engine: "custom", Config: MustParseType("object({name = string})")}
+
+	assert.Empty(t, schema.Check("config", map[string]any{"name": "x"}))
+
+	errs := schema.Check("config", map[string]any{"name": "x", "extra": true})
+	require.Len(t, errs, 1)
+	assert.Equal(t, RuleUnknown, errs[0].Rule)
+
+	schema.AdditionalConfig = true
+	assert.Empty(t, schema.Check("config", map[string]any{"name": "x", "extra": true}))
+}
+
+func TestValidateTerraformConstraint(t *testing.T) {
+	for _, version := range []string{"1.5.0", "1.5", "~> 1.5", ">= 1.5.0, < 2.0.0", "!= 1.6.0", "= v1.5.0"} {
+		assert.NoError(t, validateTerraformConstraint(version), version)
+	}
+	for _, version := range []string{"", "latest", "^1.5.0", ">= 1.5.0,", "1.x"} {
+		assert.Error(t, validateTerraformConstraint(version), version)
+	}
+}
@@ -0,0 +1,724 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed condition, as used by InputSpec.RequiredIf and
+// Dependency.Condition. Expressions only read input values: they have no
+// side effects, no function calls and no loops, so they are safe to evaluate
+// on untrusted input.
+//
+// The grammar is:
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | compare
+//	compare = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "in" ) operand ]
+//	operand = literal | path | "[" [ literal { "," literal } ] "]" | "(" expr ")"
+//	literal = string | number | "true" | "false" | "null"
+//	path    = [ "inputs." ] name { "." attribute }
+//
+// Strings are single or double quoted. A path names an input, optionally
+// followed by the attributes of object and map values. Missing inputs are
+// null, null is false in boolean context, and ordering comparisons with null
+// are false.
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// ParseExpression parses a condition
+func ParseExpression(source string) (*Expression, error) {
+	p := &exprParser{lexer: exprLexer{input: source}}
+	if err := p.next(); err != nil {
+		return nil, fmt.Errorf("invalid expression '%s': %w", source, err)
+	}
+	root, err := p.parseOr()
+	if err == nil && p.tok.kind != tokEOF {
+		err = fmt.Errorf("unexpected '%s' at position %d", p.tok.text, p.tok.pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression '%s': %w", source, err)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Inputs returns the names of the inputs the expression reads, sorted
+func (e *Expression) Inputs() []string {
+	var names []string
+	walkExpr(e.root, func(node exprNode) {
+		if path, ok := node.(*pathNode); ok && !slices.Contains(names, path.input) {
+			names = append(names, path.input)
+		}
+	})
+	slices.Sort(names)
+	return names
+}
+
+// Check type-checks the expression against inputs. It fails when the
+// expression reads an unknown input or attribute, compares values of
+// different types, or does not produce a bool.
+func (e *Expression) Check(inputs []InputSpec) error {
+	types := make(map[string]*Type, len(inputs))
+	for _, input := range inputs {
+		typ, err := input.ParsedType()
+		if err != nil {
+			typ = &Type{Kind: KindAny}
+		}
+		types[input.Name] = typ
+	}
+
+	typ, err := e.root.check(types)
+	if err != nil {
+		return err
+	}
+	if typ != nil && typ.Kind != KindBool && typ.Kind != KindAny {
+		return fmt.Errorf("expression is of type %s, not bool", typ.withoutOptional())
+	}
+	return nil
+}
+
+// Eval evaluates the expression against input values
+func (e *Expression) Eval(values map[string]any) (bool, error) {
+	value, err := e.root.eval(values)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value)
+}
+
+// EvalCondition parses and evaluates a condition against input values. An
+// empty condition is true.
+func EvalCondition(condition string, values map[string]any) (bool, error) {
+	if condition == "" {
+		return true, nil
+	}
+	expr, err := ParseExpression(condition)
+	if err != nil {
+		return false, err
+	}
+	return expr.Eval(values)
+}
+
+// checkCondition parses a condition and type-checks it against inputs
+func checkCondition(condition string, inputs []InputSpec) error {
+	expr, err := ParseExpression(condition)
+	if err != nil {
+		return err
+	}
+	return expr.Check(inputs)
+}
+
+// DependencyApplies reports whether dep is needed for the given input values
+// of the component, which are completed with defaults
+func (c *Component) DependencyApplies(dep Dependency, values map[string]any) (bool, error) {
+	applies, err := EvalCondition(dep.Condition, WithDefaults(c, values))
+	if err != nil {
+		return false, fmt.Errorf("condition of dependency '%s' of %s: %w", dep.Name, c.GetID(), err)
+	}
+	return applies, nil
+}
+
+// exprNode is a node of a parsed expression. check returns the type of the
+// node, nil for null.
+type exprNode interface {
+	check(inputs map[string]*Type) (*Type, error)
+	eval(values map[string]any) (any, error)
+}
+
+type literalNode struct {
+	value any
+}
+
+type pathNode struct {
+	input string
+	attrs []string
+}
+
+type listNode struct {
+	items []*literalNode
+}
+
+type notNode struct {
+	operand exprNode
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func walkExpr(node exprNode, visit func(exprNode)) {
+	visit(node)
+	switch n := node.(type) {
+	case *notNode:
+		walkExpr(n.operand, visit)
+	case *binaryNode:
+		walkExpr(n.left, visit)
+		walkExpr(n.right, visit)
+	}
+}
+
+func (n *literalNode) check(map[string]*Type) (*Type, error) {
+	return literalType(n.value), nil
+}
+
+func (n *literalNode) eval(map[string]any) (any, error) {
+	return n.value, nil
+}
+
+func literalType(value any) *Type {
+	switch value.(type) {
+	case string:
+		return &Type{Kind: KindString}
+	case float64:
+		return &Type{Kind: KindNumber}
+	case bool:
+		return &Type{Kind: KindBool}
+	default:
+		return nil
+	}
+}
+
+func (n *pathNode) String() string {
+	return strings.Join(append([]string{n.input}, n.attrs...), ".")
+}
+
+func (n *pathNode) check(inputs map[string]*Type) (*Type, error) {
+	typ, ok := inputs[n.input]
+	if !ok {
+		return nil, fmt.Errorf("unknown input '%s'", n.input)
+	}
+	for i, attr := range n.attrs {
+		switch typ.Kind {
+		case KindAny:
+			return typ, nil
+		case KindMap:
+			typ = typ.Elem
+		case KindObject:
+			field, ok := typ.Field(attr)
+			if !ok {
+				return nil, fmt.Errorf("'%s' has no attribute '%s'", strings.Join(append([]string{n.input}, n.attrs[:i]...), "."), attr)
+			}
+			typ = field.Type
+		default:
+			return nil, fmt.Errorf("'%s' is of type %s and has no attributes", strings.Join(append([]string{n.input}, n.attrs[:i]...), "."), typ.withoutOptional())
+		}
+	}
+	return typ, nil
+}
+
+func (n *pathNode) eval(values map[string]any) (any, error) {
+	value := values[n.input]
+	for _, attr := range n.attrs {
+		if value == nil {
+			return nil, nil
+		}
+		entries, ok := stringKeyedEntries(value)
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not an object", n)
+		}
+		value = entries[attr]
+	}
+	return value, nil
+}
+
+func (n *listNode) check(map[string]*Type) (*Type, error) {
+	elem := &Type{Kind: KindAny}
+	for i, item := range n.items {
+		typ := literalType(item.value)
+		switch {
+		case typ == nil:
+			return nil, fmt.Errorf("lists cannot contain null")
+		case i == 0:
+			elem = typ
+		case typ.Kind != elem.Kind:
+			return nil, fmt.Errorf("list mixes %s and %s values", elem, typ)
+		}
+	}
+	return &Type{Kind: KindList, Elem: elem}, nil
+}
+
+func (n *listNode) eval(map[string]any) (any, error) {
+	items := make([]any, len(n.items))
+	for i, item := range n.items {
+		items[i] = item.value
+	}
+	return items, nil
+}
+
+func (n *notNode) check(inputs map[string]*Type) (*Type, error) {
+	typ, err := n.operand.check(inputs)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectBool("!", typ); err != nil {
+		return nil, err
+	}
+	return &Type{Kind: KindBool}, nil
+}
+
+func (n *notNode) eval(values map[string]any) (any, error) {
+	value, err := n.operand.eval(values)
+	if err != nil {
+		return nil, err
+	}
+	b, err := truthy(value)
+	return !b, err
+}
+
+func (n *binaryNode) check(inputs map[string]*Type) (*Type, error) {
+	left, err := n.left.check(inputs)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.check(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	boolean := &Type{Kind: KindBool}
+	switch n.op {
+	case "&&", "||":
+		if err := expectBool(n.op, left); err != nil {
+			return nil, err
+		}
+		return boolean, expectBool(n.op, right)
+	case "==", "!=":
+		if left == nil || right == nil || comparableTypes(left, right) {
+			return boolean, nil
+		}
+		return nil, fmt.Errorf("cannot compare %s with %s", left.withoutOptional(), right.withoutOptional())
+	case "<", "<=", ">", ">=":
+		if left != nil && right != nil && orderable(left) && orderable(right) && comparableTypes(left, right) {
+			return boolean, nil
+		}
+		return nil, fmt.Errorf("operator %s needs two numbers or two strings, not %s and %s", n.op, describeExprType(left), describeExprType(right))
+	case "in":
+		if right == nil || (right.Kind != KindList && right.Kind != KindSet && right.Kind != KindAny) {
+			return nil, fmt.Errorf("operator in needs a list on its right, not %s", describeExprType(right))
+		}
+		if left == nil || right.Kind == KindAny || comparableTypes(left, right.Elem) {
+			return boolean, nil
+		}
+		return nil, fmt.Errorf("cannot look for %s in %s", left.withoutOptional(), right.withoutOptional())
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func (n *binaryNode) eval(values map[string]any) (any, error) {
+	left, err := n.left.eval(values)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		b, err := truthy(left)
+		if err != nil || b == (n.op == "||") {
+			return b, err
+		}
+		right, err := n.right.eval(values)
+		if err != nil {
+			return nil, err
+		}
+		return truthy(right)
+	}
+
+	right, err := n.right.eval(values)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equalValues(left, right), nil
+	case "!=":
+		return !equalValues(left, right), nil
+	case "in":
+		if right == nil {
+			return false, nil
+		}
+		items := reflect.ValueOf(right)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			return nil, fmt.Errorf("operator in needs a list on its right")
+		}
+		for i := range items.Len() {
+			if equalValues(left, items.Index(i).Interface()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if left == nil || right == nil {
+		return false, nil
+	}
+	cmp, err := compareValues(left, right)
+	if err != nil {
+		return nil, fmt.Errorf("operator %s: %w", n.op, err)
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func expectBool(op string, typ *Type) error {
+	if typ == nil || typ.Kind == KindBool || typ.Kind == KindAny {
+		return nil
+	}
+	return fmt.Errorf("operator %s needs bool operands, not %s", op, typ.withoutOptional())
+}
+
+func describeExprType(typ *Type) string {
+	if typ == nil {
+		return "null"
+	}
+	return typ.withoutOptional().String()
+}
+
+// comparableTypes reports whether values of a and b can be compared for equality
+func comparableTypes(a, b *Type) bool {
+	if a.Kind == KindAny || b.Kind == KindAny {
+		return true
+	}
+	numeric := func(t *Type) bool { return t.Kind == KindNumber || t.Kind == KindInteger }
+	if numeric(a) && numeric(b) {
+		return true
+	}
+	switch a.Kind {
+	case KindString, KindBool:
+		return a.Kind == b.Kind
+	}
+	return false
+}
+
+func orderable(t *Type) bool {
+	switch t.Kind {
+	case KindString, KindNumber, KindInteger, KindAny:
+		return true
+	}
+	return false
+}
+
+func truthy(value any) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return false, fmt.Errorf("%T is not a bool", value)
+}
+
+func equalValues(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compareValues(a, b any) (int, error) {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot order %T and %T", a, b)
+}
+
+// exprParser is a recursive descent parser for the expression grammar
+type exprParser struct {
+	lexer exprLexer
+	tok   exprToken
+}
+
+func (p *exprParser) next() error {
+	tok, err := p.lexer.next()
+	p.tok = tok
+	return err
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "&&")
+}
+
+func (p *exprParser) parseBinary(operand func() (exprNode, error), op string) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOperator && p.tok.text == op {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.tok.kind == tokOperator && p.tok.text == "!" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+var comparisonOperators = []string{"==", "!=", "<", "<=", ">", ">=", "in"}
+
+func (p *exprParser) parseCompare() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	isOperator := p.tok.kind == tokOperator || (p.tok.kind == tokIdent && p.tok.text == "in")
+	if !isOperator || !slices.Contains(comparisonOperators, p.tok.text) {
+		return left, nil
+	}
+	op := p.tok.text
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokOperator && tok.text == "(":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case tok.kind == tokOperator && tok.text == "[":
+		return p.parseList()
+	case tok.kind == tokIdent && !isKeyword(tok.text):
+		return p.parsePath()
+	}
+
+	literal, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	return literal, nil
+}
+
+func (p *exprParser) parseLiteral() (*literalNode, error) {
+	tok := p.tok
+	var value any
+	switch tok.kind {
+	case tokString:
+		value = tok.value
+	case tokNumber:
+		value = tok.value
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			return nil, fmt.Errorf("unexpected '%s' at position %d", tok.text, tok.pos)
+		}
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected '%s' at position %d", tok.text, tok.pos)
+	}
+	return &literalNode{value: value}, p.next()
+}
+
+func (p *exprParser) parseList() (exprNode, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	list := &listNode{}
+	for !(p.tok.kind == tokOperator && p.tok.text == "]") {
+		item, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		list.items = append(list.items, item)
+		if p.tok.kind != tokOperator || p.tok.text != "," {
+			break
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	return list, p.expect("]")
+}
+
+func (p *exprParser) parsePath() (exprNode, error) {
+	parts := strings.Split(p.tok.text, ".")
+	if len(parts) > 1 && parts[0] == templateRoot {
+		parts = parts[1:]
+	}
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid path '%s' at position %d", p.tok.text, p.tok.pos)
+		}
+	}
+	return &pathNode{input: parts[0], attrs: parts[1:]}, p.next()
+}
+
+func (p *exprParser) expect(text string) error {
+	if p.tok.kind != tokOperator || p.tok.text != text {
+		if p.tok.kind == tokEOF {
+			return fmt.Errorf("expected '%s' at end of expression", text)
+		}
+		return fmt.Errorf("expected '%s' at position %d, found '%s'", text, p.tok.pos, p.tok.text)
+	}
+	return p.next()
+}
+
+func isKeyword(word string) bool {
+	switch word {
+	case "true", "false", "null", "in":
+		return true
+	}
+	return false
+}
+
+type exprTokenKind int
+
+const (
+	tokEOF exprTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOperator
+)
+
+type exprToken struct {
+	kind  exprTokenKind
+	text  string
+	value any
+	pos   int
+}
+
+// exprLexer splits an expression into tokens
+type exprLexer struct {
+	input string
+	pos   int
+}
+
+var exprOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ","}
+
+func (l *exprLexer) next() (exprToken, error) {
+	for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if start >= len(l.input) {
+		return exprToken{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.input[start]
+	switch {
+	case c == '"' || c == '\'':
+		return l.string(c)
+	case c == '-' || (c >= '0' && c <= '9'):
+		l.pos++
+		for l.pos < len(l.input) && strings.IndexByte("0123456789.eE+-", l.input[l.pos]) >= 0 {
+			l.pos++
+		}
+		text := l.input[start:l.pos]
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return exprToken{}, fmt.Errorf("invalid number '%s' at position %d", text, start)
+		}
+		return exprToken{kind: tokNumber, text: text, value: n, pos: start}, nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for l.pos < len(l.input) {
+			c := l.input[l.pos]
+			if c != '_' && c != '-' && c != '.' && !unicode.IsLetter(rune(c)) && !unicode.IsDigit(rune(c)) {
+				break
+			}
+			l.pos++
+		}
+		return exprToken{kind: tokIdent, text: l.input[start:l.pos], pos: start}, nil
+	}
+
+	for _, op := range exprOperators {
+		if strings.HasPrefix(l.input[start:], op) {
+			l.pos += len(op)
+			return exprToken{kind: tokOperator, text: op, pos: start}, nil
+		}
+	}
+	return exprToken{}, fmt.Errorf("unexpected '%c' at position %d", c, start)
+}
+
+func (l *exprLexer) string(quote byte) (exprToken, error) {
+	start := l.pos
+	var b strings.Builder
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		c := l.input[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.input):
+			l.pos++
+			b.WriteByte(l.input[l.pos])
+		case c == quote:
+			l.pos++
+			return exprToken{kind: tokString, text: l.input[start:l.pos], value: b.String(), pos: start}, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return exprToken{}, fmt.Errorf("unterminated string at position %d", start)
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func expressionInputs() []InputSpec {
+	return []InputSpec{
+		{Name: "multi_az", Type: "bool"},
+		{Name: "replicas", Type: "integer"},
+		{Name: "tier", Type: "string"},
+		{Name: "zones", Type: "list(string)"},
+		{Name: "network", Type: "object({public = bool, cidr = optional(string)})"},
+		{Name: "tags", Type: "map(string)"},
+		{Name: "extra", Type: "any"},
+	}
+}
+
+func TestExpression_Eval(t *testing.T) {
+	values := map[string]any{
+		"multi_az": true,
+		"replicas": 3,
+		"tier":     "gold",
+		"zones":    []any{"a", "b"},
+		"network":  map[string]any{"public": false},
+		"tags":     map[string]string{"env": "prod"},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"multi_az", true},
+		{"inputs.multi_az == true", true},
+		{"!multi_az", false},
+		{"replicas >= 3 && replicas < 5", true},
+		{"replicas > 3 || tier == 'gold'", true},
+		{`tier in ["silver", "gold"]`, true},
+		{"'c' in zones", false},
+		{"network.public", false},
+		{"network.cidr == null", true},
+		{"tags.env != 'dev'", true},
+		{"extra", false},
+		{"extra == null && !(replicas == 2)", true},
+		{"extra > 1", false},
+		{"replicas == 3.0", true},
+		{"tier < 'silver'", true},
+		{"-1 < replicas", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expr)
+			require.NoError(t, err)
+			require.NoError(t, expr.Check(expressionInputs()))
+
+			got, err := expr.Eval(values)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpression_ParseErrors(t *testing.T) {
+	for _, source := range []string{
+		"",
+		"multi_az ==",
+		"(multi_az",
+		"tier == 'gold",
+		"tier in [zones]",
+		"multi_az multi_az",
+		"replicas = 3",
+		"inputs..tier",
+		"1.2.3 == replicas",
+	} {
+		t.Run(source, func(t *testing.T) {
+			_, err := ParseExpression(source)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExpression_Check(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"region == 'eu'", "unknown input 'region'"},
+		{"network.private", "'network' has no attribute 'private'"},
+		{"tier.name == 'x'", "'tier' is of type string and has no attributes"},
+		{"replicas == 'three'", "cannot compare integer with string"},
+		{"zones == ['a']", "cannot compare list(string) with list(string)"},
+		{"tier > 3", "operator > needs two numbers or two strings"},
+		{"multi_az && tier", "operator && needs bool operands, not string"},
+		{"!replicas", "operator ! needs bool operands, not integer"},
+		{"replicas in zones", "cannot look for integer in list(string)"},
+		{"tier in 'gold'", "operator in needs a list on its right"},
+		{"[1, 'a'] == zones", "list mixes number and string values"},
+		{"tier", "expression is of type string, not bool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := ParseExpression(tt.expr)
+			require.NoError(t, err)
+			assert.ErrorContains(t, expr.Check(expressionInputs()), tt.want)
+		})
+	}
+}
+
+func TestExpression_Inputs(t *testing.T) {
+	expr, err := ParseExpression("network.public && (tier == 'gold' || inputs.tier == 'silver') && multi_az")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"multi_az", "network", "tier"}, expr.Inputs())
+	assert.Equal(t, "network.public && (tier == 'gold' || inputs.tier == 'silver') && multi_az", expr.String())
+}
+
+func TestExpression_EvalErrors(t *testing.T) {
+	_, err := EvalCondition("multi_az", map[string]any{"multi_az": "yes"})
+	assert.ErrorContains(t, err, "string is not a bool")
+
+	_, err = EvalCondition("tier > 1", map[string]any{"tier": "gold"})
+	assert.ErrorContains(t, err, "cannot order string and float64")
+
+	applies, err := EvalCondition("", nil)
+	require.NoError(t, err)
+	assert.True(t, applies)
+}
+
+func TestComponent_DependencyApplies(t *testing.T) {
+	component := validRelationsComponent()
+	component.Inputs = append(component.Inputs, InputSpec{Name: "encrypted", Type: "bool", Description: "Encrypt storage", Default: true})
+	dep := Dependency{Name: "kms-key", Type: "component", Version: ">=2.0.0", Condition: "encrypted"}
+
+	applies, err := component.DependencyApplies(dep, nil)
+	require.NoError(t, err)
+	assert.True(t, applies, "defaults are applied")
+
+	applies, err = component.DependencyApplies(dep, map[string]any{"encrypted": false})
+	require.NoError(t, err)
+	assert.False(t, applies)
+
+	dep.Condition = "encrypted =="
+	_, err = component.DependencyApplies(dep, nil)
+	assert.ErrorContains(t, err, "condition of dependency 'kms-key' of postgres:1.0.0")
+}
+
+func TestComponentValidator_ValidateConditions(t *testing.T) {
+	validator := NewComponentValidator()
+
+	tests := []struct {
+		name   string
+		mutate func(c *Component)
+		paths  []string
+	}{
+		{
+			name: "valid conditions",
+			mutate: func(c *Component) {
+				c.Inputs[1].RequiredIf = "multi_az"
+				c.Dependencies[1].Condition = "multi_az == false"
+			},
+		},
+		{
+			name:   "required_if on a required input",
+			mutate: func(c *Component) { c.Inputs[1].RequiredIf = "multi_az"; c.Inputs[1].Validation.Required = true },
+			paths:  []string{"inputs[1].required_if"},
+		},
+		{
+			name:   "required_if with unknown input",
+			mutate: func(c *Component) { c.Inputs[1].RequiredIf = "region == 'eu'" },
+			paths:  []string{"inputs[1].required_if"},
+		},
+		{
+			name:   "condition type mismatch",
+			mutate: func(c *Component) { c.Dependencies[1].Condition = "multi_az == 'yes'" },
+			paths:  []string{"dependencies[1].condition"},
+		},
+		{
+			name:   "condition syntax error",
+			mutate: func(c *Component) { c.Dependencies[0].Condition = "multi_az &&" },
+			paths:  []string{"dependencies[0].condition"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := validRelationsComponent()
+			component.Inputs = append(component.Inputs,
+				InputSpec{Name: "replicas", Type: "integer", Description: "Replica count"},
+				InputSpec{Name: "multi_az", Type: "bool", Description: "Deploy across zones"},
+			)
+			tt.mutate(component)
+
+			issues := validator.Issues(component).Errors()
+			assert.ElementsMatch(t, tt.paths, issuePaths(issues))
+			for _, issue := range issues {
+				assert.Contains(t, []string{RuleCondition, RuleConflict}, issue.Rule)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rules reported in FieldError.Rule and ValidationIssue.Rule
+const (
+	RuleRequired  = "required"
+	RuleType      = "type"
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RulePattern   = "pattern"
+	RuleEnum      = "enum"
+	RuleMin       = "min"
+	RuleMax       = "max"
+	RuleUnknown   = "unknown"
+	RuleUnique    = "unique"
+	RuleFormat    = "format"
+	RuleConflict  = "conflict"
+	RulePolicy    = "policy"
+	RuleReference = "reference"
+	RuleUnused    = "unused"
+	RuleCondition = "condition"
+	// RuleVersionBump reports a version number too low for the changes it
+	// makes, see CheckVersionBump
+	RuleVersionBump = "version_bump"
+	// RuleCanonical reports a taxonomy alias where the canonical name is
+	// required, see Taxonomy.CheckCanonical
+	RuleCanonical = "canonical"
+)
+
+// FieldError describes why the value of a single field is invalid
+type FieldError struct {
+	// Field is the path of the offending value, such as "inputs.db_name"
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// WithDefaults returns a copy of values where every missing input that has a
+// default is set to it
+func WithDefaults(component *Component, values map[string]any) map[string]any {
+	resolved := maps.Clone(values)
+	if resolved == nil {
+		resolved = make(map[string]any)
+	}
+	for _, input := range component.Inputs {
+		if value, ok := resolved[input.Name]; (!ok || value == nil) && input.Default != nil {
+			resolved[input.Name] = input.Default
+		}
+	}
+	return resolved
+}
+
+// ValidateInputs checks values against the inputs of component and returns
+// every failure, nil if there is none. Missing inputs that have a default are
+// set to it in values when values is not nil. Error messages never include
+// the offending value, so they are safe to log for sensitive inputs.
+func ValidateInputs(component *Component, values map[string]any) []FieldError {
+	resolved := WithDefaults(component, values)
+	if values != nil {
+		maps.Copy(values, resolved)
+	}
+
+	var errs []FieldError
+	known := make(map[string]bool, len(component.Inputs))
+	for _, input := range component.Inputs {
+		known[input.Name] = true
+		errs = append(errs, validateInput(input, resolved[input.Name])...)
+		errs = append(errs, validateRequiredIf(input, resolved)...)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(resolved)) {
+		if !known[name] {
+			errs = append(errs, FieldError{
+				Field:   inputPath(name),
+				Rule:    RuleUnknown,
+				Message: fmt.Sprintf("is not an input of %s", component.GetID()),
+			})
+		}
+	}
+
+	return errs
+}
+
+func validateInput(input InputSpec, value any) []FieldError {
+	field := inputPath(input.Name)
+	fail := func(rule, format string, args ...any) []FieldError {
+		return []FieldError{{Field: field, Rule: rule, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	typ, err := ParseType(input.Type)
+	if err != nil {
+		return fail(RuleType, "has unsupported type '%s'", input.Type)
+	}
+
+	if value == nil {
+		if input.Validation.Required {
+			return fail(RuleRequired, "is required")
+		}
+		return nil
+	}
+
+	if errs := typ.Check(field, value); len(errs) > 0 {
+		return errs
+	}
+
+	var errs []FieldError
+	rules := input.Validation
+
+	if length, ok := valueLength(value); ok {
+		if rules.MinLength != nil && length < *rules.MinLength {
+			errs = append(errs, fail(RuleMinLength, "must have at least %d %s", *rules.MinLength, lengthUnit(value))...)
+		}
+		if rules.MaxLength != nil && length > *rules.MaxLength {
+			errs = append(errs, fail(RuleMaxLength, "must have at most %d %s", *rules.MaxLength, lengthUnit(value))...)
+		}
+	}
+
+	if s, ok := value.(string); ok && rules.Pattern != "" {
+		pattern, err := regexp.Compile(rules.Pattern)
+		switch {
+		case err != nil:
+			errs = append(errs, fail(RulePattern, "has invalid pattern '%s' in its specification", rules.Pattern)...)
+		case !pattern.MatchString(s):
+			errs = append(errs, fail(RulePattern, "must match pattern '%s'", rules.Pattern)...)
+		}
+	}
+
+	if len(rules.Enum) > 0 && !slices.Contains(rules.Enum, fmt.Sprint(value)) {
+		errs = append(errs, fail(RuleEnum, "must be one of: %s", strings.Join(rules.Enum, ", "))...)
+	}
+
+	if n, ok := toFloat(value); ok {
+		if rules.Min != nil && n < *rules.Min {
+			errs = append(errs, fail(RuleMin, "must be at least %v", *rules.Min)...)
+		}
+		if rules.Max != nil && n > *rules.Max {
+			errs = append(errs, fail(RuleMax, "must be at most %v", *rules.Max)...)
+		}
+	}
+
+	return errs
+}
+
+// validateRequiredIf reports an input that is missing while its RequiredIf
+// condition holds
+func validateRequiredIf(input InputSpec, values map[string]any) []FieldError {
+	if input.RequiredIf == "" || values[input.Name] != nil {
+		return nil
+	}
+	required, err := EvalCondition(input.RequiredIf, values)
+	if err != nil {
+		return []FieldError{{Field: inputPath(input.Name), Rule: RuleCondition, Message: fmt.Sprintf("has a required_if that cannot be evaluated: %v", err)}}
+	}
+	if required {
+		return []FieldError{{Field: inputPath(input.Name), Rule: RuleRequired, Message: fmt.Sprintf("is required when %s", input.RequiredIf)}}
+	}
+	return nil
+}
+
+func inputPath(name string) string {
+	return "inputs." + name
+}
+
+// toFloat converts any Go number to float64
+func toFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// valueLength returns the number of characters of a string or elements of
+// a list or map
+func valueLength(value any) (int, bool) {
+	if s, ok := value.(string); ok {
+		return utf8.RuneCountInString(s), true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+func lengthUnit(value any) string {
+	if _, ok := value.(string); ok {
+		return "characters"
+	}
+	return "elements"
+}
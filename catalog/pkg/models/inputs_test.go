@@ -0,0 +1,183 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func inputsComponent() *Component {
+	minLength, maxLength := 3, 8
+	minSize, maxSize := 20.0, 100.0
+	maxTags := 2
+
+	return &Component{
+		Name:    "postgres",
+		Version: "1.0.0",
+		Inputs: []InputSpec{
+			{Name: "db_name", Type: "string", Validation: Validation{Required: true, MinLength: &minLength, MaxLength: &maxLength, Pattern: "^[a-z]+$"}},
+			{Name: "instance_class", Type: "string", Default: "db.t3.micro", Validation: Validation{Enum: []string{"db.t3.micro", "db.t3.large"}}},
+			{Name: "storage_gb", Type: "integer", Default: 20, Validation: Validation{Min: &minSize, Max: &maxSize}},
+			{Name: "multi_az", Type: "boolean"},
+			{Name: "tags", Type: "list", Validation: Validation{MaxLength: &maxTags}},
+			{Name: "parameters", Type: "map"},
+			{Name: "password", Type: "string", Sensitive: true},
+		},
+	}
+}
+
+func TestValidateInputs_AppliesDefaults(t *testing.T) {
+	values := map[string]any{"db_name": "orders"}
+
+	errs := ValidateInputs(inputsComponent(), values)
+	require.Empty(t, errs)
+	assert.Equal(t, map[string]any{
+		"db_name":        "orders",
+		"instance_class": "db.t3.micro",
+		"storage_gb":     20,
+	}, values)
+
+	assert.Empty(t, ValidateInputs(inputsComponent(), map[string]any{
+		"db_name":    "orders",
+		"storage_gb": 50.0,
+		"multi_az":   true,
+		"tags":       []string{"a", "b"},
+		"parameters": map[string]any{"max_connections": 100},
+		"password":   "hunter22",
+	}))
+}
+
+func TestValidateInputs_ReportsEveryFailure(t *testing.T) {
+	errs := ValidateInputs(inputsComponent(), map[string]any{
+		"instance_class": "db.m5.huge",
+		"storage_gb":     20.5,
+		"multi_az":       "yes",
+		"tags":           []any{"a", "b", "c"},
+		"parameters":     []any{"max_connections"},
+		"region":         "eu-west-1",
+	})
+
+	assert.Equal(t, []FieldError{
+		{Field: "inputs.db_name", Rule: RuleRequired, Message: "is required"},
+		{Field: "inputs.instance_class", Rule: RuleEnum, Message: "must be one of: db.t3.micro, db.t3.large"},
+		{Field: "inputs.storage_gb", Rule: RuleType, Message: "must be of type integer"},
+		{Field: "inputs.multi_az", Rule: RuleType, Message: "must be of type bool"},
+		{Field: "inputs.tags", Rule: RuleMaxLength, Message: "must have at most 2 elements"},
+		{Field: "inputs.parameters", Rule: RuleType, Message: "must be of type map(any)"},
+		{Field: "inputs.region", Rule: RuleUnknown, Message: "is not an input of postgres:1.0.0"},
+	}, errs)
+}
+
+func TestValidateInputs_Rules(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		value any
+		rules []string
+	}{
+		{"too short", "db_name", "ab", []string{RuleMinLength}},
+		{"too long and bad pattern", "db_name", "Orders_Database", []string{RuleMaxLength, RulePattern}},
+		{"length counts characters", "db_name", "ééé", []string{RulePattern}},
+		{"below min", "storage_gb", 10, []string{RuleMin}},
+		{"above max", "storage_gb", int64(500), []string{RuleMax}},
+		{"number as string", "storage_gb", "50", []string{RuleType}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]any{"db_name": "orders", tt.input: tt.value}
+			var rules []string
+			for _, err := range ValidateInputs(inputsComponent(), values) {
+				assert.Equal(t, "inputs."+tt.input, err.Field)
+				rules = append(rules, err.Rule)
+			}
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
+
+func TestValidateInputs_SpecProblems(t *testing.T) {
+	component := &Component{
+		Name:    "broken",
+		Version: "1.0.0",
+		Inputs: []InputSpec{
+			{Name: "a", Type: "tuple"},
+			{Name: "b", Type: "string", Validation: Validation{Pattern: "("}},
+		},
+	}
+
+	errs := ValidateInputs(component, map[string]any{"a": 1, "b": "x"})
+	require.Len(t, errs, 2)
+	assert.Equal(t, "has unsupported type 'tuple'", errs[0].Message)
+	assert.Equal(t, RulePattern, errs[1].Rule)
+}
+
+func TestValidateInputs_NeverEchoesValues(t *testing.T) {
+	values := map[string]any{"db_name": "s3cr3t-VALUE-that-is-too-long"}
+	for _, err := range ValidateInputs(inputsComponent(), values) {
+		assert.NotContains(t, err.Error(), "s3cr3t")
+	}
+}
+
+func TestWithDefaults_DoesNotModifyValues(t *testing.T) {
+	values := map[string]any{"db_name": "orders"}
+	resolved := WithDefaults(inputsComponent(), values)
+
+	assert.Equal(t, "db.t3.micro", resolved["instance_class"])
+	assert.NotContains(t, values, "instance_class")
+	assert.NotNil(t, WithDefaults(inputsComponent(), nil))
+}
+
+func TestValidateInputs_NestedTypes(t *testing.T) {
+	component := &Component{
+		Name:    "service",
+		Version: "1.0.0",
+		Inputs: []InputSpec{
+			{Name: "ports", Type: "set(number)"},
+			{Name: "listener", Type: "object({port = number, tls = optional(bool)})"},
+			{Name: "env", Type: "map(string)"},
+		},
+	}
+
+	assert.Empty(t, ValidateInputs(component, map[string]any{
+		"ports":    []any{80.0, 443.0},
+		"listener": map[string]any{"port": 443},
+		"env":      map[string]string{"LOG_LEVEL": "debug"},
+	}))
+
+	errs := ValidateInputs(component, map[string]any{
+		"ports":    []any{80.0, "443", 80.0},
+		"listener": map[string]any{"tls": "yes", "host": "example.com"},
+		"env":      map[string]any{"WORKERS": 4},
+	})
+	assert.Equal(t, []FieldError{
+		{Field: "inputs.ports[1]", Rule: RuleType, Message: "must be of type number"},
+		{Field: "inputs.ports[2]", Rule: RuleUnique, Message: "duplicates element 0"},
+		{Field: "inputs.listener.port", Rule: RuleRequired, Message: "is required"},
+		{Field: "inputs.listener.tls", Rule: RuleType, Message: "must be of type bool"},
+		{Field: "inputs.listener.host", Rule: RuleUnknown, Message: "is not an attribute of the object"},
+		{Field: "inputs.env.WORKERS", Rule: RuleType, Message: "must be of type string"},
+	}, errs)
+}
+
+func TestValidateInputs_RequiredIf(t *testing.T) {
+	component := inputsComponent()
+	component.Inputs = append(component.Inputs,
+		InputSpec{Name: "replica_count", Type: "integer", RequiredIf: "multi_az == true"},
+		InputSpec{Name: "kms_key", Type: "string", RequiredIf: "storage_gb > 50 || instance_class in ['db.t3.large']"},
+	)
+
+	assert.Empty(t, ValidateInputs(component, map[string]any{"db_name": "orders"}))
+	assert.Empty(t, ValidateInputs(component, map[string]any{"db_name": "orders", "multi_az": true, "replica_count": 2}))
+
+	assert.Equal(t, []FieldError{
+		{Field: "inputs.replica_count", Rule: RuleRequired, Message: "is required when multi_az == true"},
+		{Field: "inputs.kms_key", Rule: RuleRequired, Message: "is required when storage_gb > 50 || instance_class in ['db.t3.large']"},
+	}, ValidateInputs(component, map[string]any{"db_name": "orders", "multi_az": true, "instance_class": "db.t3.large"}))
+
+	component.Inputs[len(component.Inputs)-1].RequiredIf = "storage_gb >"
+	errs := ValidateInputs(component, map[string]any{"db_name": "orders"})
+	require.Len(t, errs, 1)
+	assert.Equal(t, RuleCondition, errs[0].Rule)
+}
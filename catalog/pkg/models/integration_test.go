@@ -60,8 +60,10 @@ func TestIntegration_ComponentValidationWorkflow(t *testing.T) {
 			Engine:  "terraform",
 			Version: "1.5.0",
 			Config: map[string]any{
-				"provider": "aws",
-				"region":   "us-east-1",
+				"source":         "git::https://example.com/modules/postgres",
+				"module_version": "1.0.0",
+				"provider":       "aws",
+				"region":         "us-east-1",
 			},
 		},
 		Metadata: ComponentMetadata{
@@ -140,7 +142,7 @@ func TestIntegration_ValidationErrors(t *testing.T) {
 				Outputs: []OutputSpec{
 					{Name: "test", Type: "string", Description: "test"},
 				},
-				Deployment: DeploymentSpec{Engine: "terraform", Version: "1.0.0"},
+				Deployment: DeploymentSpec{Engine: "terraform", Version: "1.0.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
 			},
 			expectErr: "validation failed",
 		},
@@ -157,7 +159,7 @@ func TestIntegration_ValidationErrors(t *testing.T) {
 				Outputs: []OutputSpec{
 					{Name: "test", Type: "string", Description: "test"},
 				},
-				Deployment: DeploymentSpec{Engine: "terraform", Version: "1.0.0"},
+				Deployment: DeploymentSpec{Engine: "terraform", Version: "1.0.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
 			},
 			expectErr: "validation failed",
 		},
@@ -173,7 +175,7 @@ func TestIntegration_ValidationErrors(t *testing.T) {
 				Outputs: []OutputSpec{
 					{Name: "test", Type: "string", Description: "test"},
 				},
-				Deployment: DeploymentSpec{Engine: "terraform", Version: "1.0.0"},
+				Deployment: DeploymentSpec{Engine: "terraform", Version: "1.0.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
 			},
 			expectErr: "validation failed",
 		},
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// JSONSchemaDialect is the JSON Schema version of the generated documents
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns the JSON Schema of the values of the type
+func (t *Type) JSONSchema() map[string]any {
+	switch t.Kind {
+	case KindString:
+		return map[string]any{"type": "string"}
+	case KindNumber:
+		return map[string]any{"type": "number"}
+	case KindInteger:
+		return map[string]any{"type": "integer"}
+	case KindBool:
+		return map[string]any{"type": "boolean"}
+	case KindList:
+		return map[string]any{"type": "array", "items": t.Elem.JSONSchema()}
+	case KindSet:
+		return map[string]any{"type": "array", "items": t.Elem.JSONSchema(), "uniqueItems": true}
+	case KindMap:
+		return map[string]any{"type": "object", "additionalProperties": t.Elem.JSONSchema()}
+	case KindObject:
+		properties := make(map[string]any, len(t.Fields))
+		required := []string{}
+		for _, field := range t.Fields {
+			properties[field.Name] = field.Type.JSONSchema()
+			if !field.Type.Optional {
+				required = append(required, field.Name)
+			}
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]any{}
+	}
+}
+
+// InputsSchema returns a JSON Schema document validating the input values of
+// the component, as accepted by ValidateInputs. Sensitive inputs are marked
+// writeOnly and x-sensitive, and their default is left out so the schema
+// does not disclose it.
+func InputsSchema(component *Component) (map[string]any, error) {
+	properties := make(map[string]any, len(component.Inputs))
+	required := []string{}
+
+	for _, input := range component.Inputs {
+		typ, err := ParseType(input.Type)
+		if err != nil {
+			return nil, fmt.Errorf("input '%s' has invalid type: %w", input.Name, err)
+		}
+
+		schema := typ.JSONSchema()
+		if input.Description != "" {
+			schema["description"] = input.Description
+		}
+		if input.Default != nil && !input.Sensitive {
+			schema["default"] = input.Default
+		}
+		if input.Sensitive {
+			schema["writeOnly"] = true
+			schema["x-sensitive"] = true
+		}
+		applyValidation(schema, typ, input.Validation)
+
+		properties[input.Name] = schema
+		if input.Validation.Required {
+			required = append(required, input.Name)
+		}
+	}
+
+	return objectSchema(component, "inputs", properties, required), nil
+}
+
+// OutputsSchema returns a JSON Schema document describing the outputs of the
+// component. Every output is required.
+func OutputsSchema(component *Component) (map[string]any, error) {
+	properties := make(map[string]any, len(component.Outputs))
+	required := []string{}
+
+	for _, output := range component.Outputs {
+		typ, err := ParseType(output.Type)
+		if err != nil {
+			return nil, fmt.Errorf("output '%s' has invalid type: %w", output.Name, err)
+		}
+
+		schema := typ.JSONSchema()
+		if output.Description != "" {
+			schema["description"] = output.Description
+		}
+		if output.Sensitive {
+			schema["x-sensitive"] = true
+		}
+
+		properties[output.Name] = schema
+		required = append(required, output.Name)
+	}
+
+	return objectSchema(component, "outputs", properties, required), nil
+}
+
+func objectSchema(component *Component, part string, properties map[string]any, required []string) map[string]any {
+	schema := map[string]any{
+		"$schema":              JSONSchemaDialect,
+		"title":                fmt.Sprintf("%s %s %s", component.Name, component.Version, part),
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	if component.Description != "" {
+		schema["description"] = component.Description
+	}
+	return schema
+}
+
+// applyValidation adds the keywords expressing rules to schema. Length rules
+// apply to characters, items or properties depending on the type.
+func applyValidation(schema map[string]any, typ *Type, rules Validation) {
+	minKey, maxKey := "minLength", "maxLength"
+	switch typ.Kind {
+	case KindList, KindSet:
+		minKey, maxKey = "minItems", "maxItems"
+	case KindMap, KindObject:
+		minKey, maxKey = "minProperties", "maxProperties"
+	}
+	if rules.MinLength != nil {
+		schema[minKey] = *rules.MinLength
+	}
+	if rules.MaxLength != nil {
+		schema[maxKey] = *rules.MaxLength
+	}
+
+	if rules.Pattern != "" {
+		schema["pattern"] = rules.Pattern
+	}
+	if rules.Min != nil {
+		schema["minimum"] = *rules.Min
+	}
+	if rules.Max != nil {
+		schema["maximum"] = *rules.Max
+	}
+	if len(rules.Enum) > 0 {
+		schema["enum"] = enumValues(typ.Kind, rules.Enum)
+	}
+}
+
+// enumValues converts enum entries, always written as strings in specs, to
+// the JSON type of the input
+func enumValues(kind TypeKind, enum []string) []any {
+	values := make([]any, len(enum))
+	for i, entry := range enum {
+		values[i] = entry
+		switch kind {
+		case KindNumber, KindInteger:
+			if n, err := strconv.ParseFloat(entry, 64); err == nil {
+				values[i] = n
+			}
+		case KindBool:
+			if b, err := strconv.ParseBool(entry); err == nil {
+				values[i] = b
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/shared/pkg/json"
+)
+
+func TestType_JSONSchema(t *testing.T) {
+	schema := MustParseType("object({name = string, ports = set(integer), labels = optional(map(string)), extra = any})").JSONSchema()
+
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":   map[string]any{"type": "string"},
+			"ports":  map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "uniqueItems": true},
+			"labels": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"extra":  map[string]any{},
+		},
+		"required":             []string{"name", "ports", "extra"},
+		"additionalProperties": false,
+	}, schema)
+}
+
+func TestInputsSchema(t *testing.T) {
+	component := inputsComponent()
+	component.Description = "Managed PostgreSQL"
+	component.Inputs[3].Validation.Enum = []string{"true"}
+	component.Inputs[6].Default = "hunter2"
+
+	schema, err := InputsSchema(component)
+	require.NoError(t, err)
+
+	assert.Equal(t, JSONSchemaDialect, schema["$schema"])
+	assert.Equal(t, "postgres 1.0.0 inputs", schema["title"])
+	assert.Equal(t, "Managed PostgreSQL", schema["description"])
+	assert.Equal(t, []string{"db_name"}, schema["required"])
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type":      "string",
+		"minLength": 3,
+		"maxLength": 8,
+		"pattern":   "^[a-z]+$",
+	}, properties["db_name"])
+	assert.Equal(t, map[string]any{
+		"type":    "integer",
+		"default": 20,
+		"minimum": 20.0,
+		"maximum": 100.0,
+	}, properties["storage_gb"])
+	assert.Equal(t, []any{true}, properties["multi_az"].(map[string]any)["enum"])
+	assert.Equal(t, 2, properties["tags"].(map[string]any)["maxItems"])
+	assert.Equal(t, true, properties["password"].(map[string]any)["writeOnly"])
+	assert.Equal(t, true, properties["password"].(map[string]any)["x-sensitive"])
+	assert.NotContains(t, properties["password"], "default", "defaults of sensitive inputs are not disclosed")
+
+	_, err = json.ToJSON(schema)
+	require.NoError(t, err)
+}
+
+func TestOutputsSchema(t *testing.T) {
+	component := validRelationsComponent()
+	component.Outputs = append(component.Outputs, OutputSpec{Name: "password", Type: "string", Description: "Master password", Sensitive: true})
+
+	schema, err := OutputsSchema(component)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"endpoint", "password"}, schema["required"])
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "description": "Database endpoint"}, properties["endpoint"])
+	assert.Equal(t, true, properties["password"].(map[string]any)["x-sensitive"])
+
+	component.Outputs[0].Type = "blob"
+	_, err = OutputsSchema(component)
+	assert.ErrorContains(t, err, "output 'endpoint' has invalid type")
+}
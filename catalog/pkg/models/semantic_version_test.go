@@ -271,6 +271,27 @@ func TestSemanticVersionInfo_Compare(t *testing.T) {
 	}
 }
 
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected int
+	}{
+		{name: "equal versions", a: "1.2.3", b: "1.2.3", expected: 0},
+		{name: "semantic order", a: "1.10.0", b: "1.9.0", expected: 1},
+		{name: "pre-release first", a: "2.0.0-rc.1", b: "2.0.0", expected: -1},
+		{name: "invalid before valid", a: "latest", b: "0.0.1", expected: -1},
+		{name: "valid after invalid", a: "0.0.1", b: "latest", expected: 1},
+		{name: "invalid as strings", a: "beta", b: "alpha", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CompareVersions(tt.a, tt.b))
+		})
+	}
+}
+
 func TestSemanticVersionInfo_IsPreRelease(t *testing.T) {
 	tests := []struct {
 		name     string
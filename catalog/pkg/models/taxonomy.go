@@ -0,0 +1,311 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Taxonomy defines the providers, categories and subcategories components may
+// use. An empty list leaves the matching field unmanaged, so that a catalog
+// without a taxonomy accepts any value.
+type Taxonomy struct {
+	Providers  []TaxonomyTerm     `json:"providers"`
+	Categories []TaxonomyCategory `json:"categories"`
+	// Revision is incremented on every change and must match the stored
+	// revision when updating
+	Revision  int64     `json:"revision"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaxonomyTerm is an allowed value. Aliases are alternative spellings that
+// normalize to Name; matching ignores case.
+type TaxonomyTerm struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Description string   `json:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+// TaxonomyCategory is a category and the subcategories allowed within it
+type TaxonomyCategory struct {
+	TaxonomyTerm
+	SubCategories []TaxonomyTerm `json:"sub_categories,omitempty"`
+	// RequiredApprovals is the number of maintainers other than the publisher
+	// who must approve a draft in the category before it can be promoted.
+	// Sensitive categories such as networking or IAM set it; zero means none
+	RequiredApprovals int `json:"required_approvals,omitempty"`
+	// Approvers may approve the first version of components in the category.
+	// Later versions are approved by the maintainers of the version they
+	// succeed
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+// Matches reports whether value is the name or an alias of the term
+func (t *TaxonomyTerm) Matches(value string) bool {
+	return strings.EqualFold(t.Name, value) || slices.ContainsFunc(t.Aliases, func(alias string) bool {
+		return strings.EqualFold(alias, value)
+	})
+}
+
+// Provider returns the provider matching value
+func (t *Taxonomy) Provider(value string) (*TaxonomyTerm, bool) {
+	return findTerm(t.Providers, value)
+}
+
+// Category returns the category matching value
+func (t *Taxonomy) Category(value string) (*TaxonomyCategory, bool) {
+	for i := range t.Categories {
+		if t.Categories[i].Matches(value) {
+			return &t.Categories[i], true
+		}
+	}
+	return nil, false
+}
+
+// RequiredApprovals returns the number of approvals drafts in category need
+// before they are promoted
+func (t *Taxonomy) RequiredApprovals(category string) int {
+	if parent, ok := t.Category(category); ok {
+		return parent.RequiredApprovals
+	}
+	return 0
+}
+
+// Approvers returns the users who may approve the first version of
+// components in category
+func (t *Taxonomy) Approvers(category string) []string {
+	if parent, ok := t.Category(category); ok {
+		return parent.Approvers
+	}
+	return nil
+}
+
+// ResolveProvider returns the canonical name of a provider. Values are
+// returned unchanged when providers are unmanaged; ok is false when value is
+// not allowed.
+func (t *Taxonomy) ResolveProvider(value string) (string, bool) {
+	if len(t.Providers) == 0 {
+		return value, true
+	}
+	if term, ok := t.Provider(value); ok {
+		return term.Name, true
+	}
+	return value, false
+}
+
+// ResolveCategory returns the canonical name of a category, like
+// ResolveProvider
+func (t *Taxonomy) ResolveCategory(value string) (string, bool) {
+	if len(t.Categories) == 0 {
+		return value, true
+	}
+	if category, ok := t.Category(value); ok {
+		return category.Name, true
+	}
+	return value, false
+}
+
+// ResolveSubCategory returns the canonical name of a subcategory of category.
+// Subcategories are unmanaged for categories that declare none.
+func (t *Taxonomy) ResolveSubCategory(category, value string) (string, bool) {
+	parent, ok := t.Category(category)
+	if !ok || len(parent.SubCategories) == 0 {
+		return value, true
+	}
+	if term, ok := findTerm(parent.SubCategories, value); ok {
+		return term.Name, true
+	}
+	return value, false
+}
+
+// Normalize rewrites the provider, category and subcategory of component to
+// their canonical names and reports the values the taxonomy does not allow
+func (t *Taxonomy) Normalize(component *Component) ValidationErrors {
+	var issues ValidationErrors
+
+	if provider, ok := t.ResolveProvider(component.Provider); ok {
+		component.Provider = provider
+	} else if component.Provider != "" {
+		issues.errorf("provider", RuleEnum, "provider '%s' is not in the taxonomy, expected one of: %s",
+			component.Provider, termNames(t.Providers))
+	}
+
+	category, ok := t.ResolveCategory(component.Category)
+	if ok {
+		component.Category = category
+	} else if component.Category != "" {
+		names := make([]TaxonomyTerm, len(t.Categories))
+		for i, category := range t.Categories {
+			names[i] = category.TaxonomyTerm
+		}
+		issues.errorf("category", RuleEnum, "category '%s' is not in the taxonomy, expected one of: %s",
+			component.Category, termNames(names))
+	}
+
+	if component.SubCategory == "" || !ok {
+		return issues
+	}
+	if subCategory, ok := t.ResolveSubCategory(component.Category, component.SubCategory); ok {
+		component.SubCategory = subCategory
+	} else {
+		parent, _ := t.Category(component.Category)
+		issues.errorf("sub_category", RuleEnum, "sub_category '%s' is not in category '%s', expected one of: %s",
+			component.SubCategory, component.Category, termNames(parent.SubCategories))
+	}
+
+	return issues
+}
+
+// CheckCanonical reports the provider, category and subcategory of component
+// that Normalize would rewrite. Signed components must use canonical names,
+// as normalizing them would change the definition their signatures cover
+func (t *Taxonomy) CheckCanonical(component *Component) ValidationErrors {
+	normalized := *component
+	t.Normalize(&normalized)
+
+	var issues ValidationErrors
+	for _, field := range []struct{ path, value, canonical string }{
+		{"provider", component.Provider, normalized.Provider},
+		{"category", component.Category, normalized.Category},
+		{"sub_category", component.SubCategory, normalized.SubCategory},
+	} {
+		if field.value != field.canonical {
+			issues.errorf(field.path, RuleCanonical, "%s '%s' must be given by its canonical name '%s' in a signed component",
+				field.path, field.value, field.canonical)
+		}
+	}
+	return issues
+}
+
+// SetProvider adds a provider or replaces the one with the same name
+func (t *Taxonomy) SetProvider(term TaxonomyTerm) {
+	t.Providers = setTerm(t.Providers, term, func(p TaxonomyTerm) string { return p.Name })
+}
+
+// RemoveProvider removes the provider called name and reports whether it
+// existed
+func (t *Taxonomy) RemoveProvider(name string) bool {
+	n := len(t.Providers)
+	t.Providers = slices.DeleteFunc(t.Providers, func(p TaxonomyTerm) bool { return p.Name == name })
+	return len(t.Providers) < n
+}
+
+// SetCategory adds a category or replaces the one with the same name
+func (t *Taxonomy) SetCategory(category TaxonomyCategory) {
+	t.Categories = setTerm(t.Categories, category, func(c TaxonomyCategory) string { return c.Name })
+}
+
+// RemoveCategory removes the category called name and reports whether it
+// existed
+func (t *Taxonomy) RemoveCategory(name string) bool {
+	n := len(t.Categories)
+	t.Categories = slices.DeleteFunc(t.Categories, func(c TaxonomyCategory) bool { return c.Name == name })
+	return len(t.Categories) < n
+}
+
+// Validate checks that names are DNS-1123 labels, that no name or alias is
+// claimed twice within the same list and that approval counts are not
+// negative
+func (t *Taxonomy) Validate() error {
+	var issues ValidationErrors
+	validateTerms(&issues, "providers", t.Providers)
+
+	categories := make([]TaxonomyTerm, len(t.Categories))
+	for i, category := range t.Categories {
+		categories[i] = category.TaxonomyTerm
+		validateTerms(&issues, fmt.Sprintf("categories[%d].sub_categories", i), category.SubCategories)
+		if category.RequiredApprovals < 0 {
+			issues.errorf(fmt.Sprintf("categories[%d].required_approvals", i), RuleMin,
+				"'%s' cannot require %d approvals", category.Name, category.RequiredApprovals)
+		}
+	}
+	validateTerms(&issues, "categories", categories)
+
+	if issues.HasErrors() {
+		return issues
+	}
+	return nil
+}
+
+func validateTerms(issues *ValidationErrors, path string, terms []TaxonomyTerm) {
+	claimed := make(map[string]string)
+	claim := func(termPath, value, owner string) {
+		key := strings.ToLower(value)
+		if other, ok := claimed[key]; ok {
+			issues.errorf(termPath, RuleUnique, "'%s' is already used by '%s'", value, other)
+			return
+		}
+		claimed[key] = owner
+	}
+
+	for i, term := range terms {
+		termPath := fmt.Sprintf("%s[%d]", path, i)
+		if !isDNS1123(term.Name) {
+			issues.errorf(termPath+".name", RuleFormat, "'%s' must be a DNS-1123 name", term.Name)
+		}
+		if term.DisplayName == "" {
+			issues.errorf(termPath+".display_name", RuleRequired, "'%s' is missing display_name", term.Name)
+		}
+		claim(termPath+".name", term.Name, term.Name)
+		for j, alias := range term.Aliases {
+			aliasPath := fmt.Sprintf("%s.aliases[%d]", termPath, j)
+			if alias == "" {
+				issues.errorf(aliasPath, RuleRequired, "'%s' has an empty alias", term.Name)
+				continue
+			}
+			claim(aliasPath, alias, term.Name)
+		}
+	}
+}
+
+func findTerm(terms []TaxonomyTerm, value string) (*TaxonomyTerm, bool) {
+	for i := range terms {
+		if terms[i].Matches(value) {
+			return &terms[i], true
+		}
+	}
+	return nil, false
+}
+
+func setTerm[T any](terms []T, term T, name func(T) string) []T {
+	if i := slices.IndexFunc(terms, func(t T) bool { return name(t) == name(term) }); i >= 0 {
+		terms[i] = term
+		return terms
+	}
+	return append(terms, term)
+}
+
+func termNames(terms []TaxonomyTerm) string {
+	names := make([]string, len(terms))
+	for i, term := range terms {
+		names[i] = term.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Clone returns a deep copy of the taxonomy
+func (t *Taxonomy) Clone() *Taxonomy {
+	clone := *t
+	clone.Providers = cloneTerms(t.Providers)
+	clone.Categories = make([]TaxonomyCategory, len(t.Categories))
+	for i, category := range t.Categories {
+		clone.Categories[i] = TaxonomyCategory{
+			TaxonomyTerm:      cloneTerms([]TaxonomyTerm{category.TaxonomyTerm})[0],
+			SubCategories:     cloneTerms(category.SubCategories),
+			RequiredApprovals: category.RequiredApprovals,
+			Approvers:         slices.Clone(category.Approvers),
+		}
+	}
+	return &clone
+}
+
+func cloneTerms(terms []TaxonomyTerm) []TaxonomyTerm {
+	clones := make([]TaxonomyTerm, len(terms))
+	for i, term := range terms {
+		clones[i] = term
+		clones[i].Aliases = slices.Clone(term.Aliases)
+	}
+	return clones
+}
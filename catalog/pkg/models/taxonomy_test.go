@@ -0,0 +1,155 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTaxonomy() *Taxonomy {
+	return &Taxonomy{
+		Providers: []TaxonomyTerm{
+			{Name: "aws", DisplayName: "Amazon Web Services", Aliases: []string{"amazon"}},
+			{Name: "gcp", DisplayName: "Google Cloud", Aliases: []string{"google"}},
+		},
+		Categories: []TaxonomyCategory{
+			{
+				TaxonomyTerm:  TaxonomyTerm{Name: "database", DisplayName: "Databases", Aliases: []string{"db"}},
+				SubCategories: []TaxonomyTerm{{Name: "relational", DisplayName: "Relational", Aliases: []string{"sql"}}},
+			},
+			{TaxonomyTerm: TaxonomyTerm{Name: "networking", DisplayName: "Networking", Aliases: []string{"net"}}, RequiredApprovals: 2},
+		},
+	}
+}
+
+func TestTaxonomy_Resolve(t *testing.T) {
+	taxonomy := testTaxonomy()
+
+	tests := []struct {
+		name    string
+		resolve func(string) (string, bool)
+		value   string
+		want    string
+		ok      bool
+	}{
+		{"provider name", taxonomy.ResolveProvider, "aws", "aws", true},
+		{"provider case", taxonomy.ResolveProvider, "AWS", "aws", true},
+		{"provider alias", taxonomy.ResolveProvider, "Amazon", "aws", true},
+		{"unknown provider", taxonomy.ResolveProvider, "azure", "azure", false},
+		{"category alias", taxonomy.ResolveCategory, "db", "database", true},
+		{"subcategory alias", func(v string) (string, bool) { return taxonomy.ResolveSubCategory("DB", v) }, "SQL", "relational", true},
+		{"unmanaged subcategory", func(v string) (string, bool) { return taxonomy.ResolveSubCategory("networking", v) }, "vpn", "vpn", true},
+		{"unmanaged providers", (&Taxonomy{}).ResolveProvider, "anything", "anything", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.resolve(tt.value)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestTaxonomy_Normalize(t *testing.T) {
+	taxonomy := testTaxonomy()
+
+	component := &Component{Provider: "Amazon", Category: "db", SubCategory: "sql"}
+	assert.Empty(t, taxonomy.Normalize(component))
+	assert.Equal(t, "aws", component.Provider)
+	assert.Equal(t, "database", component.Category)
+	assert.Equal(t, "relational", component.SubCategory)
+
+	component = &Component{Provider: "azure", Category: "database", SubCategory: "graph"}
+	issues := taxonomy.Normalize(component)
+	require.Len(t, issues, 2)
+	assert.Equal(t, ValidationIssue{
+		Path:     "provider",
+		Rule:     RuleEnum,
+		Message:  "provider 'azure' is not in the taxonomy, expected one of: aws, gcp",
+		Severity: SeverityError,
+	}, issues[0])
+	assert.Equal(t, "sub_category", issues[1].Path)
+	assert.Equal(t, "sub_category 'graph' is not in category 'database', expected one of: relational", issues[1].Message)
+}
+
+func TestTaxonomy_CheckCanonical(t *testing.T) {
+	taxonomy := testTaxonomy()
+
+	assert.Empty(t, taxonomy.CheckCanonical(&Component{Provider: "aws", Category: "database", SubCategory: "relational"}))
+	assert.Empty(t, taxonomy.CheckCanonical(&Component{Provider: "azure"}), "values outside the taxonomy are left to Normalize")
+
+	component := &Component{Provider: "AWS", Category: "db", SubCategory: "relational"}
+	issues := taxonomy.CheckCanonical(component)
+	require.Len(t, issues, 2)
+	assert.Equal(t, ValidationIssue{
+		Path:     "provider",
+		Rule:     RuleCanonical,
+		Message:  "provider 'AWS' must be given by its canonical name 'aws' in a signed component",
+		Severity: SeverityError,
+	}, issues[0])
+	assert.Equal(t, "category", issues[1].Path)
+	assert.Equal(t, "AWS", component.Provider, "the component is left unchanged")
+}
+
+func TestTaxonomy_Validate(t *testing.T) {
+	require.NoError(t, testTaxonomy().Validate())
+
+	taxonomy := testTaxonomy()
+	taxonomy.Providers = append(taxonomy.Providers,
+		TaxonomyTerm{Name: "AWS", DisplayName: "Upper"},
+		TaxonomyTerm{Name: "azure", DisplayName: "Azure", Aliases: []string{"Google"}},
+		TaxonomyTerm{Name: "oracle"},
+	)
+	taxonomy.Categories[0].SubCategories = append(taxonomy.Categories[0].SubCategories,
+		TaxonomyTerm{Name: "nosql", DisplayName: "NoSQL", Aliases: []string{"relational"}})
+
+	err := taxonomy.Validate()
+	var issues ValidationErrors
+	require.ErrorAs(t, err, &issues)
+
+	var paths []string
+	for _, issue := range issues {
+		paths = append(paths, issue.Path)
+	}
+	assert.Equal(t, []string{
+		"providers[2].name",
+		"providers[2].name",
+		"providers[3].aliases[0]",
+		"providers[4].display_name",
+		"categories[0].sub_categories[1].aliases[0]",
+	}, paths)
+}
+
+func TestTaxonomy_RequiredApprovals(t *testing.T) {
+	taxonomy := testTaxonomy()
+	assert.Equal(t, 2, taxonomy.RequiredApprovals("networking"))
+	assert.Equal(t, 2, taxonomy.RequiredApprovals("NET"))
+	assert.Equal(t, 0, taxonomy.RequiredApprovals("database"))
+	assert.Equal(t, 0, taxonomy.RequiredApprovals("compute"))
+	assert.Equal(t, 2, taxonomy.Clone().RequiredApprovals("networking"))
+
+	taxonomy.Categories[1].RequiredApprovals = -1
+	var issues ValidationErrors
+	require.ErrorAs(t, taxonomy.Validate(), &issues)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "categories[1].required_approvals", issues[0].Path)
+	assert.Equal(t, RuleMin, issues[0].Rule)
+}
+
+func TestTaxonomy_SetAndRemove(t *testing.T) {
+	taxonomy := testTaxonomy()
+	clone := taxonomy.Clone()
+
+	taxonomy.SetProvider(TaxonomyTerm{Name: "aws", DisplayName: "AWS"})
+	taxonomy.SetProvider(TaxonomyTerm{Name: "azure", DisplayName: "Azure"})
+	assert.Equal(t, "AWS", taxonomy.Providers[0].DisplayName)
+	assert.Len(t, taxonomy.Providers, 3)
+	assert.Equal(t, "Amazon Web Services", clone.Providers[0].DisplayName)
+
+	assert.True(t, taxonomy.RemoveCategory("networking"))
+	assert.False(t, taxonomy.RemoveCategory("networking"))
+	assert.True(t, taxonomy.RemoveProvider("gcp"))
+	assert.Len(t, clone.Categories, 2)
+}
@@ -0,0 +1,370 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateRoot is the value deployment config templates are rendered with,
+// so "{{ .inputs.size }}" refers to the input named size
+const templateRoot = "inputs"
+
+// InputReference is a use of an input inside a deployment config template
+type InputReference struct {
+	// Path is the path of the config value holding the template
+	Path  string `json:"path"`
+	Input string `json:"input"`
+}
+
+// ConfigTemplates lists the input references of a deployment config. Config
+// values that are not valid templates, or that reference anything but
+// inputs, are reported as errors. all is true when a template uses the
+// inputs as a whole, such as `{{ printf "%v" .inputs }}`, so that every
+// input may be used.
+func ConfigTemplates(path string, config map[string]any) (refs []InputReference, all bool, errs []FieldError) {
+	walkConfig(path, config, func(path, text string) {
+		tree, err := parseTemplate(path, text)
+		if err != nil {
+			errs = append(errs, FieldError{Field: path, Rule: RuleFormat, Message: fmt.Sprintf("is not a valid template: %v", err)})
+			return
+		}
+		collector := &referenceCollector{path: path}
+		collector.list(tree.Root, true)
+		refs = append(refs, collector.refs...)
+		all = all || collector.all
+		errs = append(errs, collector.errs...)
+	})
+	return refs, all, errs
+}
+
+// RenderConfig renders the templates of a deployment config with values,
+// returning a copy of the config. A value that is a single input reference,
+// such as "{{ .inputs.size }}", is replaced by the input value itself so its
+// type is kept. Values are not checked against the inputs; see Preview.
+func RenderConfig(config map[string]any, values map[string]any) (map[string]any, error) {
+	text := make(map[string]any, len(values))
+	for name, value := range values {
+		if value == nil {
+			value = ""
+		}
+		text[name] = value
+	}
+
+	var renderErr error
+	rendered, _ := mapConfig("config", config, func(path, raw string) any {
+		if renderErr != nil {
+			return raw
+		}
+		tree, err := parseTemplate(path, raw)
+		if err != nil {
+			renderErr = fmt.Errorf("%s: %w", path, err)
+			return raw
+		}
+		if name, ok := singleReference(tree); ok {
+			value, ok := values[name]
+			if !ok {
+				renderErr = fmt.Errorf("%s: input '%s' has no value", path, name)
+			}
+			return value
+		}
+
+		tmpl, err := template.New(path).Option("missingkey=error").AddParseTree(path, tree)
+		if err != nil {
+			renderErr = fmt.Errorf("%s: %w", path, err)
+			return raw
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, map[string]any{templateRoot: text}); err != nil {
+			renderErr = fmt.Errorf("%s: %w", path, err)
+			return raw
+		}
+		return out.String()
+	}).(map[string]any)
+	if renderErr != nil {
+		return nil, renderErr
+	}
+	return rendered, nil
+}
+
+// Preview renders the deployment config of engine for values, after setting
+// defaults and checking values against the inputs. Invalid values are
+// reported as ValidationErrors.
+func (c *Component) Preview(engine string, values map[string]any) (map[string]any, error) {
+	spec, ok := c.DeploymentFor(engine)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not support %s", ErrUnsupportedEngine, c.GetID(), engine)
+	}
+
+	resolved := WithDefaults(c, values)
+	if errs := ValidateInputs(c, resolved); len(errs) > 0 {
+		var issues ValidationErrors
+		for _, err := range errs {
+			issues.errorf(err.Field, err.Rule, "%s", err)
+		}
+		return nil, issues
+	}
+
+	full := make(map[string]any, len(c.Inputs))
+	for _, input := range c.Inputs {
+		full[input.Name] = resolved[input.Name]
+	}
+	return RenderConfig(spec.Config, full)
+}
+
+// validateTemplates checks that deployment configs only reference declared
+// inputs and map every declared output
+func (cv *ComponentValidator) validateTemplates(component *Component, issues *ValidationErrors) {
+	declared := make(map[string]bool, len(component.Inputs))
+	for _, input := range component.Inputs {
+		declared[input.Name] = true
+	}
+
+	for i, spec := range component.DeploymentSpecs() {
+		path := "deployment"
+		if i > 0 {
+			path = fmt.Sprintf("additional_deployments[%d]", i-1)
+		}
+
+		refs, all, errs := ConfigTemplates(path+".config", spec.Config)
+		for _, err := range errs {
+			issues.errorf(err.Field, err.Rule, "deployment for engine '%s' has invalid config: %s", spec.Engine, err)
+		}
+
+		used := make(map[string]bool, len(refs))
+		for _, ref := range refs {
+			if !declared[ref.Input] {
+				issues.errorf(ref.Path, RuleReference, "deployment for engine '%s' references undeclared input '%s'", spec.Engine, ref.Input)
+			}
+			used[ref.Input] = true
+		}
+		if len(refs) > 0 && !all {
+			for j, input := range component.Inputs {
+				if !used[input.Name] {
+					issues.warnf(fmt.Sprintf("inputs[%d]", j), RuleUnused, "input '%s' is not used by the config of engine '%s'", input.Name, spec.Engine)
+				}
+			}
+		}
+
+		cv.validateOutputMapping(component, spec, path, issues)
+	}
+}
+
+// validateOutputMapping checks the outputs a deployment maps to what its
+// engine produces. Engines without a mapping are assumed to produce every
+// output under its own name.
+func (cv *ComponentValidator) validateOutputMapping(component *Component, spec DeploymentSpec, path string, issues *ValidationErrors) {
+	schema, ok := cv.engines.Lookup(spec.Engine)
+	if !ok || schema.Outputs == "" {
+		return
+	}
+	mapping, ok := spec.Config[schema.Outputs].(map[string]any)
+	if !ok {
+		return
+	}
+
+	path = fmt.Sprintf("%s.config.%s", path, schema.Outputs)
+	declared := make(map[string]bool, len(component.Outputs))
+	for _, output := range component.Outputs {
+		declared[output.Name] = true
+		if source, _ := mapping[output.Name].(string); source == "" {
+			issues.errorf(path, RuleReference, "deployment for engine '%s' does not map output '%s'", spec.Engine, output.Name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(mapping)) {
+		if !declared[name] {
+			issues.errorf(path+"."+name, RuleUnknown, "deployment for engine '%s' maps undeclared output '%s'", spec.Engine, name)
+		}
+	}
+}
+
+// parseTemplate parses a config value, using the default delimiters
+func parseTemplate(name, text string) (*parse.Tree, error) {
+	trees, err := parse.Parse(name, text, "", "", builtinFuncs)
+	if err != nil {
+		return nil, err
+	}
+	return trees[name], nil
+}
+
+// builtinFuncs declares the functions text/template provides so parsing
+// accepts them
+var builtinFuncs = map[string]any{
+	"and": true, "call": true, "html": true, "index": true, "slice": true, "js": true, "len": true,
+	"not": true, "or": true, "print": true, "printf": true, "println": true, "urlquery": true,
+	"eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+}
+
+// singleReference returns the input a template consists of, when it is a
+// single "{{ .inputs.name }}" action
+func singleReference(tree *parse.Tree) (string, bool) {
+	var action *parse.ActionNode
+	for _, node := range tree.Root.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			if strings.TrimSpace(string(n.Text)) != "" {
+				return "", false
+			}
+		case *parse.ActionNode:
+			if action != nil {
+				return "", false
+			}
+			action = n
+		default:
+			return "", false
+		}
+	}
+	if action == nil || len(action.Pipe.Decl) > 0 || len(action.Pipe.Cmds) != 1 || len(action.Pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+	field, ok := action.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !ok || len(field.Ident) != 2 || field.Ident[0] != templateRoot {
+		return "", false
+	}
+	return field.Ident[1], true
+}
+
+// referenceCollector gathers the input references of a template
+type referenceCollector struct {
+	path string
+	refs []InputReference
+	all  bool
+	errs []FieldError
+}
+
+// list walks a list of nodes. root is false inside range and with, where dot
+// no longer refers to the template root.
+func (c *referenceCollector) list(list *parse.ListNode, root bool) {
+	if list == nil {
+		return
+	}
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			c.pipe(n.Pipe, root)
+		case *parse.IfNode:
+			c.branch(&n.BranchNode, root, root)
+		case *parse.RangeNode:
+			c.branch(&n.BranchNode, root, false)
+		case *parse.WithNode:
+			c.branch(&n.BranchNode, root, false)
+		case *parse.TemplateNode:
+			c.pipe(n.Pipe, root)
+		}
+	}
+}
+
+func (c *referenceCollector) branch(node *parse.BranchNode, root, inner bool) {
+	c.pipe(node.Pipe, root)
+	c.list(node.List, inner)
+	c.list(node.ElseList, root)
+}
+
+func (c *referenceCollector) pipe(pipe *parse.PipeNode, root bool) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		if name, ok := indexedInput(cmd); ok {
+			c.refs = append(c.refs, InputReference{Path: c.path, Input: name})
+			continue
+		}
+		for _, arg := range cmd.Args {
+			c.arg(arg, root)
+		}
+	}
+}
+
+func (c *referenceCollector) arg(node parse.Node, root bool) {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if root {
+			c.field(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			c.field(n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		c.arg(n.Node, root)
+	case *parse.PipeNode:
+		c.pipe(n, root)
+	}
+}
+
+// field records a field chain starting at the template root
+func (c *referenceCollector) field(ident []string) {
+	if ident[0] != templateRoot {
+		c.errs = append(c.errs, FieldError{
+			Field:   c.path,
+			Rule:    RuleReference,
+			Message: fmt.Sprintf("references unknown value '.%s', only .%s is available", ident[0], templateRoot),
+		})
+		return
+	}
+	if len(ident) == 1 {
+		c.all = true
+		return
+	}
+	c.refs = append(c.refs, InputReference{Path: c.path, Input: ident[1]})
+}
+
+// indexedInput returns the input of an `index .inputs "name"` command
+func indexedInput(cmd *parse.CommandNode) (string, bool) {
+	if len(cmd.Args) != 3 {
+		return "", false
+	}
+	fn, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok || fn.Ident != "index" {
+		return "", false
+	}
+	field, ok := cmd.Args[1].(*parse.FieldNode)
+	if !ok || len(field.Ident) != 1 || field.Ident[0] != templateRoot {
+		return "", false
+	}
+	name, ok := cmd.Args[2].(*parse.StringNode)
+	if !ok {
+		return "", false
+	}
+	return name.Text, true
+}
+
+// walkConfig calls visit for every string of config that holds a template
+func walkConfig(path string, value any, visit func(path, text string)) {
+	mapConfig(path, value, func(path, text string) any {
+		visit(path, text)
+		return text
+	})
+}
+
+// mapConfig returns a copy of value where every string holding a template is
+// replaced by the result of fn. Map keys are visited in sorted order.
+func mapConfig(path string, value any, fn func(path, text string) any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if v == nil {
+			return v
+		}
+		out := make(map[string]any, len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			out[key] = mapConfig(path+"."+key, v[key], fn)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = mapConfig(fmt.Sprintf("%s[%d]", path, i), item, fn)
+		}
+		return out
+	case string:
+		if strings.Contains(v, "{{") {
+			return fn(path, v)
+		}
+		return v
+	default:
+		return v
+	}
+}
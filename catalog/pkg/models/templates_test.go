@@ -0,0 +1,193 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templatedComponent() *Component {
+	component := validRelationsComponent()
+	component.Inputs = []InputSpec{
+		{Name: "db_name", Type: "string", Description: "Database name", Validation: Validation{Required: true}},
+		{Name: "size", Type: "string", Description: "Instance size", Default: "small"},
+		{Name: "replicas", Type: "number", Description: "Replica count", Default: 1},
+	}
+	component.Deployment.Config = map[string]any{
+		"source":         "git::https://example.com/modules/postgres",
+		"module_version": "1.0.0",
+		"name":           "{{ .inputs.db_name }}",
+		"instance_class": "db.t3.{{ .inputs.size }}",
+		"replicas":       "{{ .inputs.replicas }}",
+		"outputs":        map[string]any{"endpoint": "db_instance_address"},
+	}
+	return component
+}
+
+func TestConfigTemplates(t *testing.T) {
+	refs, all, errs := ConfigTemplates("config", map[string]any{
+		"name":    "{{ .inputs.db_name }}",
+		"plain":   "no template",
+		"tags":    []any{"{{ index .inputs \"team\" }}", map[string]any{"env": "{{ $.inputs.env | printf \"%s\" }}"}},
+		"scoped":  "{{ with .inputs.size }}{{ .label }}{{ end }}",
+		"bad":     "{{ .inputs.db_name",
+		"unknown": "{{ .component.name }}",
+	})
+
+	assert.Equal(t, []InputReference{
+		{Path: "config.name", Input: "db_name"},
+		{Path: "config.scoped", Input: "size"},
+		{Path: "config.tags[0]", Input: "team"},
+		{Path: "config.tags[1].env", Input: "env"},
+	}, refs)
+	assert.False(t, all)
+
+	require.Len(t, errs, 2)
+	assert.Equal(t, "config.bad", errs[0].Field)
+	assert.Equal(t, RuleFormat, errs[0].Rule)
+	assert.Equal(t, "config.unknown", errs[1].Field)
+	assert.Equal(t, RuleReference, errs[1].Rule)
+
+	_, all, errs = ConfigTemplates("config", map[string]any{"all": `{{ printf "%v" .inputs }}`})
+	assert.True(t, all)
+	assert.Empty(t, errs)
+}
+
+func TestComponentValidator_ValidateTemplates(t *testing.T) {
+	validator := NewComponentValidator()
+
+	tests := []struct {
+		name     string
+		mutate   func(c *Component)
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:   "valid component",
+			mutate: func(c *Component) {},
+		},
+		{
+			name:   "undeclared input",
+			mutate: func(c *Component) { c.Deployment.Config["zone"] = "{{ .inputs.zone }}" },
+			errors: []string{"deployment.config.zone"},
+		},
+		{
+			name:   "invalid template",
+			mutate: func(c *Component) { c.Deployment.Config["zone"] = "{{ .inputs.zone" },
+			errors: []string{"deployment.config.zone"},
+		},
+		{
+			name:     "unused input",
+			mutate:   func(c *Component) { delete(c.Deployment.Config, "replicas") },
+			warnings: []string{"inputs[2]"},
+		},
+		{
+			name: "inputs used as a whole",
+			mutate: func(c *Component) {
+				delete(c.Deployment.Config, "replicas")
+				c.Deployment.Config["vars"] = "{{ printf \"%v\" .inputs }}"
+			},
+		},
+		{
+			name: "unmapped output",
+			mutate: func(c *Component) {
+				c.Outputs = append(c.Outputs, OutputSpec{Name: "port", Type: "number", Description: "Port"})
+			},
+			errors: []string{"deployment.config.outputs"},
+		},
+		{
+			name:   "mapped undeclared output",
+			mutate: func(c *Component) { c.Deployment.Config["outputs"].(map[string]any)["arn"] = "db_instance_arn" },
+			errors: []string{"deployment.config.outputs.arn"},
+		},
+		{
+			name: "additional deployment",
+			mutate: func(c *Component) {
+				c.AdditionalDeployments = []DeploymentSpec{{
+					Engine:  "crossplane",
+					Version: "1.14.0",
+					Config: map[string]any{
+						"apiVersion":        "database.example.org/v1alpha1",
+						"kind":              "XPostgres",
+						"compositionRef":    map[string]any{"name": "xpostgres-{{ .inputs.tier }}"},
+						"connectionDetails": map[string]any{},
+					},
+				}}
+			},
+			errors:   []string{"additional_deployments[0].config.compositionRef.name", "additional_deployments[0].config.connectionDetails"},
+			warnings: []string{"inputs[0]", "inputs[1]", "inputs[2]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := templatedComponent()
+			tt.mutate(component)
+
+			issues := validator.Issues(component)
+			assert.ElementsMatch(t, tt.errors, issuePaths(issues.Errors()))
+			assert.ElementsMatch(t, tt.warnings, issuePaths(issues.Warnings()))
+		})
+	}
+}
+
+func TestComponentValidator_TemplateMessages(t *testing.T) {
+	component := templatedComponent()
+	component.Deployment.Config["zone"] = "{{ .inputs.zone }}"
+
+	err := NewComponentValidator().Validate(component)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deployment for engine 'terraform' references undeclared input 'zone'")
+}
+
+func TestComponent_Preview(t *testing.T) {
+	component := templatedComponent()
+
+	config, err := component.Preview("terraform", map[string]any{"db_name": "orders", "replicas": 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"source":         "git::https://example.com/modules/postgres",
+		"module_version": "1.0.0",
+		"name":           "orders",
+		"instance_class": "db.t3.small",
+		"replicas":       3,
+		"outputs":        map[string]any{"endpoint": "db_instance_address"},
+	}, config)
+	assert.Equal(t, "{{ .inputs.db_name }}", component.Deployment.Config["name"], "the component is left untouched")
+
+	_, err = component.Preview("terraform", map[string]any{"replicas": "three"})
+	var issues ValidationErrors
+	require.ErrorAs(t, err, &issues)
+	assert.Equal(t, []string{"inputs.db_name", "inputs.replicas"}, issuePaths(issues))
+
+	_, err = component.Preview("pulumi", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedEngine)
+}
+
+func TestRenderConfig(t *testing.T) {
+	config, err := RenderConfig(map[string]any{
+		"list":  []any{"{{ .inputs.a }}", "x-{{ .inputs.b }}"},
+		"empty": "[{{ .inputs.b }}]",
+	}, map[string]any{"a": true, "b": nil})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"list": []any{true, "x-"}, "empty": "[]"}, config)
+
+	_, err = RenderConfig(map[string]any{"name": "{{ .inputs.missing }}"}, map[string]any{})
+	assert.ErrorContains(t, err, "config.name: input 'missing' has no value")
+
+	_, err = RenderConfig(map[string]any{"name": "db-{{ .inputs.missing }}"}, map[string]any{})
+	assert.ErrorContains(t, err, "config.name")
+
+	config, err = RenderConfig(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, config)
+}
+
+func issuePaths(issues ValidationErrors) []string {
+	paths := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		paths = append(paths, issue.Path)
+	}
+	return paths
+}
@@ -0,0 +1,425 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// TypeKind identifies the shape of an input or output value
+type TypeKind string
+
+const (
+	KindString  TypeKind = "string"
+	KindNumber  TypeKind = "number"
+	KindInteger TypeKind = "integer"
+	KindBool    TypeKind = "bool"
+	KindList    TypeKind = "list"
+	KindSet     TypeKind = "set"
+	KindMap     TypeKind = "map"
+	KindObject  TypeKind = "object"
+	KindAny     TypeKind = "any"
+)
+
+// Type is the parsed form of an InputSpec or OutputSpec type.
+//
+// The grammar is:
+//
+//	type     = scalar | list(type) | set(type) | map(type)
+//	         | object({ name = type, ... }) | optional(type)
+//	scalar   = string | number | integer | bool | any
+//
+// For compatibility with older specs, boolean and int are accepted as
+// aliases, array as list, and bare list, set, map and object stand for
+// collections of any.
+type Type struct {
+	Kind TypeKind
+	// Elem is the element type of lists, sets and maps
+	Elem *Type
+	// Fields are the attributes of an object, in declaration order
+	Fields []ObjectField
+	// Optional marks a value that may be omitted or null
+	Optional bool
+}
+
+// ObjectField is an attribute of an object type
+type ObjectField struct {
+	Name string
+	Type *Type
+}
+
+// ParseType parses a type expression
+func ParseType(expr string) (*Type, error) {
+	p := &typeParser{input: expr}
+	t, err := p.parseType()
+	if err != nil {
+		return nil, fmt.Errorf("invalid type '%s': %w", expr, err)
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("invalid type '%s': unexpected '%s' at position %d", expr, p.input[p.pos:], p.pos)
+	}
+	return t, nil
+}
+
+// MustParseType is like ParseType but panics on error. It is meant for
+// types known at compile time.
+func MustParseType(expr string) *Type {
+	t, err := ParseType(expr)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ParsedType returns the parsed type of the input
+func (i *InputSpec) ParsedType() (*Type, error) {
+	return ParseType(i.Type)
+}
+
+// ParsedType returns the parsed type of the output
+func (o *OutputSpec) ParsedType() (*Type, error) {
+	return ParseType(o.Type)
+}
+
+// String returns the canonical form of the type
+func (t *Type) String() string {
+	var s string
+	switch t.Kind {
+	case KindList, KindSet, KindMap:
+		s = fmt.Sprintf("%s(%s)", t.Kind, t.Elem)
+	case KindObject:
+		fields := make([]string, len(t.Fields))
+		for i, field := range t.Fields {
+			fields[i] = fmt.Sprintf("%s = %s", field.Name, field.Type)
+		}
+		s = fmt.Sprintf("object({%s})", strings.Join(fields, ", "))
+	default:
+		s = string(t.Kind)
+	}
+	if t.Optional {
+		return "optional(" + s + ")"
+	}
+	return s
+}
+
+// Field returns the object attribute called name
+func (t *Type) Field(name string) (*ObjectField, bool) {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// AssignableTo reports whether every value of t is a valid value of target,
+// so that an output of type t can be wired into an input of type target.
+// Integers are numbers, lists and sets convert into each other, and objects
+// may carry attributes the target does not declare.
+func (t *Type) AssignableTo(target *Type) bool {
+	if t.Optional && !target.Optional {
+		return false
+	}
+	if target.Kind == KindAny {
+		return true
+	}
+
+	switch t.Kind {
+	case KindAny:
+		return false
+	case KindInteger:
+		return target.Kind == KindInteger || target.Kind == KindNumber
+	case KindList, KindSet:
+		if target.Kind != KindList && target.Kind != KindSet {
+			return false
+		}
+		return t.Elem.AssignableTo(target.Elem)
+	case KindMap:
+		return target.Kind == KindMap && t.Elem.AssignableTo(target.Elem)
+	case KindObject:
+		if target.Kind == KindMap {
+			for _, field := range t.Fields {
+				if !field.Type.AssignableTo(target.Elem) {
+					return false
+				}
+			}
+			return true
+		}
+		if target.Kind != KindObject {
+			return false
+		}
+		for _, want := range target.Fields {
+			have, ok := t.Field(want.Name)
+			if !ok {
+				if !want.Type.Optional {
+					return false
+				}
+				continue
+			}
+			if !have.Type.AssignableTo(want.Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return t.Kind == target.Kind
+	}
+}
+
+// Check validates value against the type and returns every failure, with
+// fields named from path
+func (t *Type) Check(path string, value any) []FieldError {
+	if value == nil {
+		if t.Optional || t.Kind == KindAny {
+			return nil
+		}
+		return []FieldError{{Field: path, Rule: RuleRequired, Message: "is required"}}
+	}
+
+	mismatch := []FieldError{{Field: path, Rule: RuleType, Message: fmt.Sprintf("must be of type %s", t.withoutOptional())}}
+
+	switch t.Kind {
+	case KindString:
+		if _, ok := value.(string); !ok {
+			return mismatch
+		}
+	case KindNumber:
+		if _, ok := toFloat(value); !ok {
+			return mismatch
+		}
+	case KindInteger:
+		if n, ok := toFloat(value); !ok || n != math.Trunc(n) {
+			return mismatch
+		}
+	case KindBool:
+		if _, ok := value.(bool); !ok {
+			return mismatch
+		}
+	case KindList, KindSet:
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return mismatch
+		}
+		var errs []FieldError
+		for i := range v.Len() {
+			errs = append(errs, t.Elem.Check(fmt.Sprintf("%s[%d]", path, i), v.Index(i).Interface())...)
+		}
+		if t.Kind == KindSet {
+			for i := range v.Len() {
+				for j := range i {
+					if reflect.DeepEqual(v.Index(i).Interface(), v.Index(j).Interface()) {
+						errs = append(errs, FieldError{Field: fmt.Sprintf("%s[%d]", path, i), Rule: RuleUnique, Message: fmt.Sprintf("duplicates element %d", j)})
+						break
+					}
+				}
+			}
+		}
+		return errs
+	case KindMap, KindObject:
+		entries, ok := stringKeyedEntries(value)
+		if !ok {
+			return mismatch
+		}
+		var errs []FieldError
+		if t.Kind == KindMap {
+			for _, key := range slices.Sorted(maps.Keys(entries)) {
+				errs = append(errs, t.Elem.Check(path+"."+key, entries[key])...)
+			}
+			return errs
+		}
+		for _, field := range t.Fields {
+			errs = append(errs, field.Type.Check(path+"."+field.Name, entries[field.Name])...)
+		}
+		for _, key := range slices.Sorted(maps.Keys(entries)) {
+			if _, known := t.Field(key); !known {
+				errs = append(errs, FieldError{Field: path + "." + key, Rule: RuleUnknown, Message: "is not an attribute of the object"})
+			}
+		}
+		return errs
+	}
+
+	return nil
+}
+
+func (t *Type) withoutOptional() *Type {
+	if !t.Optional {
+		return t
+	}
+	required := *t
+	required.Optional = false
+	return &required
+}
+
+// stringKeyedEntries returns the entries of a map with string keys
+func stringKeyedEntries(value any) (map[string]any, bool) {
+	if m, ok := value.(map[string]any); ok {
+		return m, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	entries := make(map[string]any, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries[iter.Key().String()] = iter.Value().Interface()
+	}
+	return entries, true
+}
+
+// typeParser is a recursive descent parser for the type grammar
+type typeParser struct {
+	input string
+	pos   int
+}
+
+var scalarTypes = map[string]TypeKind{
+	"string":  KindString,
+	"number":  KindNumber,
+	"integer": KindInteger,
+	"int":     KindInteger,
+	"bool":    KindBool,
+	"boolean": KindBool,
+	"any":     KindAny,
+}
+
+var collectionTypes = map[string]TypeKind{
+	"list":  KindList,
+	"array": KindList,
+	"set":   KindSet,
+	"map":   KindMap,
+}
+
+func (p *typeParser) parseType() (*Type, error) {
+	start := p.pos
+	name := p.ident()
+	if name == "" {
+		return nil, p.errorf("expected a type")
+	}
+
+	if kind, ok := scalarTypes[name]; ok {
+		return &Type{Kind: kind}, nil
+	}
+
+	if kind, ok := collectionTypes[name]; ok {
+		if !p.accept('(') {
+			return &Type{Kind: kind, Elem: &Type{Kind: KindAny}}, nil
+		}
+		elem, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if elem.Optional {
+			return nil, fmt.Errorf("optional is only allowed for object attributes and top-level types, not in %s", name)
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return &Type{Kind: kind, Elem: elem}, nil
+	}
+
+	switch name {
+	case "object":
+		if !p.accept('(') {
+			return &Type{Kind: KindMap, Elem: &Type{Kind: KindAny}}, nil
+		}
+		t, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		return t, p.expect(')')
+	case "optional":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		if inner.Optional {
+			return nil, fmt.Errorf("optional cannot be nested")
+		}
+		inner.Optional = true
+		return inner, p.expect(')')
+	}
+
+	p.pos = start
+	return nil, p.errorf("unknown type '%s'", name)
+}
+
+func (p *typeParser) parseObject() (*Type, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+
+	t := &Type{Kind: KindObject, Fields: []ObjectField{}}
+	for !p.accept('}') {
+		name := p.ident()
+		if name == "" {
+			return nil, p.errorf("expected an attribute name")
+		}
+		if _, dup := t.Field(name); dup {
+			return nil, fmt.Errorf("attribute '%s' is declared more than once", name)
+		}
+		if !p.accept('=') && !p.accept(':') {
+			return nil, p.errorf("expected '=' after attribute '%s'", name)
+		}
+		fieldType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		t.Fields = append(t.Fields, ObjectField{Name: name, Type: fieldType})
+
+		if !p.accept(',') {
+			if err := p.expect('}'); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return t, nil
+}
+
+func (p *typeParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *typeParser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c != '_' && c != '-' && !unicode.IsLetter(rune(c)) && !unicode.IsDigit(rune(c)) {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *typeParser) accept(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *typeParser) expect(c byte) error {
+	if !p.accept(c) {
+		return p.errorf("expected '%c'", c)
+	}
+	return nil
+}
+
+func (p *typeParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"string", "string"},
+		{" number ", "number"},
+		{"boolean", "bool"},
+		{"int", "integer"},
+		{"list(string)", "list(string)"},
+		{"array", "list(any)"},
+		{"set( number )", "set(number)"},
+		{"map(list(string))", "map(list(string))"},
+		{"object", "map(any)"},
+		{"object({name = string, port: optional(number), tags = set(string),})", "object({name = string, port = optional(number), tags = set(string)})"},
+		{"object({})", "object({})"},
+		{"optional(map(bool))", "optional(map(bool))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			typ, err := ParseType(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, typ.String())
+
+			again, err := ParseType(typ.String())
+			require.NoError(t, err)
+			assert.Equal(t, typ, again, "canonical form must parse back to the same type")
+		})
+	}
+}
+
+func TestParseType_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "expected a type at position 0"},
+		{"tuple", "unknown type 'tuple' at position 0"},
+		{"list(string", "expected ')' at position 11"},
+		{"list(string))", "unexpected ')' at position 12"},
+		{"list(optional(string))", "optional is only allowed for object attributes"},
+		{"optional(optional(string))", "optional cannot be nested"},
+		{"object({name string})", "expected '=' after attribute 'name'"},
+		{"object({a = string, a = number})", "attribute 'a' is declared more than once"},
+		{"map(strin)", "unknown type 'strin'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseType(tt.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestType_AssignableTo(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"string", "string", true},
+		{"string", "number", false},
+		{"integer", "number", true},
+		{"number", "integer", false},
+		{"string", "any", true},
+		{"any", "string", false},
+		{"list(string)", "set(string)", true},
+		{"set(integer)", "list(number)", true},
+		{"list(string)", "map(string)", false},
+		{"map(integer)", "map(number)", true},
+		{"optional(string)", "string", false},
+		{"string", "optional(string)", true},
+		{"object({host = string, port = integer})", "object({port = number})", true},
+		{"object({host = string})", "object({host = string, port = optional(number)})", true},
+		{"object({host = string})", "object({host = string, port = number})", false},
+		{"object({host = string, port = number})", "map(string)", false},
+		{"object({a = string, b = string})", "map(string)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.want, MustParseType(tt.from).AssignableTo(MustParseType(tt.to)))
+		})
+	}
+}
+
+func TestComponentValidator_ValidateTypes(t *testing.T) {
+	validator := NewComponentValidator()
+
+	tests := []struct {
+		name   string
+		mutate func(c *Component)
+		errMsg string
+	}{
+		{
+			name:   "complex types",
+			mutate: func(c *Component) { c.Inputs[0].Type = "object({name = string, port = optional(number)})" },
+		},
+		{
+			name:   "unknown input type",
+			mutate: func(c *Component) { c.Inputs[0].Type = "text" },
+			errMsg: "input 'db_name' has invalid type: invalid type 'text'",
+		},
+		{
+			name:   "unknown output type",
+			mutate: func(c *Component) { c.Outputs[0].Type = "list(" },
+			errMsg: "output 'endpoint' has invalid type",
+		},
+		{
+			name: "default matches type",
+			mutate: func(c *Component) {
+				c.Inputs[0].Type = "list(string)"
+				c.Inputs[0].Default = []any{"a", "b"}
+			},
+		},
+		{
+			name: "default does not match type",
+			mutate: func(c *Component) {
+				c.Inputs[0].Type = "list(string)"
+				c.Inputs[0].Default = []any{"a", 2.0}
+			},
+			errMsg: "input 'db_name' default does not match type list(string): default[1]: must be of type string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := validRelationsComponent()
+			tt.mutate(component)
+
+			err := validator.Validate(component)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity tells whether a validation issue prevents publishing a component
+type Severity string
+
+const (
+	// SeverityError issues make the component invalid
+	SeverityError Severity = "error"
+	// SeverityWarning issues are reported but do not fail validation
+	SeverityWarning Severity = "warning"
+)
+
+// ValidationIssue is a single problem found while validating a component
+type ValidationIssue struct {
+	// Path locates the offending field in the JSON form of the component,
+	// such as "inputs[2].validation.pattern"
+	Path     string   `json:"path"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity"`
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// ValidationErrors lists every issue found in a component, in the order the
+// checks ran. It is returned as an error when at least one issue has
+// SeverityError.
+type ValidationErrors []ValidationIssue
+
+func (e ValidationErrors) Error() string {
+	return "validation failed: " + e.Summary()
+}
+
+// Summary joins the messages of the issues with SeverityError
+func (e ValidationErrors) Summary() string {
+	messages := make([]string, 0, len(e))
+	for _, issue := range e.Errors() {
+		messages = append(messages, issue.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// HasErrors reports whether any issue has SeverityError
+func (e ValidationErrors) HasErrors() bool {
+	return len(e.Errors()) > 0
+}
+
+// Errors returns the issues with SeverityError
+func (e ValidationErrors) Errors() ValidationErrors {
+	return e.withSeverity(SeverityError)
+}
+
+// Warnings returns the issues with SeverityWarning
+func (e ValidationErrors) Warnings() ValidationErrors {
+	return e.withSeverity(SeverityWarning)
+}
+
+func (e ValidationErrors) withSeverity(severity Severity) ValidationErrors {
+	var issues ValidationErrors
+	for _, issue := range e {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (e *ValidationErrors) errorf(path, rule, format string, args ...any) {
+	e.add(SeverityError, path, rule, format, args...)
+}
+
+func (e *ValidationErrors) warnf(path, rule, format string, args ...any) {
+	e.add(SeverityWarning, path, rule, format, args...)
+}
+
+func (e *ValidationErrors) add(severity Severity, path, rule, format string, args ...any) {
+	*e = append(*e, ValidationIssue{
+		Path:     path,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+		Severity: severity,
+	})
+}
+
+// has reports whether an issue was already recorded for rule at path
+func (e ValidationErrors) has(path, rule string) bool {
+	for _, issue := range e {
+		if issue.Path == path && issue.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,33 @@ type ComponentVersion struct {
 	Checksum        string              `json:"checksum"`
 	PreviousVersion *string             `json:"previous_version,omitempty"`
 	Status          VersionStatus       `json:"status"`
+	// SunsetAt is when a deprecated version stops being resolved
+	SunsetAt *time.Time `json:"sunset_at,omitempty"`
+	// DeprecatedAt and Deprecation describe the deprecation of the version,
+	// so that it can be reported without reading the version
+	DeprecatedAt *time.Time   `json:"deprecated_at,omitempty"`
+	Deprecation  *Deprecation `json:"deprecation,omitempty"`
+	// Maintainers decide who sees the version while it is a draft
+	Maintainers []string `json:"maintainers,omitempty"`
+}
+
+// NewComponentVersion returns the history entry of a component version
+func NewComponentVersion(component *Component) ComponentVersion {
+	version := ComponentVersion{
+		ComponentName: component.Name,
+		Version:       component.Version,
+		CreatedAt:     component.CreatedAt,
+		GitCommit:     component.Metadata.GitCommit,
+		Checksum:      component.Metadata.Checksum,
+		Status:        component.Status(),
+		DeprecatedAt:  component.Metadata.DeprecatedAt,
+		Maintainers:   component.Maintainers,
+	}
+	if component.IsDeprecated() && component.Metadata.Deprecation != nil {
+		version.Deprecation = component.Metadata.Deprecation.Clone()
+		version.SunsetAt = version.Deprecation.SunsetAt
+	}
+	return version
 }
 
 type SemanticVersionInfo struct {
@@ -61,17 +89,26 @@ type ComponentChange struct {
 	Summary             string                 `json:"summary"`
 	Rollback            *RollbackInfo          `json:"rollback,omitempty"`
 	SemanticVersionInfo *SemanticVersionChange `json:"semantic_version_info"`
+	// Approvers are the maintainers whose approval of the promoted draft was
+	// still valid when it was promoted
+	Approvers []string `json:"approvers,omitempty"`
+	// Warnings are the issues the publish checks raised without blocking the
+	// promotion
+	Warnings ValidationErrors `json:"warnings,omitempty"`
 }
 
 type ChangeType string
 
 const (
-	ChangeTypeCreate   ChangeType = "create"
-	ChangeTypeUpdate   ChangeType = "update"
-	ChangeTypeDelete   ChangeType = "delete"
-	ChangeTypeRestore  ChangeType = "restore"
-	ChangeTypeRollback ChangeType = "rollback"
-	ChangeTypeYank     ChangeType = "yank"
+	ChangeTypeCreate    ChangeType = "create"
+	ChangeTypeUpdate    ChangeType = "update"
+	ChangeTypeDelete    ChangeType = "delete"
+	ChangeTypeRestore   ChangeType = "restore"
+	ChangeTypeRollback  ChangeType = "rollback"
+	ChangeTypeYank      ChangeType = "yank"
+	ChangeTypePromote   ChangeType = "promote"
+	ChangeTypeApprove   ChangeType = "approve"
+	ChangeTypeDeprecate ChangeType = "deprecate"
 )
 
 type FieldChange struct {
@@ -79,6 +116,8 @@ type FieldChange struct {
 	OldValue  any    `json:"old_value"`
 	NewValue  any    `json:"new_value"`
 	Operation string `json:"operation"`
+	// Breaking is set when the change breaks consumers of the old version
+	Breaking bool `json:"breaking,omitempty"`
 }
 
 type SemanticVersionChange struct {
@@ -116,9 +155,12 @@ type DiffSummary struct {
 	RecommendedVersionBump string   `json:"recommended_version_bump"`
 }
 
+// VersionConstraint is a parsed constraint. Ranges holds one range per
+// "||" alternative; Operator and Version are only set for constraints made
+// of a single comparator on a full version, such as ^1.2.0
 type VersionConstraint struct {
 	Raw      string              `json:"raw"`
-	Operator ConstraintOperator  `json:"operator"`
+	Operator ConstraintOperator  `json:"operator,omitempty"`
 	Version  SemanticVersionInfo `json:"version"`
 	Ranges   []VersionRange      `json:"ranges"`
 }
@@ -136,11 +178,14 @@ const (
 	OperatorAny          ConstraintOperator = "*"
 )
 
+// VersionRange is an interval of versions. Max is ignored when Unbounded is
+// set
 type VersionRange struct {
 	Min        SemanticVersionInfo `json:"min"`
 	Max        SemanticVersionInfo `json:"max"`
 	IncludeMin bool                `json:"include_min"`
 	IncludeMax bool                `json:"include_max"`
+	Unbounded  bool                `json:"unbounded,omitempty"`
 }
 
 func ParseSemanticVersion(version string) (*SemanticVersionInfo, error) {
@@ -241,6 +286,24 @@ func (v *SemanticVersionInfo) Compare(other *SemanticVersionInfo) int {
 	return 0
 }
 
+// CompareVersions compares two versions by semantic version precedence.
+// Invalid versions sort before valid ones and among themselves as strings,
+// so that any list of versions can be sorted with it
+func CompareVersions(a, b string) int {
+	va, errA := ParseSemanticVersion(a)
+	vb, errB := ParseSemanticVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	default:
+		return va.Compare(vb)
+	}
+}
+
 func (v *SemanticVersionInfo) IsPreRelease() bool {
 	return v.PreRelease != ""
 }
@@ -342,6 +405,20 @@ func (cv *ComponentVersion) IsYanked() bool {
 	return cv.Status == VersionStatusYanked
 }
 
+// IsMaintainer returns true if user is listed in Maintainers
+func (cv *ComponentVersion) IsMaintainer(user string) bool {
+	return user != "" && slices.Contains(cv.Maintainers, user)
+}
+
+// Warnings returns the warnings to report when the version is read at now,
+// as Component.Warnings does
+func (cv *ComponentVersion) Warnings(now time.Time) []Warning {
+	if !cv.IsDeprecated() && cv.DeprecatedAt == nil {
+		return nil
+	}
+	return []Warning{deprecationWarning(cv.ComponentName, cv.Version, cv.DeprecatedAt, cv.Deprecation, cv.SunsetAt, now)}
+}
+
 type ConstraintParser interface {
 	Parse(constraint string) (*VersionConstraint, error)
 }
@@ -352,6 +429,12 @@ type constraintRule struct {
 	trimLen  int
 }
 
+// tableConstraintParser parses constraints made of alternatives separated
+// by "||", each a set of comparators separated by commas or spaces that
+// must all hold. A comparator is an operator from the table followed by a
+// version, an exact version, an x-range such as 1.2.x or 1.2, or a hyphen
+// range such as 1.2.3 - 1.4.0. Versions after an operator may be partial,
+// as in ^1.2 or >=2
 type tableConstraintParser struct {
 	rules []constraintRule
 }
@@ -365,57 +448,312 @@ func NewConstraintParser() ConstraintParser {
 			{"<", OperatorLessThan, 1},
 			{"~", OperatorTilde, 1},
 			{"^", OperatorCaret, 1},
+			{"=", OperatorEqual, 1},
 		},
 	}
 }
 
 func (p *tableConstraintParser) Parse(constraint string) (*VersionConstraint, error) {
 	constraint = strings.TrimSpace(constraint)
+	if constraint == "" {
+		return nil, fmt.Errorf("invalid version constraint %q: constraint is empty", constraint)
+	}
+
+	vc := &VersionConstraint{Raw: constraint}
+	var comparators []comparator
+	for _, alternative := range strings.Split(constraint, "||") {
+		set, err := p.parseSet(alternative)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		}
+		comparators = append(comparators, set...)
 
-	if constraint == "*" {
-		return &VersionConstraint{
-			Raw:      constraint,
-			Operator: OperatorAny,
-		}, nil
+		r := set[0].bounds
+		for _, c := range set[1:] {
+			r = r.intersect(c.bounds)
+		}
+		vc.Ranges = append(vc.Ranges, r)
 	}
 
-	for _, rule := range p.rules {
-		if strings.HasPrefix(constraint, rule.prefix) {
-			return p.parseWithRule(constraint, rule)
+	// A single comparator on a full version keeps its operator, so that
+	// simple constraints read as before.
+	if len(comparators) == 1 && (comparators[0].full || comparators[0].operator == OperatorAny) {
+		vc.Operator = comparators[0].operator
+		vc.Version = comparators[0].version
+	}
+	return vc, nil
+}
+
+// comparator is a single term of a constraint and the range it admits
+type comparator struct {
+	operator ConstraintOperator
+	version  SemanticVersionInfo
+	// full is set when the version has all three components
+	full   bool
+	bounds VersionRange
+}
+
+// parseSet parses comparators that must all hold
+func (p *tableConstraintParser) parseSet(set string) ([]comparator, error) {
+	tokens := strings.Fields(strings.ReplaceAll(set, ",", " "))
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty alternative")
+	}
+
+	var comparators []comparator
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if i+2 < len(tokens) && tokens[i+1] == "-" {
+			c, err := hyphenRange(token, tokens[i+2])
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, c)
+			i += 2
+			continue
+		}
+
+		// An operator may be separated from its version, as in ">= 1.2.0".
+		if p.isOperator(token) && i+1 < len(tokens) {
+			i++
+			token += tokens[i]
+		}
+		c, err := p.parseComparator(token)
+		if err != nil {
+			return nil, err
 		}
+		comparators = append(comparators, c)
 	}
+	return comparators, nil
+}
 
-	return p.parseExactMatch(constraint)
+func (p *tableConstraintParser) isOperator(token string) bool {
+	for _, rule := range p.rules {
+		if token == rule.prefix {
+			return true
+		}
+	}
+	return false
 }
 
-func (p *tableConstraintParser) parseWithRule(constraint string, rule constraintRule) (*VersionConstraint, error) {
-	versionStr := strings.TrimSpace(constraint[rule.trimLen:])
-	version, err := ParseSemanticVersion(versionStr)
+func (p *tableConstraintParser) parseComparator(token string) (comparator, error) {
+	operator := OperatorEqual
+	text := token
+	for _, rule := range p.rules {
+		if strings.HasPrefix(token, rule.prefix) {
+			operator = rule.operator
+			text = token[rule.trimLen:]
+			break
+		}
+	}
+
+	version, err := parsePartialVersion(text)
 	if err != nil {
-		return nil, fmt.Errorf("invalid version in constraint %q: %w", constraint, err)
+		return comparator{}, fmt.Errorf("invalid version in %q: %w", token, err)
+	}
+	if version.parts == 0 {
+		if operator != OperatorEqual && operator != OperatorGreaterEqual {
+			return comparator{}, fmt.Errorf("%q matches no version", token)
+		}
+		return comparator{operator: OperatorAny, bounds: anyVersion()}, nil
 	}
 
-	return &VersionConstraint{
-		Raw:      constraint,
-		Operator: rule.operator,
-		Version:  *version,
-	}, nil
+	lower := version.lower()
+	next := version.next()
+	var bounds VersionRange
+	switch operator {
+	case OperatorEqual:
+		if version.parts == 3 {
+			bounds = VersionRange{Min: lower, Max: lower, IncludeMin: true, IncludeMax: true}
+		} else {
+			bounds = VersionRange{Min: lower, Max: next, IncludeMin: true}
+		}
+	case OperatorGreaterThan:
+		if version.parts == 3 {
+			bounds = VersionRange{Min: lower, Unbounded: true}
+		} else {
+			bounds = VersionRange{Min: next, IncludeMin: true, Unbounded: true}
+		}
+	case OperatorGreaterEqual:
+		bounds = VersionRange{Min: lower, IncludeMin: true, Unbounded: true}
+	case OperatorLessThan:
+		bounds = VersionRange{Min: semanticVersion(0, 0, 0), IncludeMin: true, Max: lower}
+	case OperatorLessEqual:
+		if version.parts == 3 {
+			bounds = VersionRange{Min: semanticVersion(0, 0, 0), IncludeMin: true, Max: lower, IncludeMax: true}
+		} else {
+			bounds = VersionRange{Min: semanticVersion(0, 0, 0), IncludeMin: true, Max: next}
+		}
+	case OperatorTilde:
+		if version.parts == 1 {
+			bounds = VersionRange{Min: lower, IncludeMin: true, Max: semanticVersion(lower.Major+1, 0, 0)}
+		} else {
+			bounds = VersionRange{Min: lower, IncludeMin: true, Max: semanticVersion(lower.Major, lower.Minor+1, 0)}
+		}
+	case OperatorCaret:
+		bounds = VersionRange{Min: lower, IncludeMin: true, Max: semanticVersion(lower.Major+1, 0, 0)}
+	}
+
+	return comparator{operator: operator, version: lower, full: version.parts == 3, bounds: bounds}, nil
 }
 
-func (p *tableConstraintParser) parseExactMatch(constraint string) (*VersionConstraint, error) {
-	version, err := ParseSemanticVersion(constraint)
+// hyphenRange parses "from - to". A partial upper version admits every
+// version it matches, so 1.2.3 - 1.4 includes 1.4.9
+func hyphenRange(from, to string) (comparator, error) {
+	low, err := parsePartialVersion(from)
+	if err != nil {
+		return comparator{}, fmt.Errorf("invalid version in %q: %w", from, err)
+	}
+	high, err := parsePartialVersion(to)
 	if err != nil {
-		return nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		return comparator{}, fmt.Errorf("invalid version in %q: %w", to, err)
 	}
 
-	return &VersionConstraint{
-		Raw:      constraint,
-		Operator: OperatorEqual,
-		Version:  *version,
-	}, nil
+	bounds := VersionRange{Min: low.lower(), IncludeMin: true}
+	switch high.parts {
+	case 0:
+		bounds.Unbounded = true
+	case 3:
+		bounds.Max, bounds.IncludeMax = high.lower(), true
+	default:
+		bounds.Max = high.next()
+	}
+	return comparator{bounds: bounds}, nil
+}
+
+// partialVersion is a version whose minor and patch may be missing or
+// wildcards, as in 1, 1.2 or 1.2.x
+type partialVersion struct {
+	SemanticVersionInfo
+	// parts counts the components given, 0 for "*"
+	parts int
+}
+
+func parsePartialVersion(text string) (*partialVersion, error) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "v")
+	if text == "" {
+		return nil, fmt.Errorf("version is missing")
+	}
+
+	core, build, _ := strings.Cut(text, "+")
+	core, preRelease, _ := strings.Cut(core, "-")
+
+	var numbers [3]int
+	parts := 0
+	for i, part := range strings.Split(core, ".") {
+		if i == 3 {
+			return nil, fmt.Errorf("invalid semantic version format: %s", text)
+		}
+		if part == "x" || part == "X" || part == "*" {
+			continue
+		}
+		if parts != i {
+			return nil, fmt.Errorf("version %s has a number after a wildcard", text)
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version component %q in %s", part, text)
+		}
+		numbers[i] = n
+		parts++
+	}
+	if preRelease != "" && parts < 3 {
+		return nil, fmt.Errorf("pre-release %s requires a full version", text)
+	}
+
+	v := semanticVersion(numbers[0], numbers[1], numbers[2])
+	v.PreRelease = preRelease
+	v.Build = build
+	v.Raw = v.String()
+	return &partialVersion{SemanticVersionInfo: v, parts: parts}, nil
+}
+
+// lower returns the lowest version matched, with missing components zero
+func (v *partialVersion) lower() SemanticVersionInfo {
+	return v.SemanticVersionInfo
+}
+
+// next returns the first release above every version matched
+func (v *partialVersion) next() SemanticVersionInfo {
+	switch v.parts {
+	case 1:
+		return semanticVersion(v.Major+1, 0, 0)
+	case 2:
+		return semanticVersion(v.Major, v.Minor+1, 0)
+	default:
+		return *v.NextPatch()
+	}
+}
+
+func semanticVersion(major, minor, patch int) SemanticVersionInfo {
+	return SemanticVersionInfo{
+		Major: major,
+		Minor: minor,
+		Patch: patch,
+		Raw:   fmt.Sprintf("%d.%d.%d", major, minor, patch),
+	}
+}
+
+func anyVersion() VersionRange {
+	return VersionRange{Min: semanticVersion(0, 0, 0), IncludeMin: true, Unbounded: true}
+}
+
+// intersect returns the versions in both r and other
+func (r VersionRange) intersect(other VersionRange) VersionRange {
+	result := r
+	if c := other.Min.Compare(&r.Min); c > 0 || (c == 0 && !other.IncludeMin) {
+		result.Min, result.IncludeMin = other.Min, other.IncludeMin
+	}
+	switch {
+	case other.Unbounded:
+	case r.Unbounded:
+		result.Max, result.IncludeMax, result.Unbounded = other.Max, other.IncludeMax, false
+	default:
+		if c := other.Max.Compare(&r.Max); c < 0 || (c == 0 && !other.IncludeMax) {
+			result.Max, result.IncludeMax = other.Max, other.IncludeMax
+		}
+	}
+	return result
+}
+
+// Contains reports whether version is in the range. As with npm, a
+// pre-release is only contained when a bound is a pre-release of the same
+// major.minor.patch, so ^1.2.3-beta.1 admits 1.2.3-beta.2 but not
+// 1.3.0-beta.1
+func (r VersionRange) Contains(version *SemanticVersionInfo) bool {
+	if c := version.Compare(&r.Min); c < 0 || (c == 0 && !r.IncludeMin) {
+		return false
+	}
+	if !r.Unbounded {
+		if c := version.Compare(&r.Max); c > 0 || (c == 0 && !r.IncludeMax) {
+			return false
+		}
+	}
+	if version.IsPreRelease() {
+		return sameRelease(version, &r.Min) || (!r.Unbounded && sameRelease(version, &r.Max))
+	}
+	return true
 }
 
+// sameRelease reports whether bound is a pre-release of the
+// major.minor.patch of version
+func sameRelease(version, bound *SemanticVersionInfo) bool {
+	return bound.IsPreRelease() &&
+		version.Major == bound.Major && version.Minor == bound.Minor && version.Patch == bound.Patch
+}
+
+// Satisfies reports whether version is in any of the ranges of the
+// constraint. Constraints built without ranges fall back to their operator
 func (vc *VersionConstraint) Satisfies(version *SemanticVersionInfo) bool {
+	if len(vc.Ranges) > 0 {
+		for _, r := range vc.Ranges {
+			if r.Contains(version) {
+				return true
+			}
+		}
+		return false
+	}
+
 	switch vc.Operator {
 	case OperatorAny:
 		return true
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintParser_Satisfies(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{"*", []string{"0.0.1", "1.2.3", "10.0.0"}, []string{"1.0.0-alpha"}},
+		{"1.2.3", []string{"1.2.3", "v1.2.3"}, []string{"1.2.4", "1.2.3-rc.1"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.2"}},
+		{">=1.2.0", []string{"1.2.0", "3.0.0"}, []string{"1.1.9"}},
+		{">= 1.2.0", []string{"1.2.0"}, []string{"1.1.9"}},
+		{"<2.0.0", []string{"0.1.0", "1.9.9"}, []string{"2.0.0", "2.0.0-alpha"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"2.0.0", "1.2.2"}},
+		{">=1.2.0, <2.0.0", []string{"1.2.0", "1.9.9"}, []string{"1.1.0", "2.0.0"}},
+		{">=1.2.0 <2.0.0", []string{"1.5.0"}, []string{"2.1.0"}},
+		{">1.2.0 <=1.4.0", []string{"1.2.1", "1.4.0"}, []string{"1.2.0", "1.4.1"}},
+		{"1.2.x", []string{"1.2.0", "1.2.99"}, []string{"1.3.0", "1.1.9"}},
+		{"1.2", []string{"1.2.5"}, []string{"1.3.0"}},
+		{"1.X", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"x", []string{"4.5.6"}, nil},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"~1.2", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2", []string{"1.2.0", "1.8.0"}, []string{"1.1.0", "2.0.0"}},
+		{"1.2.3 - 1.4.0", []string{"1.2.3", "1.4.0"}, []string{"1.2.2", "1.4.1"}},
+		{"1.2 - 1.4", []string{"1.2.0", "1.4.9"}, []string{"1.1.9", "1.5.0"}},
+		{"^1.2 || ^2.0", []string{"1.2.0", "2.5.0"}, []string{"1.1.0", "3.0.0"}},
+		{"<1.0.0 || >=2.0.0, <2.1.0", []string{"0.9.0", "2.0.5"}, []string{"1.5.0", "2.1.0"}},
+		{">=2.0.0, <1.0.0", nil, []string{"1.5.0", "0.5.0", "2.5.0"}},
+	}
+
+	parser := NewConstraintParser()
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			constraint, err := parser.Parse(tt.constraint)
+			require.NoError(t, err)
+
+			for _, version := range tt.matches {
+				info, err := ParseSemanticVersion(version)
+				require.NoError(t, err)
+				assert.True(t, constraint.Satisfies(info), "%s should satisfy %s", version, tt.constraint)
+			}
+			for _, version := range tt.rejects {
+				info, err := ParseSemanticVersion(version)
+				require.NoError(t, err)
+				assert.False(t, constraint.Satisfies(info), "%s should not satisfy %s", version, tt.constraint)
+			}
+		})
+	}
+}
+
+func TestConstraintParser_PreReleases(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{"^1.2.3-beta.1", []string{"1.2.3-beta.1", "1.2.3-beta.2", "1.2.3", "1.5.0"}, []string{"1.2.3-alpha", "1.3.0-beta.1", "1.2.4-rc.1"}},
+		{">=1.2.3-rc.1, <1.3.0", []string{"1.2.3-rc.2", "1.2.8"}, []string{"1.2.4-rc.1"}},
+		{">1.0.0 <2.0.0-rc.2", []string{"2.0.0-rc.1", "1.5.0"}, []string{"2.0.0-rc.2", "1.5.0-beta"}},
+		{"1.2.0-rc.1", []string{"1.2.0-rc.1"}, []string{"1.2.0-rc.2", "1.2.0"}},
+		{"^1.0.0", []string{"1.2.0"}, []string{"1.2.0-rc.1"}},
+		{"^1.0.0 || 1.2.0-rc.1", []string{"1.2.0-rc.1", "1.1.0"}, []string{"1.2.0-rc.2"}},
+	}
+
+	parser := NewConstraintParser()
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			constraint, err := parser.Parse(tt.constraint)
+			require.NoError(t, err)
+
+			for _, version := range tt.matches {
+				info, err := ParseSemanticVersion(version)
+				require.NoError(t, err)
+				assert.True(t, constraint.Satisfies(info), "%s should satisfy %s", version, tt.constraint)
+			}
+			for _, version := range tt.rejects {
+				info, err := ParseSemanticVersion(version)
+				require.NoError(t, err)
+				assert.False(t, constraint.Satisfies(info), "%s should not satisfy %s", version, tt.constraint)
+			}
+		})
+	}
+}
+
+func TestConstraintParser_Normalizes(t *testing.T) {
+	parser := NewConstraintParser()
+
+	constraint, err := parser.Parse("^1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, OperatorCaret, constraint.Operator)
+	assert.Equal(t, "1.2.0", constraint.Version.String())
+	require.Len(t, constraint.Ranges, 1)
+	assert.Equal(t, "1.2.0", constraint.Ranges[0].Min.String())
+	assert.Equal(t, "2.0.0", constraint.Ranges[0].Max.String())
+	assert.True(t, constraint.Ranges[0].IncludeMin)
+	assert.False(t, constraint.Ranges[0].IncludeMax)
+
+	constraint, err = parser.Parse("*")
+	require.NoError(t, err)
+	assert.Equal(t, OperatorAny, constraint.Operator)
+
+	constraint, err = parser.Parse(">=1.2.0, <1.8.0, <2.0.0 || 3.x")
+	require.NoError(t, err)
+	assert.Empty(t, constraint.Operator)
+	require.Len(t, constraint.Ranges, 2)
+	assert.Equal(t, "1.2.0", constraint.Ranges[0].Min.String())
+	assert.Equal(t, "1.8.0", constraint.Ranges[0].Max.String(), "comparators of a set are intersected")
+	assert.False(t, constraint.Ranges[0].Unbounded)
+	assert.Equal(t, "3.0.0", constraint.Ranges[1].Min.String())
+	assert.Equal(t, "4.0.0", constraint.Ranges[1].Max.String())
+
+	constraint, err = parser.Parse(">=1.2.0")
+	require.NoError(t, err)
+	assert.True(t, constraint.Ranges[0].Unbounded)
+}
+
+func TestConstraintParser_Errors(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"", "constraint is empty"},
+		{"^1.2 ||", "empty alternative"},
+		{"1.2.3.4", "invalid semantic version format"},
+		{"1.x.3", "number after a wildcard"},
+		{"1.2-beta", "requires a full version"},
+		{">=abc", "invalid version component"},
+		{"<*", "matches no version"},
+		{"^", "version is missing"},
+	}
+
+	parser := NewConstraintParser()
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			_, err := parser.Parse(tt.constraint)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestVersionConstraint_SatisfiesWithoutRanges(t *testing.T) {
+	version, err := ParseSemanticVersion("1.4.0")
+	require.NoError(t, err)
+
+	assert.True(t, (&VersionConstraint{Operator: OperatorAny}).Satisfies(version))
+	assert.True(t, (&VersionConstraint{Operator: OperatorCaret, Version: semanticVersion(1, 2, 0)}).Satisfies(version))
+	assert.False(t, (&VersionConstraint{Operator: OperatorTilde, Version: semanticVersion(1, 2, 0)}).Satisfies(version))
+}
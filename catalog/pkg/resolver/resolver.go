@@ -0,0 +1,647 @@
+// Package resolver computes the complete set of component versions needed to
+// deploy a root component.
+//
+// Every dependency constraint reachable from the root must hold at the same
+// time and each component is pinned to a single version. The resolver prefers
+// the highest version of every component and backtracks when a choice leads
+// to a conflict, so a solution is found whenever one exists.
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// DefaultMaxSteps bounds the number of candidate versions tried by a single
+// resolution.
+const DefaultMaxSteps = 10000
+
+var (
+	// ErrUnresolvable is matched by every ConflictError.
+	ErrUnresolvable = errors.New("dependencies cannot be resolved")
+	// ErrCycle is matched by ConflictErrors caused by a dependency cycle.
+	ErrCycle = errors.New("dependency cycle")
+	// ErrSearchLimit is returned when the search gives up after MaxSteps.
+	ErrSearchLimit = errors.New("dependency search limit exceeded")
+	// ErrInvalidRequest is returned for requests that cannot be resolved
+	// as given, such as an invalid constraint.
+	ErrInvalidRequest = errors.New("invalid resolution request")
+)
+
+// Reader is the read access to a catalog shared by the resolver, lockfiles
+// and the Go client. The catalog storage backends and client.Client
+// implement it. Readers report missing components and versions with errors
+// matching models.ErrNotFound, and components that no longer match their
+// checksum with errors matching models.ErrChecksumMismatch.
+type Reader interface {
+	GetComponent(ctx context.Context, name, version string) (*models.Component, error)
+	GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error)
+}
+
+// Options controls which dependencies are followed.
+type Options struct {
+	// IncludeOptional resolves optional dependencies as if they were required.
+	IncludeOptional bool
+	// Inputs holds the input values of components by name. A dependency
+	// with a Condition is included when the condition holds for the values
+	// of its component, completed with defaults.
+	Inputs map[string]map[string]any
+	// Include decides whether a dependency with a Condition applies to a
+	// component without Inputs. Nil includes every conditional dependency.
+	Include func(component *models.Component, dependency models.Dependency) (bool, error)
+	// Prefer maps component names to a version tried before any other when
+	// it satisfies every constraint, such as the version pinned by a
+	// lockfile. Other versions are still tried when it leads to a conflict.
+	Prefer map[string]string
+	// AllowSunset lets deprecated versions past their sunset date be
+	// selected. They are refused otherwise.
+	AllowSunset bool
+	// MaxSteps bounds the search. Zero means DefaultMaxSteps.
+	MaxSteps int
+}
+
+// Resolver resolves dependency graphs against a Reader.
+type Resolver struct {
+	store  Reader
+	parser models.ConstraintParser
+	opts   Options
+}
+
+// New creates a Resolver reading from store.
+func New(store Reader, opts Options) *Resolver {
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = DefaultMaxSteps
+	}
+	return &Resolver{
+		store:  store,
+		parser: models.NewConstraintParser(),
+		opts:   opts,
+	}
+}
+
+// Resolution is a consistent set of pinned component versions.
+type Resolution struct {
+	Root string `json:"root"`
+	// Components lists every resolved component, dependencies before the
+	// components that need them.
+	Components []ResolvedComponent `json:"components"`
+	// Warnings reports the deprecations of the resolved versions, in the
+	// order of Components.
+	Warnings []models.Warning `json:"warnings,omitempty"`
+}
+
+// ResolvedComponent is a component pinned to a single version.
+type ResolvedComponent struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Dependencies names the resolved direct dependencies.
+	Dependencies []string `json:"dependencies,omitempty"`
+	// RequiredBy lists the name@version of every component depending on
+	// this one. It is empty for the root.
+	RequiredBy []string          `json:"required_by,omitempty"`
+	Component  *models.Component `json:"-"`
+}
+
+// Get returns the resolved component called name.
+func (r *Resolution) Get(name string) (*ResolvedComponent, bool) {
+	for i := range r.Components {
+		if r.Components[i].Name == name {
+			return &r.Components[i], true
+		}
+	}
+	return nil, false
+}
+
+// Versions maps every resolved component name to its version.
+func (r *Resolution) Versions() map[string]string {
+	versions := make(map[string]string, len(r.Components))
+	for _, component := range r.Components {
+		versions[component.Name] = component.Version
+	}
+	return versions
+}
+
+// ConflictError explains why no consistent set of versions exists.
+type ConflictError struct {
+	Root   string
+	Reason string
+	// Cycle holds the name@version path of a dependency cycle, first and
+	// last element equal, when a cycle caused the failure.
+	Cycle []string
+
+	depth int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("cannot resolve %s: %s", e.Root, e.Reason)
+}
+
+// Is matches ErrUnresolvable, and ErrCycle when the conflict is a cycle.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrUnresolvable || (target == ErrCycle && len(e.Cycle) > 0)
+}
+
+// Resolve pins name and all of its transitive dependencies. constraint
+// selects the root version; empty means the latest.
+func (r *Resolver) Resolve(ctx context.Context, name, constraint string) (*Resolution, error) {
+	return r.resolve(ctx, name, []string{name}, map[string]string{name: constraint})
+}
+
+// ResolveAll pins several roots, mapped to their constraints, and all of
+// their transitive dependencies together, so that components shared by the
+// roots get a single version. The Root of the resolution lists the roots,
+// separated by commas.
+func (r *Resolver) ResolveAll(ctx context.Context, roots map[string]string) (*Resolution, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w: at least one root is required", ErrInvalidRequest)
+	}
+	names := slices.Sorted(maps.Keys(roots))
+	return r.resolve(ctx, strings.Join(names, ","), names, roots)
+}
+
+func (r *Resolver) resolve(ctx context.Context, root string, names []string, constraints map[string]string) (*Resolution, error) {
+	initial := &state{
+		selected:     make(map[string]*selection),
+		requirements: make(map[string][]requirement, len(names)),
+		order:        names,
+	}
+	for _, name := range names {
+		constraint := constraints[name]
+		if constraint == "" {
+			constraint = "*"
+		}
+		rootConstraint, err := r.parser.Parse(constraint)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid constraint for %s: %w", ErrInvalidRequest, name, err)
+		}
+		initial.requirements[name] = []requirement{{constraint: rootConstraint}}
+	}
+
+	s := &session{
+		Resolver:    r,
+		root:        root,
+		versions:    make(map[string][]candidate),
+		sunset:      make(map[string][]candidate),
+		components:  make(map[string]*models.Component),
+		constraints: make(map[string]*models.VersionConstraint),
+	}
+
+	final, err := s.solve(ctx, initial, 0)
+	if err != nil {
+		return nil, err
+	}
+	return final.resolution(root), nil
+}
+
+// candidate is a published version of a component.
+type candidate struct {
+	version string
+	info    *models.SemanticVersionInfo
+}
+
+// requirement is a constraint placed on a component by another one. by is
+// empty for the root request.
+type requirement struct {
+	by         string
+	constraint *models.VersionConstraint
+}
+
+func (req requirement) describe(name string) string {
+	if req.by == "" {
+		return fmt.Sprintf("%s@%s was requested", name, req.constraint.Raw)
+	}
+	return fmt.Sprintf("%s requires %s@%s", req.by, name, req.constraint.Raw)
+}
+
+type selection struct {
+	candidate
+	component *models.Component
+	deps      []string
+}
+
+func (sel *selection) id() string {
+	return sel.component.Name + "@" + sel.version
+}
+
+// state is a partial solution. It is copied before every choice so that
+// backtracking only has to drop the copy.
+type state struct {
+	selected     map[string]*selection
+	requirements map[string][]requirement
+	order        []string
+}
+
+func (st *state) clone() *state {
+	next := &state{
+		selected:     make(map[string]*selection, len(st.selected)+1),
+		requirements: make(map[string][]requirement, len(st.requirements)),
+		order:        slices.Clone(st.order),
+	}
+	for name, sel := range st.selected {
+		next.selected[name] = sel
+	}
+	for name, reqs := range st.requirements {
+		next.requirements[name] = slices.Clip(reqs)
+	}
+	return next
+}
+
+// requiredBy describes who requires name, for error messages.
+func (st *state) requiredBy(name string) string {
+	var by []string
+	for _, req := range st.requirements[name] {
+		if req.by == "" {
+			by = append(by, "the request")
+		} else {
+			by = append(by, req.by)
+		}
+	}
+	return strings.Join(by, ", ")
+}
+
+// path returns the selected names leading from "from" to "to", both
+// included, or nil if "to" cannot be reached.
+func (st *state) path(from, to string) []string {
+	visited := make(map[string]bool)
+	var walk func(name string) []string
+	walk = func(name string) []string {
+		if name == to {
+			return []string{name}
+		}
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+
+		sel, ok := st.selected[name]
+		if !ok {
+			return nil
+		}
+		for _, dep := range sel.deps {
+			if rest := walk(dep); rest != nil {
+				return append([]string{name}, rest...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+func (st *state) resolution(root string) *Resolution {
+	requiredBy := make(map[string][]string)
+	for _, sel := range st.selected {
+		for _, dep := range sel.deps {
+			requiredBy[dep] = append(requiredBy[dep], sel.id())
+		}
+	}
+
+	// Dependencies first, ties broken by name so the order is stable.
+	var components []ResolvedComponent
+	var warnings []models.Warning
+	now := time.Now()
+	placed := make(map[string]bool, len(st.selected))
+	names := make([]string, 0, len(st.selected))
+	for name := range st.selected {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for progress := true; progress; {
+		progress = false
+		for _, name := range names {
+			sel := st.selected[name]
+			if placed[name] || slices.ContainsFunc(sel.deps, func(dep string) bool { return !placed[dep] }) {
+				continue
+			}
+			placed[name] = true
+			by := requiredBy[name]
+			slices.Sort(by)
+			components = append(components, ResolvedComponent{
+				Name:         name,
+				Version:      sel.version,
+				Dependencies: slices.Sorted(slices.Values(sel.deps)),
+				RequiredBy:   by,
+				Component:    sel.component,
+			})
+			warnings = append(warnings, sel.component.Warnings(now)...)
+			progress = true
+			break
+		}
+	}
+
+	return &Resolution{Root: root, Components: components, Warnings: warnings}
+}
+
+// session holds the caches of a single Resolve call.
+type session struct {
+	*Resolver
+	root        string
+	steps       int
+	versions    map[string][]candidate
+	sunset      map[string][]candidate
+	components  map[string]*models.Component
+	constraints map[string]*models.VersionConstraint
+}
+
+func (s *session) conflict(depth int, format string, args ...any) *ConflictError {
+	return &ConflictError{Root: s.root, Reason: fmt.Sprintf(format, args...), depth: depth}
+}
+
+// solve extends st until every required component is selected. It returns a
+// *ConflictError when st cannot be completed and any other error when the
+// search itself failed.
+func (s *session) solve(ctx context.Context, st *state, depth int) (*state, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	next := ""
+	for _, name := range st.order {
+		if _, ok := st.selected[name]; !ok {
+			next = name
+			break
+		}
+	}
+	if next == "" {
+		return st, nil
+	}
+
+	reqs := st.requirements[next]
+	available, err := s.availableVersions(ctx, next)
+	if err != nil {
+		return nil, err
+	}
+	candidates := matching(available, reqs...)
+	if len(candidates) == 0 {
+		return nil, s.explainUnsatisfiable(depth, next, reqs, available)
+	}
+	if preferred, ok := s.opts.Prefer[next]; ok {
+		if i := slices.IndexFunc(candidates, func(cand candidate) bool { return cand.version == preferred }); i > 0 {
+			candidates = slices.Concat(candidates[i:i+1], candidates[:i], candidates[i+1:])
+		}
+	}
+
+	var best *ConflictError
+	for _, cand := range candidates {
+		s.steps++
+		if s.steps > s.opts.MaxSteps {
+			return nil, fmt.Errorf("%w: gave up resolving %s after %d steps", ErrSearchLimit, s.root, s.opts.MaxSteps)
+		}
+
+		extended, err := s.choose(ctx, st, next, cand, depth)
+		if err == nil {
+			var final *state
+			if final, err = s.solve(ctx, extended, depth+1); err == nil {
+				return final, nil
+			}
+		}
+
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) {
+			return nil, err
+		}
+		if best == nil || conflict.depth > best.depth {
+			best = conflict
+		}
+	}
+	return nil, best
+}
+
+// choose selects cand for name and records the requirements of its
+// dependencies.
+func (s *session) choose(ctx context.Context, st *state, name string, cand candidate, depth int) (*state, error) {
+	component, err := s.component(ctx, name, cand.version)
+	if err != nil {
+		return nil, err
+	}
+	sel := &selection{candidate: cand, component: component}
+	id := sel.id()
+
+	for _, other := range component.ConflictsWith {
+		if _, required := st.requirements[other]; required {
+			return nil, s.conflict(depth, "%s conflicts with %s, which is required by %s", id, other, st.requiredBy(other))
+		}
+	}
+	for _, other := range st.selected {
+		if slices.Contains(other.component.ConflictsWith, name) {
+			return nil, s.conflict(depth, "%s conflicts with %s, which is required by %s", other.id(), name, st.requiredBy(name))
+		}
+	}
+
+	next := st.clone()
+	next.selected[name] = sel
+
+	for _, dep := range component.Dependencies {
+		include, err := s.includes(component, dep)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate condition of %s dependency on %s: %w", id, dep.Name, err)
+		}
+		if !include {
+			continue
+		}
+
+		constraint, err := s.constraint(dep.Version)
+		if err != nil {
+			return nil, s.conflict(depth, "%s has an invalid constraint on %s: %v", id, dep.Name, err)
+		}
+		req := requirement{by: id, constraint: constraint}
+
+		if dep.Name == name {
+			return nil, s.cycle(depth, next, []string{name, name})
+		}
+		if path := next.path(dep.Name, name); path != nil {
+			return nil, s.cycle(depth, next, append([]string{name}, path...))
+		}
+		for _, other := range next.selected {
+			if other.component.Name != dep.Name && slices.Contains(other.component.ConflictsWith, dep.Name) {
+				return nil, s.conflict(depth, "%s conflicts with %s, which is required by %s", other.id(), dep.Name, id)
+			}
+		}
+
+		if chosen, ok := next.selected[dep.Name]; ok && !constraint.Satisfies(chosen.info) {
+			available, err := s.availableVersions(ctx, dep.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, existing := range next.requirements[dep.Name] {
+				if len(matching(available, existing, req)) == 0 {
+					return nil, s.conflict(depth, "%s but %s", existing.describe(dep.Name), req.describe(dep.Name))
+				}
+			}
+			return nil, s.conflict(depth, "%s but %s was selected", req.describe(dep.Name), chosen.id())
+		}
+
+		if _, known := next.requirements[dep.Name]; !known {
+			next.order = append(next.order, dep.Name)
+		}
+		next.requirements[dep.Name] = append(next.requirements[dep.Name], req)
+		if !slices.Contains(sel.deps, dep.Name) {
+			sel.deps = append(sel.deps, dep.Name)
+		}
+	}
+
+	return next, nil
+}
+
+func (s *session) cycle(depth int, st *state, names []string) *ConflictError {
+	path := make([]string, len(names))
+	for i, name := range names {
+		path[i] = st.selected[name].id()
+	}
+	err := s.conflict(depth, "dependency cycle %s", strings.Join(path, " -> "))
+	err.Cycle = path
+	return err
+}
+
+// explainUnsatisfiable reports why no version of name meets reqs, naming
+// the two requirements that cannot hold together when there are such.
+func (s *session) explainUnsatisfiable(depth int, name string, reqs []requirement, available []candidate) *ConflictError {
+	for _, req := range reqs {
+		if len(matching(available, req)) > 0 {
+			continue
+		}
+		if sunset := matching(s.sunset[name], req); len(sunset) > 0 {
+			return s.conflict(depth, "%s but %s@%s is past its sunset date, set AllowSunset to use it anyway",
+				req.describe(name), name, sunset[0].version)
+		}
+	}
+
+	if len(available) == 0 {
+		return s.conflict(depth, "%s but no version of %s is published", reqs[0].describe(name), name)
+	}
+
+	for _, req := range reqs {
+		if len(matching(available, req)) == 0 {
+			versions := make([]string, len(available))
+			for i, cand := range available {
+				versions[i] = cand.version
+			}
+			return s.conflict(depth, "%s but no published version satisfies it (available: %s)", req.describe(name), strings.Join(versions, ", "))
+		}
+	}
+
+	for i := range reqs {
+		for j := i + 1; j < len(reqs); j++ {
+			if len(matching(available, reqs[i], reqs[j])) == 0 {
+				return s.conflict(depth, "%s but %s", reqs[i].describe(name), reqs[j].describe(name))
+			}
+		}
+	}
+
+	described := make([]string, len(reqs))
+	for i, req := range reqs {
+		described[i] = req.describe(name)
+	}
+	return s.conflict(depth, "no version of %s satisfies all of: %s", name, strings.Join(described, "; "))
+}
+
+func (s *session) includes(component *models.Component, dep models.Dependency) (bool, error) {
+	if dep.Optional && !s.opts.IncludeOptional {
+		return false, nil
+	}
+	if dep.Condition == "" {
+		return true, nil
+	}
+	if values, ok := s.opts.Inputs[component.Name]; ok {
+		return models.EvalCondition(dep.Condition, models.WithDefaults(component, values))
+	}
+	if s.opts.Include != nil {
+		return s.opts.Include(component, dep)
+	}
+	return true, nil
+}
+
+// availableVersions returns the resolvable versions of name, highest first.
+// Drafts and yanked versions are never selected, and versions past their
+// sunset date only when AllowSunset is set; those are kept aside to explain
+// conflicts.
+func (s *session) availableVersions(ctx context.Context, name string) ([]candidate, error) {
+	if versions, ok := s.versions[name]; ok {
+		return versions, nil
+	}
+
+	history, err := s.store.GetVersionHistory(ctx, name)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, fmt.Errorf("failed to read versions of %s: %w", name, err)
+	}
+
+	now := time.Now()
+	var versions, sunset []candidate
+	for _, version := range history {
+		if version.Status == models.VersionStatusDraft || version.Status == models.VersionStatusYanked {
+			continue
+		}
+		info, err := models.ParseSemanticVersion(version.Version)
+		if err != nil {
+			continue
+		}
+		cand := candidate{version: version.Version, info: info}
+		if version.SunsetAt != nil && !now.Before(*version.SunsetAt) && !s.opts.AllowSunset {
+			sunset = append(sunset, cand)
+			continue
+		}
+		versions = append(versions, cand)
+	}
+	highestFirst := func(a, b candidate) int {
+		return b.info.Compare(a.info)
+	}
+	slices.SortFunc(versions, highestFirst)
+	slices.SortFunc(sunset, highestFirst)
+
+	s.versions[name] = versions
+	s.sunset[name] = sunset
+	return versions, nil
+}
+
+func (s *session) component(ctx context.Context, name, version string) (*models.Component, error) {
+	id := name + "@" + version
+	if component, ok := s.components[id]; ok {
+		return component, nil
+	}
+
+	component, err := s.store.GetComponent(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", id, err)
+	}
+	s.components[id] = component
+	return component, nil
+}
+
+func (s *session) constraint(raw string) (*models.VersionConstraint, error) {
+	if constraint, ok := s.constraints[raw]; ok {
+		return constraint, nil
+	}
+	constraint, err := s.parser.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	s.constraints[raw] = constraint
+	return constraint, nil
+}
+
+// matching returns the candidates satisfying every requirement. Pre-releases
+// are only picked when every requirement names a pre-release of the same
+// version, see models.VersionRange.Contains.
+func matching(candidates []candidate, reqs ...requirement) []candidate {
+	var matched []candidate
+	for _, cand := range candidates {
+		ok := true
+		for _, req := range reqs {
+			if !req.constraint.Satisfies(cand.info) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, cand)
+		}
+	}
+	return matched
+}
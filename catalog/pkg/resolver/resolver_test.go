@@ -0,0 +1,325 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/internal/storage"
+	"github.com/HatiCode/nestor/catalog/internal/storage/memory"
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+	"github.com/HatiCode/nestor/shared/pkg/logging"
+)
+
+// component builds a component from "name@version" followed by its
+// dependencies as "name@constraint".
+func component(id string, deps ...string) *models.Component {
+	name, version, _ := strings.Cut(id, "@")
+	c := &models.Component{
+		Name:       name,
+		Version:    version,
+		Provider:   "aws",
+		Category:   "networking",
+		Deployment: models.DeploymentSpec{Engine: "terraform", Version: "1.5.0", Config: map[string]any{"source": "git::https://example.com/modules/component", "module_version": "1.0.0"}},
+	}
+	for _, dep := range deps {
+		depName, constraint, _ := strings.Cut(dep, "@")
+		c.Dependencies = append(c.Dependencies, models.Dependency{Name: depName, Type: "component", Version: constraint})
+	}
+	return c
+}
+
+func newStore(t *testing.T, components ...*models.Component) storage.ComponentStore {
+	t.Helper()
+	store := memory.NewComponentStore(logging.NewNoop())
+	require.NoError(t, store.(storage.BulkWriter).PutComponents(context.Background(), components))
+	return store
+}
+
+func TestResolve_PicksHighestConsistentVersions(t *testing.T) {
+	store := newStore(t,
+		component("app@1.0.0", "db@^2.0.0", "vpc@^1.0.0"),
+		component("db@2.0.0", "vpc@>=1.2.0"),
+		component("db@2.3.0", "vpc@~1.4.0"),
+		component("db@3.0.0"),
+		component("vpc@1.0.0"),
+		component("vpc@1.4.2"),
+		component("vpc@1.5.0"),
+	)
+
+	resolution, err := New(store, Options{}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"app": "1.0.0", "db": "2.3.0", "vpc": "1.4.2"}, resolution.Versions())
+
+	names := make([]string, len(resolution.Components))
+	for i, c := range resolution.Components {
+		names[i] = c.Name
+	}
+	assert.Equal(t, []string{"vpc", "db", "app"}, names, "dependencies come first")
+
+	vpc, ok := resolution.Get("vpc")
+	require.True(t, ok)
+	assert.Equal(t, []string{"app@1.0.0", "db@2.3.0"}, vpc.RequiredBy)
+	assert.Equal(t, "1.4.2", vpc.Component.Version)
+}
+
+func TestResolve_BacktracksOnConflict(t *testing.T) {
+	// The newest db needs a vpc that cache rules out; an older db works.
+	store := newStore(t,
+		component("app@1.0.0", "db@^2.0.0", "cache@^1.0.0"),
+		component("db@2.1.0", "vpc@>=1.6.0"),
+		component("db@2.0.0", "vpc@^1.0.0"),
+		component("cache@1.0.0", "vpc@<1.5.0"),
+		component("vpc@1.4.0"),
+		component("vpc@1.6.0"),
+	)
+
+	resolution, err := New(store, Options{}).Resolve(context.Background(), "app", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "1.0.0", "db": "2.0.0", "cache": "1.0.0", "vpc": "1.4.0"}, resolution.Versions())
+}
+
+func TestResolve_ExplainsFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []*models.Component
+		want       string
+		cycle      bool
+	}{
+		{
+			name: "incompatible constraints",
+			components: []*models.Component{
+				component("app@1.0.0", "a@^2.0.0", "b@^1.0.0"),
+				component("a@2.0.0", "vpc@<1.5.0"),
+				component("b@1.0.0", "vpc@>=1.6.0"),
+				component("vpc@1.4.0"),
+				component("vpc@1.6.0"),
+			},
+			want: "a@2.0.0 requires vpc@<1.5.0 but b@1.0.0 requires vpc@>=1.6.0",
+		},
+		{
+			name: "missing component",
+			components: []*models.Component{
+				component("app@1.0.0", "vpc@^1.0.0"),
+			},
+			want: "app@1.0.0 requires vpc@^1.0.0 but no version of vpc is published",
+		},
+		{
+			name: "no matching version",
+			components: []*models.Component{
+				component("app@1.0.0", "vpc@^3.0.0"),
+				component("vpc@1.0.0"),
+				component("vpc@2.0.0"),
+			},
+			want: "app@1.0.0 requires vpc@^3.0.0 but no published version satisfies it (available: 2.0.0, 1.0.0)",
+		},
+		{
+			name: "cycle",
+			components: []*models.Component{
+				component("app@1.0.0", "a@^1.0.0"),
+				component("a@1.0.0", "b@^1.0.0"),
+				component("b@1.0.0", "app@^1.0.0"),
+			},
+			want:  "dependency cycle b@1.0.0 -> app@1.0.0 -> a@1.0.0 -> b@1.0.0",
+			cycle: true,
+		},
+		{
+			name: "conflicts with",
+			components: []*models.Component{
+				component("app@1.0.0", "postgres@^1.0.0", "legacy-postgres@^1.0.0"),
+				func() *models.Component {
+					c := component("postgres@1.0.0")
+					c.ConflictsWith = []string{"legacy-postgres"}
+					return c
+				}(),
+				component("legacy-postgres@1.0.0"),
+			},
+			want: "postgres@1.0.0 conflicts with legacy-postgres, which is required by app@1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(newStore(t, tt.components...), Options{}).Resolve(context.Background(), "app", "")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrUnresolvable)
+			assert.Contains(t, err.Error(), tt.want)
+			assert.Equal(t, tt.cycle, errors.Is(err, ErrCycle))
+		})
+	}
+}
+
+func TestResolve_OptionalAndConditionalDependencies(t *testing.T) {
+	app := component("app@1.0.0", "vpc@^1.0.0")
+	app.Dependencies = append(app.Dependencies,
+		models.Dependency{Name: "kms", Type: "component", Version: "*", Optional: true},
+		models.Dependency{Name: "bastion", Type: "component", Version: "*", Condition: "inputs.public"},
+	)
+	store := newStore(t, app, component("vpc@1.0.0"), component("kms@1.0.0"), component("bastion@1.0.0"))
+
+	resolution, err := New(store, Options{}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "1.0.0", "vpc": "1.0.0", "bastion": "1.0.0"}, resolution.Versions())
+
+	resolution, err = New(store, Options{
+		IncludeOptional: true,
+		Include: func(_ *models.Component, dep models.Dependency) (bool, error) {
+			return dep.Condition != "inputs.public", nil
+		},
+	}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "1.0.0", "vpc": "1.0.0", "kms": "1.0.0"}, resolution.Versions())
+
+	for public, want := range map[bool]int{true: 3, false: 2} {
+		resolution, err = New(store, Options{
+			Inputs: map[string]map[string]any{"app": {"public": public}},
+		}).Resolve(context.Background(), "app", "")
+		require.NoError(t, err)
+		assert.Len(t, resolution.Components, want, "public=%v", public)
+	}
+
+	_, err = New(store, Options{
+		Inputs: map[string]map[string]any{"app": {"public": "yes"}},
+	}).Resolve(context.Background(), "app", "")
+	assert.ErrorContains(t, err, "failed to evaluate condition of app@1.0.0 dependency on bastion")
+}
+
+func TestResolve_SkipsUnavailableVersions(t *testing.T) {
+	store := newStore(t,
+		component("app@1.0.0", "vpc@^1.0.0"),
+		component("vpc@1.0.0"),
+		component("vpc@1.1.0"),
+		component("vpc@1.2.0-rc.1"),
+	)
+	yanked := statusStore{ComponentStore: store, statuses: map[string]models.VersionStatus{"vpc@1.1.0": models.VersionStatusYanked}}
+
+	resolution, err := New(yanked, Options{}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", resolution.Versions()["vpc"], "yanked versions and pre-releases are skipped")
+
+	resolution, err = New(store, Options{}).Resolve(context.Background(), "vpc", "1.2.0-rc.1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0-rc.1", resolution.Versions()["vpc"], "pre-releases can be pinned exactly")
+}
+
+func TestResolve_RefusesSunsetVersions(t *testing.T) {
+	sunset := func(c *models.Component, at time.Time) *models.Component {
+		c.Metadata.Deprecated = true
+		c.Metadata.Deprecation = &models.Deprecation{SunsetAt: &at}
+		return c
+	}
+	store := newStore(t,
+		component("app@1.0.0", "vpc@^1.0.0"),
+		component("vpc@1.0.0"),
+		sunset(component("vpc@1.1.0"), time.Now().Add(-time.Hour)),
+		sunset(component("vpc@1.2.0"), time.Now().Add(-time.Hour)),
+		sunset(component("vpc@1.3.0"), time.Now().Add(24*time.Hour)),
+	)
+
+	resolution, err := New(store, Options{}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", resolution.Versions()["vpc"], "deprecated versions resolve until their sunset date")
+	require.Len(t, resolution.Warnings, 1)
+	assert.Equal(t, models.WarningDeprecated, resolution.Warnings[0].Code)
+	assert.Equal(t, "1.3.0", resolution.Warnings[0].Version)
+
+	_, err = New(store, Options{}).Resolve(context.Background(), "vpc", "~1.2.0")
+	require.ErrorIs(t, err, ErrUnresolvable)
+	assert.Contains(t, err.Error(), "vpc@~1.2.0 was requested but vpc@1.2.0 is past its sunset date, set AllowSunset to use it anyway")
+
+	resolution, err = New(store, Options{AllowSunset: true}).Resolve(context.Background(), "vpc", "~1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", resolution.Versions()["vpc"])
+	require.Len(t, resolution.Warnings, 1)
+	assert.Equal(t, models.WarningSunset, resolution.Warnings[0].Code)
+}
+
+func TestResolve_SearchLimit(t *testing.T) {
+	store := newStore(t,
+		component("app@1.0.0", "a@*", "b@*"),
+		component("a@1.0.0", "vpc@1.0.0"),
+		component("a@2.0.0", "vpc@1.0.0"),
+		component("b@1.0.0", "vpc@2.0.0"),
+		component("vpc@1.0.0"),
+		component("vpc@2.0.0"),
+	)
+
+	_, err := New(store, Options{MaxSteps: 3}).Resolve(context.Background(), "app", "")
+	assert.ErrorIs(t, err, ErrSearchLimit)
+}
+
+// statusStore overrides the status reported for some versions.
+type statusStore struct {
+	storage.ComponentStore
+	statuses map[string]models.VersionStatus
+}
+
+func (s statusStore) GetVersionHistory(ctx context.Context, name string) ([]models.ComponentVersion, error) {
+	history, err := s.ComponentStore.GetVersionHistory(ctx, name)
+	for i, version := range history {
+		if status, ok := s.statuses[name+"@"+version.Version]; ok {
+			history[i].Status = status
+		}
+	}
+	return history, err
+}
+
+func TestResolveAll_SharesDependencies(t *testing.T) {
+	store := newStore(t,
+		component("api@1.0.0", "db@^2.0.0"),
+		component("worker@1.0.0", "db@<2.3.0"),
+		component("db@2.0.0"),
+		component("db@2.2.0"),
+		component("db@2.3.0"),
+	)
+	r := New(store, Options{})
+
+	resolution, err := r.ResolveAll(context.Background(), map[string]string{"worker": "", "api": "^1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "api,worker", resolution.Root)
+	assert.Equal(t, map[string]string{"api": "1.0.0", "worker": "1.0.0", "db": "2.2.0"}, resolution.Versions())
+
+	_, err = r.ResolveAll(context.Background(), map[string]string{"api": "", "db": "^2.3.0", "worker": ""})
+	assert.ErrorIs(t, err, ErrUnresolvable)
+
+	_, err = r.ResolveAll(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestResolve_PrefersPinnedVersions(t *testing.T) {
+	store := newStore(t,
+		component("app@1.0.0", "db@^2.0.0"),
+		component("db@2.0.0"),
+		component("db@2.1.0"),
+	)
+
+	resolution, err := New(store, Options{Prefer: map[string]string{"db": "2.0.0"}}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", resolution.Versions()["db"])
+
+	resolution, err = New(store, Options{Prefer: map[string]string{"db": "1.0.0"}}).Resolve(context.Background(), "app", "")
+	require.NoError(t, err)
+	assert.Equal(t, "2.1.0", resolution.Versions()["db"], "preferences outside the constraints are ignored")
+}
+
+func TestResolve_CompoundConstraints(t *testing.T) {
+	store := newStore(t,
+		component("app@1.0.0", "db@>=2.0.0, <2.2.0 || ^3.0", "vpc@^1.2.0-rc.1"),
+		component("db@2.1.0"),
+		component("db@2.2.0"),
+		component("db@3.0.0-beta.1"),
+		component("vpc@1.2.0-rc.2"),
+		component("vpc@1.3.0-rc.1"),
+	)
+
+	resolution, err := New(store, Options{}).Resolve(context.Background(), "app", "1.x")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "1.0.0", "db": "2.1.0", "vpc": "1.2.0-rc.2"}, resolution.Versions(),
+		"pre-releases only match constraints naming the same version")
+}
@@ -0,0 +1,142 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Key is a trusted public key and the publisher it belongs to.
+type Key struct {
+	ID        string
+	Publisher string
+	PublicKey ed25519.PublicKey
+}
+
+// KeySet is a set of trusted keys indexed by ID.
+type KeySet struct {
+	keys map[string]Key
+}
+
+// keyFile is the content of a key set file:
+//
+//	keys:
+//	  - id: platform-2024
+//	    publisher: platform-team
+//	    public_key: 3q2+7wAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+type keyFile struct {
+	Keys []struct {
+		ID        string `yaml:"id"`
+		Publisher string `yaml:"publisher"`
+		PublicKey string `yaml:"public_key"`
+	} `yaml:"keys"`
+}
+
+// NewKeySet returns a key set of keys. Every key needs an ID, a publisher
+// and a valid public key, and IDs must be unique.
+func NewKeySet(keys ...Key) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]Key, len(keys))}
+	for i, key := range keys {
+		switch {
+		case key.ID == "":
+			return nil, fmt.Errorf("keys[%d]: id is required", i)
+		case key.Publisher == "":
+			return nil, fmt.Errorf("key '%s': publisher is required", key.ID)
+		case len(key.PublicKey) != ed25519.PublicKeySize:
+			return nil, fmt.Errorf("key '%s': invalid ed25519 public key size %d", key.ID, len(key.PublicKey))
+		}
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("key '%s' is declared twice", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+	return set, nil
+}
+
+// LoadKeySet reads a key set from a YAML file listing the id, publisher and
+// base64 encoded public_key of each key.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys: %w", err)
+	}
+	var file keyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	keys := make([]Key, 0, len(file.Keys))
+	for _, entry := range file.Keys {
+		publicKey, err := ParsePublicKey(entry.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key '%s': %w", path, entry.ID, err)
+		}
+		keys = append(keys, Key{ID: entry.ID, Publisher: entry.Publisher, PublicKey: publicKey})
+	}
+	set, err := NewKeySet(keys...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Key returns the key with the given ID.
+func (s *KeySet) Key(id string) (Key, bool) {
+	key, ok := s.keys[id]
+	return key, ok
+}
+
+// Keys returns the keys of the set sorted by ID.
+func (s *KeySet) Keys() []Key {
+	keys := make([]Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b Key) int { return strings.Compare(a.ID, b.ID) })
+	return keys
+}
+
+// GenerateKey returns a new key pair, encoded in base64.
+func GenerateKey() (publicKey, privateKey string, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(public), base64.StdEncoding.EncodeToString(private), nil
+}
+
+// ParsePublicKey decodes a base64 encoded ed25519 public key.
+func ParsePublicKey(text string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, fmt.Errorf("public key is not valid base64: %w", err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key size %d", len(data))
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// ParsePrivateKey decodes a base64 encoded ed25519 private key, either the
+// 64 byte key or its 32 byte seed.
+func ParsePrivateKey(text string) (ed25519.PrivateKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, errors.New("private key is not valid base64")
+	}
+	switch len(data) {
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(data), nil
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(data), nil
+	default:
+		return nil, fmt.Errorf("invalid ed25519 private key size %d", len(data))
+	}
+}
@@ -0,0 +1,155 @@
+// Package signing signs component versions with ed25519 keys and verifies
+// those signatures against a set of trusted keys.
+//
+// A signature covers the checksum of a component (see
+// models.ComputeChecksum), so it stays valid when the component is
+// deprecated but not when its definition changes. Publishers sign with Sign,
+// the catalog verifies signatures on publish with KeySet.Verify, and
+// consumers such as the orchestrator check the components they fetch with a
+// Verifier.
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+// payloadPrefix separates component signatures from any other use of the
+// same keys.
+const payloadPrefix = "nestor-component-signature-v1\n"
+
+var (
+	// ErrUnsigned is returned when a component carries no signature.
+	ErrUnsigned = errors.New("component is not signed")
+	// ErrUntrustedKey is returned for signatures made by a key outside the
+	// trusted key set.
+	ErrUntrustedKey = errors.New("signing key is not trusted")
+	// ErrInvalidSignature is returned for signatures that do not match the
+	// component.
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Payload returns the bytes signed for a component checksum.
+func Payload(checksum string) []byte {
+	return []byte(payloadPrefix + checksum)
+}
+
+// Sign records the checksum of component and appends a signature of it made
+// with key. A previous signature by keyID is replaced.
+func Sign(component *models.Component, keyID string, key ed25519.PrivateKey) error {
+	if keyID == "" {
+		return errors.New("key id is required")
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid ed25519 private key size %d", len(key))
+	}
+	if err := component.SetChecksum(); err != nil {
+		return err
+	}
+
+	signature := models.Signature{
+		KeyID: keyID,
+		Value: base64.StdEncoding.EncodeToString(ed25519.Sign(key, Payload(component.Metadata.Checksum))),
+	}
+	component.Metadata.Signatures = slices.DeleteFunc(component.Metadata.Signatures, func(s models.Signature) bool {
+		return s.KeyID == keyID
+	})
+	component.Metadata.Signatures = append(component.Metadata.Signatures, signature)
+	return nil
+}
+
+// Verify checks that component matches its checksum, carries at least one
+// signature and that every signature was made by a key of keys. It returns
+// the keys that signed the component.
+func (s *KeySet) Verify(component *models.Component) ([]Key, error) {
+	checksum, err := verifiedChecksum(component)
+	if err != nil {
+		return nil, err
+	}
+	if len(component.Metadata.Signatures) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsigned, component.GetID())
+	}
+
+	var signers []Key
+	var errs []error
+	for _, signature := range component.Metadata.Signatures {
+		key, err := s.verify(checksum, signature)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", component.GetID(), err))
+			continue
+		}
+		signers = append(signers, key)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return signers, nil
+}
+
+// verify checks a single signature of checksum.
+func (s *KeySet) verify(checksum string, signature models.Signature) (Key, error) {
+	key, ok := s.Key(signature.KeyID)
+	if !ok {
+		return Key{}, fmt.Errorf("%w: key '%s'", ErrUntrustedKey, signature.KeyID)
+	}
+	if signature.Publisher != "" && signature.Publisher != key.Publisher {
+		return Key{}, fmt.Errorf("%w: key '%s' belongs to '%s', not '%s'", ErrInvalidSignature, key.ID, key.Publisher, signature.Publisher)
+	}
+	value, err := base64.StdEncoding.DecodeString(signature.Value)
+	if err != nil || !ed25519.Verify(key.PublicKey, Payload(checksum), value) {
+		return Key{}, fmt.Errorf("%w: key '%s' did not sign this component", ErrInvalidSignature, key.ID)
+	}
+	return key, nil
+}
+
+// verifiedChecksum computes the checksum of component and compares it with
+// the recorded one, if any.
+func verifiedChecksum(component *models.Component) (string, error) {
+	checksum, err := models.ComputeChecksum(component)
+	if err != nil {
+		return "", err
+	}
+	if recorded := component.Metadata.Checksum; recorded != "" && recorded != checksum {
+		return "", fmt.Errorf("%w: %s was published as %s but is now %s", models.ErrChecksumMismatch, component.GetID(), recorded, checksum)
+	}
+	return checksum, nil
+}
+
+// Verifier checks components before they are deployed. Components must be
+// signed by a trusted key in the Enforced environments, such as production;
+// elsewhere unsigned components and signatures by unknown keys are
+// accepted. Tampered components, whose checksum or signatures by a trusted
+// key do not match, are refused everywhere.
+type Verifier struct {
+	Keys     *KeySet
+	Enforced []string
+}
+
+// Check verifies component for a deployment to environment.
+func (v *Verifier) Check(component *models.Component, environment string) error {
+	keys := v.Keys
+	if keys == nil {
+		keys = &KeySet{}
+	}
+	if slices.Contains(v.Enforced, environment) {
+		_, err := keys.Verify(component)
+		return err
+	}
+
+	checksum, err := verifiedChecksum(component)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, signature := range component.Metadata.Signatures {
+		if _, err := keys.verify(checksum, signature); err != nil && !errors.Is(err, ErrUntrustedKey) {
+			errs = append(errs, fmt.Errorf("%s: %w", component.GetID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
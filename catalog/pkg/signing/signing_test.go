@@ -0,0 +1,180 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HatiCode/nestor/catalog/pkg/models"
+)
+
+func newComponent() *models.Component {
+	return &models.Component{
+		Name:        "postgres",
+		Version:     "1.0.0",
+		Provider:    "aws",
+		Category:    "database",
+		Description: "Managed PostgreSQL",
+		Inputs: []models.InputSpec{
+			{Name: "db_name", Type: "string", Description: "Database name"},
+		},
+		Outputs: []models.OutputSpec{
+			{Name: "endpoint", Type: "string", Description: "Database endpoint"},
+		},
+		Deployment: models.DeploymentSpec{
+			Engine:  "terraform",
+			Version: "1.5.0",
+			Config:  map[string]any{"source": "git::https://example.com/postgres", "module_version": "1.0.0"},
+		},
+	}
+}
+
+func newKey(t *testing.T, id, publisher string) (Key, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	return Key{ID: id, Publisher: publisher, PublicKey: public}, private
+}
+
+func TestSignAndVerify(t *testing.T) {
+	platform, platformKey := newKey(t, "platform-2024", "platform-team")
+	data, dataKey := newKey(t, "data-2024", "data-team")
+	_, rogueKey := newKey(t, "rogue", "nobody")
+	keys, err := NewKeySet(platform, data)
+	require.NoError(t, err)
+
+	component := newComponent()
+	_, err = keys.Verify(component)
+	assert.ErrorIs(t, err, ErrUnsigned)
+
+	require.NoError(t, Sign(component, platform.ID, platformKey))
+	require.NoError(t, Sign(component, data.ID, dataKey))
+	require.NoError(t, Sign(component, platform.ID, platformKey))
+	assert.Len(t, component.Metadata.Signatures, 2, "signing twice with a key replaces its signature")
+	assert.NotEmpty(t, component.Metadata.Checksum)
+
+	signers, err := keys.Verify(component)
+	require.NoError(t, err)
+	assert.Equal(t, []Key{data, platform}, signers)
+
+	component.Metadata.Deprecated = true
+	_, err = keys.Verify(component)
+	assert.NoError(t, err, "deprecation does not invalidate signatures")
+
+	t.Run("tampered definition", func(t *testing.T) {
+		tampered := newComponent()
+		require.NoError(t, Sign(tampered, platform.ID, platformKey))
+		tampered.Deployment.Version = "1.6.0"
+		_, err := keys.Verify(tampered)
+		assert.ErrorIs(t, err, models.ErrChecksumMismatch)
+
+		tampered.Metadata.Checksum = ""
+		_, err = keys.Verify(tampered)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		signed := newComponent()
+		require.NoError(t, Sign(signed, platform.ID, platformKey))
+		require.NoError(t, Sign(signed, "rogue", rogueKey))
+		_, err := keys.Verify(signed)
+		assert.ErrorIs(t, err, ErrUntrustedKey)
+		assert.ErrorContains(t, err, "postgres:1.0.0: signing key is not trusted: key 'rogue'")
+	})
+
+	t.Run("forged key id", func(t *testing.T) {
+		forged := newComponent()
+		require.NoError(t, Sign(forged, platform.ID, rogueKey))
+		_, err := keys.Verify(forged)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("wrong publisher", func(t *testing.T) {
+		signed := newComponent()
+		require.NoError(t, Sign(signed, platform.ID, platformKey))
+		signed.Metadata.Signatures[0].Publisher = "data-team"
+		_, err := keys.Verify(signed)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
+
+func TestVerifier_Check(t *testing.T) {
+	platform, platformKey := newKey(t, "platform-2024", "platform-team")
+	_, rogueKey := newKey(t, "rogue", "nobody")
+	keys, err := NewKeySet(platform)
+	require.NoError(t, err)
+	verifier := &Verifier{Keys: keys, Enforced: []string{"production"}}
+
+	unsigned := newComponent()
+	assert.ErrorIs(t, verifier.Check(unsigned, "production"), ErrUnsigned)
+	assert.NoError(t, verifier.Check(unsigned, "staging"))
+
+	signed := newComponent()
+	require.NoError(t, Sign(signed, platform.ID, platformKey))
+	assert.NoError(t, verifier.Check(signed, "production"))
+
+	unknown := newComponent()
+	require.NoError(t, Sign(unknown, "rogue", rogueKey))
+	assert.ErrorIs(t, verifier.Check(unknown, "production"), ErrUntrustedKey)
+	assert.NoError(t, verifier.Check(unknown, "staging"))
+
+	signed.Inputs[0].Sensitive = true
+	assert.ErrorIs(t, verifier.Check(signed, "staging"), models.ErrChecksumMismatch, "tampered components are refused everywhere")
+	signed.Metadata.Checksum = ""
+	assert.ErrorIs(t, verifier.Check(signed, "staging"), ErrInvalidSignature)
+}
+
+func TestNewKeySet_RejectsInvalidKeys(t *testing.T) {
+	valid, _ := newKey(t, "a", "team")
+
+	tests := []struct {
+		name string
+		keys []Key
+		want string
+	}{
+		{"missing id", []Key{{Publisher: "team", PublicKey: valid.PublicKey}}, "keys[0]: id is required"},
+		{"missing publisher", []Key{{ID: "a", PublicKey: valid.PublicKey}}, "key 'a': publisher is required"},
+		{"short key", []Key{{ID: "a", Publisher: "team", PublicKey: valid.PublicKey[:8]}}, "invalid ed25519 public key size 8"},
+		{"duplicate", []Key{valid, valid}, "key 'a' is declared twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeySet(tt.keys...)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	publicKey, privateKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("keys:\n  - id: platform-2024\n    publisher: platform-team\n    public_key: "+publicKey+"\n"), 0o600))
+
+	keys, err := LoadKeySet(path)
+	require.NoError(t, err)
+	require.Len(t, keys.Keys(), 1)
+	assert.Equal(t, "platform-team", keys.Keys()[0].Publisher)
+
+	key, err := ParsePrivateKey(privateKey)
+	require.NoError(t, err)
+	component := newComponent()
+	require.NoError(t, Sign(component, "platform-2024", key))
+	_, err = keys.Verify(component)
+	assert.NoError(t, err)
+
+	seed, err := ParsePrivateKey(base64.StdEncoding.EncodeToString(key.Seed()))
+	require.NoError(t, err)
+	assert.Equal(t, key, seed)
+
+	require.NoError(t, os.WriteFile(path, []byte("keys:\n  - id: broken\n    publisher: team\n    public_key: AAAA\n"), 0o600))
+	_, err = LoadKeySet(path)
+	assert.ErrorContains(t, err, "key 'broken': invalid ed25519 public key size 3")
+}
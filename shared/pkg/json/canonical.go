@@ -0,0 +1,161 @@
+package json
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// jsonCanonical decodes numbers as json.Number so they can be normalized
+// without going through float64
+var jsonCanonical = jsoniter.Config{
+	EscapeHTML: false,
+	UseNumber:  true,
+}.Froze()
+
+// ToCanonicalJSON encodes v in canonical form: object keys sorted by code
+// point, no insignificant whitespace, numbers normalized and no HTML
+// escaping. Equal values always produce the same bytes, which makes the
+// output suitable for checksums and signatures.
+func ToCanonicalJSON(v any) ([]byte, error) {
+	data, err := jsonNestor.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(data)
+}
+
+// Canonicalize rewrites a JSON document in canonical form, see
+// ToCanonicalJSON.
+func Canonicalize(data []byte) ([]byte, error) {
+	var tree any
+	if err := jsonCanonical.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, tree); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FromCanonicalJSON decodes data into v the way Canonicalize reads it, with
+// numbers kept as json.Number so that integers beyond 2^53 survive a round
+// trip through ToCanonicalJSON.
+func FromCanonicalJSON(data []byte, v any) error {
+	return jsonCanonical.Unmarshal(data, v)
+}
+
+func writeCanonical(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(buf, v)
+	case stdjson.Number:
+		n, err := canonicalNumber(string(v))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(n)
+	case float64:
+		n, err := canonicalNumber(strconv.FormatFloat(v, 'g', -1, 64))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(n)
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("canonical json: unsupported value of type %T", value)
+	}
+	return nil
+}
+
+// canonicalNumber writes integer literals in plain decimal notation, and
+// other numbers as the shortest float64 representation, in plain notation
+// for integral values below 1e21 as ECMAScript does
+func canonicalNumber(text string) (string, error) {
+	if n, ok := new(big.Int).SetString(text, 10); ok {
+		return n.String(), nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return "", fmt.Errorf("canonical json: invalid number %q", text)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
+
+// writeCanonicalString escapes only what JSON requires: quotes, backslashes
+// and control characters. Invalid UTF-8 is replaced with U+FFFD.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\b':
+			buf.WriteString(`\b`)
+		case r == '\f':
+			buf.WriteString(`\f`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(buf, `\u%04x`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,66 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"sorted keys", `{"b": 1, "a": {"d": 2, "c": 3}}`, `{"a":{"c":3,"d":2},"b":1}`},
+		{"keys sorted by code point", `{"é": 1, "Z": 2, "a": 3}`, `{"Z":2,"a":3,"é":1}`},
+		{"whitespace", "[ 1 ,\n\t2 ]", `[1,2]`},
+		{"integral float", `3.0`, `3`},
+		{"integer", `3`, `3`},
+		{"negative zero", `-0.0`, `0`},
+		{"large integer", `12345678901234567890123`, `12345678901234567890123`},
+		{"exponent", `1e3`, `1000`},
+		{"large exponent", `1e21`, `1e+21`},
+		{"small exponent", `1.5E-7`, `1.5e-07`},
+		{"fraction", `0.10`, `0.1`},
+		{"html is not escaped", `"<a href=\"x\">&</a>"`, `"<a href=\"x\">&</a>"`},
+		{"unicode escapes are decoded", `"\u00e9\u2028"`, "\"\u00e9\u2028\""},
+		{"control characters are escaped", `"a\u0001\n"`, `"a\u0001\n"`},
+		{"literals", `[true,false,null]`, `[true,false,null]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize([]byte(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	_, err := Canonicalize([]byte(`{"a":`))
+	assert.Error(t, err)
+}
+
+func TestToCanonicalJSON(t *testing.T) {
+	a, err := ToCanonicalJSON(map[string]any{"size": 3.0, "url": "https://example.com/?a=1&b=<2>", "tags": []string{"b", "a"}})
+	require.NoError(t, err)
+	b, err := ToCanonicalJSON(struct {
+		Tags []string `json:"tags"`
+		URL  string   `json:"url"`
+		Size int      `json:"size"`
+	}{Tags: []string{"b", "a"}, URL: "https://example.com/?a=1&b=<2>", Size: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"size":3,"tags":["b","a"],"url":"https://example.com/?a=1&b=<2>"}`, string(a))
+	assert.Equal(t, a, b, "equal values encode to the same bytes")
+}
+
+func TestFromCanonicalJSON(t *testing.T) {
+	var tree any
+	require.NoError(t, FromCanonicalJSON([]byte(`{"id":9007199254740993,"ratio":0.5}`), &tree))
+
+	out, err := ToCanonicalJSON(tree)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":9007199254740993,"ratio":0.5}`, string(out), "integers beyond 2^53 keep their precision")
+}